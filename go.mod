@@ -4,12 +4,16 @@ go 1.16
 
 require (
 	github.com/ahmetalpbalkan/go-cursor v0.0.0-20131010032410-8136607ea412
+	github.com/dapr/dapr v1.3.1
 	github.com/fatih/color v1.10.0
 	github.com/jedib0t/go-pretty/v6 v6.3.1
+	github.com/kedacore/keda/v2 v2.4.0
 	github.com/leaanthony/synx v0.1.0
+	github.com/mattn/go-isatty v0.0.13
 	github.com/oliveagle/jsonpath v0.0.0-20180606110733-2e52cf6e6852
 	github.com/openfunction v0.0.0-00010101000000-000000000000
 	github.com/pkg/errors v0.9.1
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/shipwright-io/build v0.6.0
 	github.com/spf13/cobra v1.2.1
 	github.com/spf13/pflag v1.0.5
@@ -20,6 +24,7 @@ require (
 	k8s.io/client-go v11.0.1-0.20190805182717-6502b5e7b1b5+incompatible
 	k8s.io/component-base v0.21.4
 	k8s.io/klog/v2 v2.9.0
+	sigs.k8s.io/yaml v1.2.0
 )
 
 replace (
@@ -50,12 +50,24 @@ Find more information at:
 	ioStreams := genericclioptions.IOStreams{In: in, Out: out, ErrOut: errout}
 
 	cmd.AddCommand(subcommand.NewCmdCreate(kubeConfigFlags, ioStreams))
+	cmd.AddCommand(subcommand.NewCmdApply(kubeConfigFlags, ioStreams))
+	cmd.AddCommand(subcommand.NewCmdDiff(kubeConfigFlags, ioStreams))
+	cmd.AddCommand(subcommand.NewCmdEdit(kubeConfigFlags, ioStreams))
 	cmd.AddCommand(subcommand.NewCmdDelete(kubeConfigFlags, ioStreams))
 	cmd.AddCommand(subcommand.NewCmdGet(kubeConfigFlags, ioStreams))
+	cmd.AddCommand(subcommand.NewCmdDescribe(kubeConfigFlags, ioStreams))
+	cmd.AddCommand(subcommand.NewCmdWait(kubeConfigFlags, ioStreams))
+	cmd.AddCommand(subcommand.NewCmdInvoke(kubeConfigFlags, ioStreams))
+	cmd.AddCommand(subcommand.NewCmdBuild(kubeConfigFlags, ioStreams))
 	cmd.AddCommand(subcommand.NewCmdLogs(kubeConfigFlags, ioStreams))
 	cmd.AddCommand(subcommand.NewCmdInstall(kubeConfigFlags, ioStreams))
 	cmd.AddCommand(subcommand.NewCmdUninstall(kubeConfigFlags, ioStreams))
 	cmd.AddCommand(subcommand.NewCmdDemo(kubeConfigFlags, ioStreams))
+	cmd.AddCommand(subcommand.NewCmdBundle(kubeConfigFlags, ioStreams))
+	cmd.AddCommand(subcommand.NewCmdImages(kubeConfigFlags, ioStreams))
+	cmd.AddCommand(subcommand.NewCmdStatus(kubeConfigFlags, ioStreams))
+	cmd.AddCommand(subcommand.NewCmdDoctor(kubeConfigFlags, ioStreams))
+	cmd.AddCommand(subcommand.NewCmdUpgrade(kubeConfigFlags, ioStreams))
 	cmd.AddCommand(subcommand.NewCmdVersion())
 	return cmd
 }
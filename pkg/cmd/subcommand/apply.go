@@ -0,0 +1,317 @@
+package subcommand
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/OpenFunction/cli/pkg/cmd/util"
+	cc "github.com/OpenFunction/cli/pkg/cmd/util/client"
+	"github.com/OpenFunction/cli/pkg/components/linux"
+	client "github.com/openfunction/pkg/client/clientset/versioned"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	openfunction "github.com/openfunction/apis/core/v1beta1"
+)
+
+const (
+	dryRunNone   = "none"
+	dryRunClient = "client"
+	dryRunServer = "server"
+
+	applyCreated    = "created"
+	applyConfigured = "configured"
+	applyUnchanged  = "unchanged"
+	applyPruned     = "pruned"
+)
+
+// Apply is the commandline for 'apply' sub command
+type Apply struct {
+	genericclioptions.IOStreams
+
+	FilenameOptions resource.FilenameOptions
+	DryRun          string
+	Prune           bool
+	Selector        string
+	ForceConflicts  bool
+
+	namespace        string
+	enforceNamespace bool
+}
+
+const (
+	applyExample = `
+# Create or update the functions in function.yaml
+ofn apply -f function.yaml
+
+# Apply the functions of a directory and its subdirectories
+ofn apply -f functions/ -R
+
+# Preview the changes with the API server without persisting them
+ofn apply -f function.yaml --dry-run=server
+
+# Apply the functions of a kustomization and delete the functions labeled app=demo which are no longer in it
+ofn apply -k functions/ --prune -l app=demo
+`
+)
+
+// NewApply returns an initialized Apply instance
+func NewApply(ioStreams genericclioptions.IOStreams) *Apply {
+	return &Apply{
+		IOStreams: ioStreams,
+	}
+}
+
+func NewCmdApply(cf *genericclioptions.ConfigFlags, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	var fc client.Interface
+
+	a := NewApply(ioStreams)
+	cmd := &cobra.Command{
+		Use:                   "apply -f FILENAME",
+		DisableFlagsInUseLine: true,
+		Short:                 "Create or update functions from files or stdin",
+		Long: `
+Create or update functions from files or stdin with server-side apply,
+the fields removed from the files since the last apply are removed from the functions as well,
+the files must only contain functions of core.openfunction.io/v1beta1
+`,
+		Example: applyExample,
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			config, err := cf.ToRESTConfig()
+			if err != nil {
+				panic(err)
+			}
+			cc.SetConfigDefaults(config)
+			fc = client.NewForConfigOrDie(config)
+
+			a.namespace, a.enforceNamespace, err = cf.ToRawKubeConfigLoader().Namespace()
+			return err
+		},
+
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(a.Validate(cmd))
+			util.CheckErr(a.Run(fc, cmd))
+		},
+	}
+
+	usage := "to use to apply the functions"
+	AddFilenameOptionFlags(cmd, &a.FilenameOptions, usage)
+	cmd.Flags().StringVar(&a.DryRun, "dry-run", dryRunNone, `Must be "none" or "server". If server, submit the requests to the API server without persisting the changes.`)
+	cmd.Flags().BoolVar(&a.Prune, "prune", a.Prune, "Delete the functions matching --selector which have been applied by ofn but aren't in the files.")
+	cmd.Flags().StringVarP(&a.Selector, "selector", "l", a.Selector, "Selector (label query) of the functions to be pruned, e.g. -l key1=value1,key2=value2")
+	cmd.Flags().BoolVar(&a.ForceConflicts, "force-conflicts", a.ForceConflicts, "Take the ownership of the fields which are managed by other field managers.")
+	return cmd
+}
+
+func (a *Apply) Validate(cmd *cobra.Command) error {
+	if len(a.FilenameOptions.Filenames) == 0 && a.FilenameOptions.Kustomize == "" {
+		return util.UsageErrorf(cmd, "-f or -k is required")
+	}
+	if a.DryRun != dryRunNone && a.DryRun != dryRunServer {
+		return util.UsageErrorf(cmd, `invalid dry-run value %s, must be "none" or "server"`, a.DryRun)
+	}
+	if a.Prune && a.Selector == "" {
+		return util.UsageErrorf(cmd, "--prune requires a selector, use -l to specify it")
+	}
+	return nil
+}
+
+func (a *Apply) Run(fc client.Interface, cmd *cobra.Command) error {
+	ctx := context.Background()
+
+	fns, err := getApplyConfigurations(a.FilenameOptions)
+	if err != nil {
+		return err
+	}
+
+	applied := map[types.NamespacedName]bool{}
+	namespaces := map[string]bool{}
+	for _, fn := range fns {
+		if fn.GetNamespace() == "" {
+			fn.SetNamespace(a.namespace)
+		}
+		if a.enforceNamespace && fn.GetNamespace() != a.namespace {
+			return errors.Errorf("the namespace of function %s is %s, which doesn't match the namespace %s of the command", fn.GetName(), fn.GetNamespace(), a.namespace)
+		}
+
+		result, err := a.apply(ctx, fc, fn)
+		if err != nil {
+			return err
+		}
+		a.print(fn.GetName(), result)
+
+		applied[types.NamespacedName{Namespace: fn.GetNamespace(), Name: fn.GetName()}] = true
+		namespaces[fn.GetNamespace()] = true
+	}
+
+	if !a.Prune {
+		return nil
+	}
+	if len(namespaces) == 0 {
+		namespaces[a.namespace] = true
+	}
+	for ns := range namespaces {
+		if err := a.prune(ctx, fc, ns, applied); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apply applies fn with server-side apply and returns whether it has been created, configured or left unchanged.
+func (a *Apply) apply(ctx context.Context, fc client.Interface, fn *unstructured.Unstructured) (string, error) {
+	live, err := fc.CoreV1beta1().Functions(fn.GetNamespace()).Get(ctx, fn.GetName(), metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		live = nil
+	} else if err != nil {
+		return "", err
+	}
+
+	result, err := applyFunction(ctx, fc, fn, a.DryRun == dryRunServer, a.ForceConflicts)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case live == nil:
+		return applyCreated, nil
+	case equality.Semantic.DeepEqual(live.Spec, result.Spec) &&
+		equality.Semantic.DeepEqual(live.Labels, result.Labels) &&
+		equality.Semantic.DeepEqual(live.Annotations, result.Annotations):
+		return applyUnchanged, nil
+	default:
+		return applyConfigured, nil
+	}
+}
+
+// prune deletes the functions of the namespace matching the selector which have been applied by ofn but not this time.
+func (a *Apply) prune(ctx context.Context, fc client.Interface, namespace string, applied map[types.NamespacedName]bool) error {
+	list, err := fc.CoreV1beta1().Functions(namespace).List(ctx, metav1.ListOptions{LabelSelector: a.Selector})
+	if err != nil {
+		return err
+	}
+
+	opts := metav1.DeleteOptions{}
+	if a.DryRun == dryRunServer {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	for _, fn := range list.Items {
+		if applied[types.NamespacedName{Namespace: fn.Namespace, Name: fn.Name}] || !isAppliedByOfn(fn.ManagedFields) {
+			continue
+		}
+		if err := fc.CoreV1beta1().Functions(fn.Namespace).Delete(ctx, fn.Name, opts); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+		a.print(fn.Name, applyPruned)
+	}
+	return nil
+}
+
+func (a *Apply) print(name string, result string) {
+	if a.DryRun == dryRunServer {
+		result += " (server dry run)"
+	}
+	fmt.Fprintf(a.Out, "function.%s/%s %s\n", openfunction.GroupVersion.Group, name, result)
+}
+
+// getApplyConfigurations returns the functions of the files as they are written,
+// so that server-side apply only takes the ownership of the fields set in the files.
+// Each of them is validated against the Function type, and the files must not contain other objects,
+// otherwise a function moved to another API version would be pruned.
+func getApplyConfigurations(filenameOptions resource.FilenameOptions) ([]*unstructured.Unstructured, error) {
+	r := resource.NewLocalBuilder().
+		Unstructured().
+		ContinueOnError().
+		FilenameParam(false, &filenameOptions).
+		Flatten().
+		Do()
+	if err := r.Err(); err != nil {
+		return nil, err
+	}
+
+	var fns []*unstructured.Unstructured
+	err := r.Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+
+		obj, ok := info.Object.(*unstructured.Unstructured)
+		if !ok {
+			return errors.Errorf("unexpected object in %s", info.Source)
+		}
+		gvk := obj.GroupVersionKind()
+		switch {
+		case gvk.GroupKind() == openfunction.GroupVersion.WithKind("Function").GroupKind() && gvk.Version != openfunction.GroupVersion.Version:
+			return errors.Errorf("function %s in %s has the unsupported apiVersion %s, only %s is supported",
+				obj.GetName(), info.Source, obj.GetAPIVersion(), openfunction.GroupVersion)
+		case gvk != openfunction.GroupVersion.WithKind("Function"):
+			return errors.Errorf("%s %s in %s isn't a function, only functions can be applied", obj.GetKind(), obj.GetName(), info.Source)
+		}
+		if err := validateApplyConfiguration(obj); err != nil {
+			return errors.Wrapf(err, "function %s in %s is invalid", obj.GetName(), info.Source)
+		}
+		fns = append(fns, obj)
+		return nil
+	})
+	return fns, err
+}
+
+// validateApplyConfiguration checks that obj decodes to a Function without any unknown field.
+func validateApplyConfiguration(obj *unstructured.Unstructured) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(&openfunction.Function{})
+}
+
+// applyFunction applies fn with server-side apply as ofn and returns the function the API server has persisted,
+// or would have persisted in the dry run mode.
+func applyFunction(ctx context.Context, fc client.Interface, fn *unstructured.Unstructured, dryRun bool, force bool) (*openfunction.Function, error) {
+	data, err := toApplyPatch(fn)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := metav1.PatchOptions{
+		FieldManager: linux.FieldManager,
+		Force:        &force,
+	}
+	if dryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	return fc.CoreV1beta1().Functions(fn.GetNamespace()).Patch(ctx, fn.GetName(), types.ApplyPatchType, data, opts)
+}
+
+// toApplyPatch returns the configuration of fn sent by server-side apply,
+// the fields set by the API server are dropped so that ofn doesn't take their ownership.
+func toApplyPatch(fn *unstructured.Unstructured) ([]byte, error) {
+	obj := fn.DeepCopy()
+	unstructured.RemoveNestedField(obj.Object, "status")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "resourceVersion")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "managedFields")
+	return json.Marshal(obj.Object)
+}
+
+// isAppliedByOfn returns true if the object has fields applied by ofn.
+func isAppliedByOfn(managedFields []metav1.ManagedFieldsEntry) bool {
+	for _, f := range managedFields {
+		if f.Manager == linux.FieldManager && f.Operation == metav1.ManagedFieldsOperationApply {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,293 @@
+package subcommand
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/OpenFunction/cli/pkg/components/linux"
+	openfunction "github.com/openfunction/apis/core/v1beta1"
+	fakeclient "github.com/openfunction/pkg/client/clientset/versioned/fake"
+	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/resource"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// withApplyReactor makes the fake client handle the apply patches the way the API server does for the fields the tests use,
+// and keeps the patches it has received.
+func withApplyReactor(fc *fakeclient.Clientset) map[string]map[string]interface{} {
+	patches := map[string]map[string]interface{}{}
+	gvr := openfunction.GroupVersion.WithResource("functions")
+	fc.PrependReactor("patch", "functions", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		pa := action.(k8stesting.PatchAction)
+		if pa.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+		patch := map[string]interface{}{}
+		if err := json.Unmarshal(pa.GetPatch(), &patch); err != nil {
+			return true, nil, err
+		}
+		patches[pa.GetName()] = patch
+
+		applied := &openfunction.Function{}
+		if err := json.Unmarshal(pa.GetPatch(), applied); err != nil {
+			return true, nil, err
+		}
+		obj, err := fc.Tracker().Get(gvr, pa.GetNamespace(), pa.GetName())
+		if k8serrors.IsNotFound(err) {
+			applied.ManagedFields = []metav1.ManagedFieldsEntry{{Manager: linux.FieldManager, Operation: metav1.ManagedFieldsOperationApply}}
+			return true, applied, fc.Tracker().Create(gvr, applied, pa.GetNamespace())
+		} else if err != nil {
+			return true, nil, err
+		}
+
+		live := obj.(*openfunction.Function).DeepCopy()
+		live.Spec = applied.Spec
+		live.Labels = applied.Labels
+		live.Annotations = applied.Annotations
+		return true, live, fc.Tracker().Update(gvr, live, pa.GetNamespace())
+	})
+	return patches
+}
+
+func TestApply(t *testing.T) {
+	const functions = `
+apiVersion: core.openfunction.io/v1beta1
+kind: Function
+metadata:
+  name: created
+  labels:
+    app: demo
+spec:
+  image: demo/created:v1
+---
+apiVersion: core.openfunction.io/v1beta1
+kind: Function
+metadata:
+  name: configured
+  labels:
+    app: demo
+spec:
+  image: demo/configured:v2
+---
+apiVersion: core.openfunction.io/v1beta1
+kind: Function
+metadata:
+  name: unchanged
+  labels:
+    app: demo
+spec:
+  image: demo/unchanged:v1
+`
+	appliedByOfn := []metav1.ManagedFieldsEntry{{Manager: linux.FieldManager, Operation: metav1.ManagedFieldsOperationApply}}
+	function := func(name string, image string, managedFields []metav1.ManagedFieldsEntry) *openfunction.Function {
+		return &openfunction.Function{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:          name,
+				Namespace:     "default",
+				Labels:        map[string]string{"app": "demo"},
+				ManagedFields: managedFields,
+			},
+			Spec: openfunction.FunctionSpec{Image: image},
+		}
+	}
+
+	file := filepath.Join(t.TempDir(), "functions.yaml")
+	if err := ioutil.WriteFile(file, []byte(functions), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fc := fakeclient.NewSimpleClientset(
+		function("configured", "demo/configured:v1", appliedByOfn),
+		function("unchanged", "demo/unchanged:v1", appliedByOfn),
+		function("pruned", "demo/pruned:v1", appliedByOfn),
+		function("created-by-hand", "demo/created-by-hand:v1", nil),
+	)
+	patches := withApplyReactor(fc)
+
+	out := &bytes.Buffer{}
+	a := NewApply(genericclioptions.IOStreams{Out: out})
+	a.FilenameOptions = resource.FilenameOptions{Filenames: []string{file}}
+	a.DryRun = dryRunNone
+	a.Prune = true
+	a.Selector = "app=demo"
+	a.namespace = "default"
+	if err := a.Run(fc, &cobra.Command{}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"function.core.openfunction.io/created created",
+		"function.core.openfunction.io/configured configured",
+		"function.core.openfunction.io/unchanged unchanged",
+		"function.core.openfunction.io/pruned pruned",
+	}
+	if got := strings.Split(strings.TrimSpace(out.String()), "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	list, err := fc.CoreV1beta1().Functions("default").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, fn := range list.Items {
+		names = append(names, fn.Name+"="+fn.Spec.Image)
+	}
+	sort.Strings(names)
+	wantNames := []string{
+		"configured=demo/configured:v2",
+		"created-by-hand=demo/created-by-hand:v1",
+		"created=demo/created:v1",
+		"unchanged=demo/unchanged:v1",
+	}
+	if strings.Join(names, ",") != strings.Join(wantNames, ",") {
+		t.Errorf("want functions %v, got %v", wantNames, names)
+	}
+
+	// Only the fields of the file are sent so that ofn doesn't take the ownership of the others.
+	wantPatch := map[string]interface{}{
+		"apiVersion": "core.openfunction.io/v1beta1",
+		"kind":       "Function",
+		"metadata": map[string]interface{}{
+			"name":      "created",
+			"namespace": "default",
+			"labels":    map[string]interface{}{"app": "demo"},
+		},
+		"spec": map[string]interface{}{"image": "demo/created:v1"},
+	}
+	gotPatch, _ := json.Marshal(patches["created"])
+	wantPatchData, _ := json.Marshal(wantPatch)
+	if string(gotPatch) != string(wantPatchData) {
+		t.Errorf("want patch %s, got %s", wantPatchData, gotPatch)
+	}
+}
+
+func TestGetApplyConfigurations(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "functions",
+			content: `
+apiVersion: core.openfunction.io/v1beta1
+kind: Function
+metadata:
+  name: sample
+spec:
+  image: demo:v1
+---
+apiVersion: core.openfunction.io/v1beta1
+kind: Function
+metadata:
+  name: other
+spec:
+  image: demo:v2
+`,
+			want: []string{"sample", "other"},
+		},
+		{
+			name: "other kind",
+			content: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+---
+apiVersion: core.openfunction.io/v1beta1
+kind: Function
+metadata:
+  name: sample
+spec:
+  image: demo:v1
+`,
+			wantErr: `ConfigMap config in`,
+		},
+		{
+			name: "misspelled kind",
+			content: `
+apiVersion: core.openfunction.io/v1beta1
+kind: Functon
+metadata:
+  name: sample
+`,
+			wantErr: `Functon sample in`,
+		},
+		{
+			name: "unsupported apiVersion",
+			content: `
+apiVersion: core.openfunction.io/v1alpha2
+kind: Function
+metadata:
+  name: sample
+spec:
+  image: demo:v1
+`,
+			wantErr: `function sample in`,
+		},
+		{
+			name: "unknown field",
+			content: `
+apiVersion: core.openfunction.io/v1beta1
+kind: Function
+metadata:
+  name: sample
+spec:
+  imag: demo:v1
+`,
+			wantErr: `function sample in`,
+		},
+		{
+			name: "invalid type",
+			content: `
+apiVersion: core.openfunction.io/v1beta1
+kind: Function
+metadata:
+  name: sample
+spec:
+  image: [demo]
+`,
+			wantErr: `function sample in`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "functions.yaml")
+			if err := ioutil.WriteFile(file, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			fns, err := getApplyConfigurations(resource.FilenameOptions{Filenames: []string{file}})
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("want error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, fn := range fns {
+				names = append(names, fn.GetName())
+			}
+			if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("want %v, got %v", tt.want, names)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package subcommand
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/OpenFunction/cli/pkg/cmd/util"
+	cc "github.com/OpenFunction/cli/pkg/cmd/util/client"
+	client "github.com/openfunction/pkg/client/clientset/versioned"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	k8s "k8s.io/client-go/kubernetes"
+
+	openfunction "github.com/openfunction/apis/core/v1beta1"
+)
+
+// Build is the commandline for 'build' sub command
+type Build struct {
+	genericclioptions.IOStreams
+
+	Name     string
+	Revision string
+	Follow   bool
+	Timeout  time.Duration
+
+	namespace      string
+	functionClient client.Interface
+	clientSet      k8s.Interface
+}
+
+const (
+	buildExample = `
+# Rebuild the function sample from the revision in its spec
+ofn build sample
+
+# Build the function sample from a commit and stream the logs of the build
+ofn build sample --revision 3f2a9c1 --follow
+`
+)
+
+// NewBuild returns an initialized Build instance
+func NewBuild(ioStreams genericclioptions.IOStreams) *Build {
+	return &Build{
+		IOStreams: ioStreams,
+	}
+}
+
+func NewCmdBuild(cf *genericclioptions.ConfigFlags, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	b := NewBuild(ioStreams)
+	cmd := &cobra.Command{
+		Use:                   "build NAME [--revision REVISION] [--follow]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Start a new build of a function",
+		Long: `
+Start a new build of an existing function, the function is served with the new image once it's built.
+
+With --revision the revision of the source repository in the spec of the function is set,
+so the function keeps being built from it. Otherwise the function is rebuilt from its current spec,
+e.g. to pick up the commits pushed to a branch.
+
+With --follow the logs of the steps of the build are streamed, and the digest of the image is printed once it's built.
+`,
+		Example: buildExample,
+		Args:    cobra.ExactArgs(1),
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			config, clientSet, err := cc.NewKubeConfigClient(cf)
+			if err != nil {
+				return err
+			}
+			b.clientSet = clientSet
+
+			if err := cc.SetConfigDefaults(config); err != nil {
+				return err
+			}
+			b.functionClient, err = client.NewForConfig(config)
+			if err != nil {
+				return err
+			}
+
+			b.namespace, _, err = cf.ToRawKubeConfigLoader().Namespace()
+			return err
+		},
+
+		Run: func(cmd *cobra.Command, args []string) {
+			b.Name = args[0]
+			util.CheckErr(b.Run())
+		},
+	}
+
+	cmd.Flags().StringVar(&b.Revision, "revision", "", "The revision of the source repository to build, e.g. a branch, a tag or a commit")
+	cmd.Flags().BoolVarP(&b.Follow, "follow", "f", false, "Stream the logs of the build and wait for it to finish")
+	cmd.Flags().DurationVar(&b.Timeout, "timeout", 30*time.Minute, "The length of time to wait for the build")
+	return cmd
+}
+
+func (b *Build) Run() error {
+	ctx, done := context.WithTimeout(context.Background(), b.Timeout)
+	defer done()
+
+	fc := b.functionClient
+	fn, err := fc.CoreV1beta1().Functions(b.namespace).Get(ctx, b.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if fn.Spec.Build == nil {
+		return errors.Errorf("function %s/%s has no build, set spec.build to build it", fn.Namespace, fn.Name)
+	}
+
+	var oldBuilder string
+	if fn.Status.Build != nil {
+		oldBuilder = fn.Status.Build.ResourceRef
+	}
+	if err := startBuild(ctx, fc, fn, b.Revision); err != nil {
+		return err
+	}
+
+	fn, err = untilFunction(ctx, fc, fn.Namespace, fn.Name, func(fn *openfunction.Function) (bool, error) {
+		cond := fn.Status.Build
+		return cond != nil && cond.ResourceRef != "" && cond.ResourceRef != oldBuilder, nil
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return errors.Errorf("timed out waiting for the builder of function %s/%s to be created", b.namespace, b.Name)
+		}
+		return err
+	}
+	builder := fn.Status.Build.ResourceRef
+	fmt.Fprintf(b.Out, "function.%s/%s build started, builder: %s\n", openfunction.GroupVersion.Group, fn.Name, builder)
+	if !b.Follow {
+		return nil
+	}
+
+	if err := b.followBuild(ctx, fn.Namespace, builder); err != nil {
+		return err
+	}
+	fn, err = waitForFunction(ctx, fc, fn.Namespace, fn.Name, []waitCondition{{Phase: waitForBuild, State: openfunction.Succeeded}}, nil)
+	if err != nil {
+		return err
+	}
+
+	image := fn.Spec.Image
+	if result, err := fc.CoreV1beta1().Builders(fn.Namespace).Get(ctx, fn.Status.Build.ResourceRef, metav1.GetOptions{}); err == nil &&
+		result.Status.Output != nil && result.Status.Output.Digest != "" {
+		image = fmt.Sprintf("%s@%s", image, result.Status.Output.Digest)
+	}
+	fmt.Fprintf(b.Out, "function.%s/%s built, image: %s\n", openfunction.GroupVersion.Group, fn.Name, image)
+	return nil
+}
+
+// startBuild makes the controller create a new builder for fn,
+// which checks out revision if it isn't empty.
+func startBuild(ctx context.Context, fc client.Interface, fn *openfunction.Function, revision string) error {
+	functions := fc.CoreV1beta1().Functions(fn.Namespace)
+	if revision != "" {
+		if repo := fn.Spec.Build.SrcRepo; repo == nil || repo.Revision == nil || *repo.Revision != revision {
+			// The controller creates a new builder once the build in the spec changes.
+			patch, err := json.Marshal(map[string]interface{}{
+				"spec": map[string]interface{}{
+					"build": map[string]interface{}{
+						"srcRepo": map[string]interface{}{"revision": revision},
+					},
+				},
+			})
+			if err != nil {
+				return err
+			}
+			_, err = functions.Patch(ctx, fn.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+			return err
+		}
+	}
+
+	// The controller creates a new builder if the hash of the build in the status is reset,
+	// as it doesn't match the build in the spec anymore.
+	patch := []byte(`{"status":{"build":{"resourceHash":""}}}`)
+	_, err := functions.Patch(ctx, fn.Name, types.MergePatchType, patch, metav1.PatchOptions{}, "status")
+	return err
+}
+
+// followBuild streams the logs of the steps of the pod of the build run of the builder,
+// it returns without error if the build ends before the pod is created.
+func (b *Build) followBuild(ctx context.Context, namespace string, builderName string) error {
+	finished := builderFinished(ctx, b.functionClient, namespace, builderName)
+
+	var buildRun string
+	err := wait.PollImmediateUntil(time.Second, func() (bool, error) {
+		builder, err := b.functionClient.CoreV1beta1().Builders(namespace).Get(ctx, builderName, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		if _, buildRun = getBuilderResourceRef(builder.Status.ResourceRef); buildRun != "" {
+			return true, nil
+		}
+		// The build may fail before the build run is created.
+		return finished()
+	}, ctx.Done())
+	if err != nil || buildRun == "" {
+		return err
+	}
+
+	pods := b.clientSet.CoreV1().Pods(namespace)
+	pod, err := buildRunPod(ctx, pods, buildRun, true, finished)
+	if err != nil || pod == nil {
+		return err
+	}
+
+	options := func(container string) *corev1.PodLogOptions {
+		return &corev1.PodLogOptions{Container: container, Follow: true}
+	}
+	p := newPodLogs(pods, "", waitForBuild, options, &logFormatter{output: logOutputRaw, minLevel: -1}, b.Out, b.ErrOut)
+	return streamBuildSteps(ctx, p, pod)
+}
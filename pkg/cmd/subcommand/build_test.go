@@ -0,0 +1,68 @@
+package subcommand
+
+import (
+	"context"
+	"testing"
+
+	openfunction "github.com/openfunction/apis/core/v1beta1"
+	fakeclient "github.com/openfunction/pkg/client/clientset/versioned/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestStartBuild(t *testing.T) {
+	current := "main"
+	tests := []struct {
+		name            string
+		revision        string
+		wantSubresource string
+		wantPatch       string
+	}{
+		{
+			name:            "rebuild",
+			wantSubresource: "status",
+			wantPatch:       `{"status":{"build":{"resourceHash":""}}}`,
+		},
+		{
+			name:            "same revision",
+			revision:        current,
+			wantSubresource: "status",
+			wantPatch:       `{"status":{"build":{"resourceHash":""}}}`,
+		},
+		{
+			name:      "new revision",
+			revision:  "3f2a9c1",
+			wantPatch: `{"spec":{"build":{"srcRepo":{"revision":"3f2a9c1"}}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn := &openfunction.Function{
+				ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default"},
+				Spec: openfunction.FunctionSpec{
+					Build: &openfunction.BuildImpl{SrcRepo: &openfunction.GitRepo{Url: "https://github.com/OpenFunction/samples.git", Revision: &current}},
+				},
+				Status: openfunction.FunctionStatus{Build: &openfunction.Condition{State: openfunction.Succeeded, ResourceHash: "1234"}},
+			}
+			fc := fakeclient.NewSimpleClientset(fn)
+
+			if err := startBuild(context.Background(), fc, fn, tt.revision); err != nil {
+				t.Fatal(err)
+			}
+
+			var patches []k8stesting.PatchAction
+			for _, action := range fc.Actions() {
+				if patch, ok := action.(k8stesting.PatchAction); ok {
+					patches = append(patches, patch)
+				}
+			}
+			if len(patches) != 1 {
+				t.Fatalf("got %d patches, want 1", len(patches))
+			}
+			if patches[0].GetSubresource() != tt.wantSubresource || string(patches[0].GetPatch()) != tt.wantPatch {
+				t.Errorf("got patch %s of %q, want %s of %q", patches[0].GetPatch(), patches[0].GetSubresource(), tt.wantPatch, tt.wantSubresource)
+			}
+		})
+	}
+}
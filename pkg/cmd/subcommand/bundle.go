@@ -0,0 +1,196 @@
+package subcommand
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/OpenFunction/cli/pkg/cmd/util"
+	"github.com/OpenFunction/cli/pkg/cmd/util/client"
+	"github.com/OpenFunction/cli/pkg/components/bundle"
+	"github.com/OpenFunction/cli/pkg/components/common"
+	"github.com/OpenFunction/cli/pkg/components/inventory"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+const (
+	defaultBundleFile = "ofn-bundle.tar.gz"
+)
+
+// BundleCreate is the commandline for 'bundle create' sub command
+type BundleCreate struct {
+	genericclioptions.IOStreams
+
+	OpenFunctionVersion string
+	KubernetesVersion   string
+	Output              string
+	RegionCN            bool
+	Timeout             time.Duration
+}
+
+// NewBundleCreate returns an initialized BundleCreate instance
+func NewBundleCreate(ioStreams genericclioptions.IOStreams) *BundleCreate {
+	return &BundleCreate{
+		IOStreams: ioStreams,
+	}
+}
+
+func NewCmdBundle(cf *genericclioptions.ConfigFlags, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "bundle",
+		DisableFlagsInUseLine: true,
+		Short:                 "Manage the manifest bundles used for offline installation.",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(newCmdBundleCreate(cf, ioStreams))
+	return cmd
+}
+
+func newCmdBundleCreate(cf *genericclioptions.ConfigFlags, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	b := NewBundleCreate(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:                   "create [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Download the manifests of OpenFunction and its dependencies into a bundle.",
+		Long: `This command will download the manifests of OpenFunction and its dependencies into a bundle,
+which can be installed without network access using "ofn install --from-bundle".`,
+		Example: `
+# Create a bundle for the latest stable version of OpenFunction and the Kubernetes version of the current cluster
+ofn bundle create
+
+# Create a bundle without access to the target cluster
+ofn bundle create --version v0.6.0 --kubernetes-version v1.22.0 -o ofn-bundle.tar.gz
+
+# Install OpenFunction from the bundle
+ofn install --from-bundle ofn-bundle.tar.gz --all
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(b.ValidateArgs(cf))
+			util.CheckErr(b.RunCreate())
+		},
+	}
+
+	cmd.Flags().StringVar(&b.OpenFunctionVersion, "version", "", "Used to specify the version of OpenFunction to be bundled.")
+	cmd.Flags().StringVar(&b.KubernetesVersion, "kubernetes-version", "", "The version of the target Kubernetes cluster, defaults to the version of the current cluster.")
+	cmd.Flags().StringVarP(&b.Output, "output", "o", defaultBundleFile, "The path of the bundle to be created.")
+	cmd.Flags().BoolVar(&b.RegionCN, "region-cn", false, "For users who have limited access to gcr.io or github.com.")
+	cmd.Flags().DurationVar(&b.Timeout, "timeout", 10*time.Minute, "Set timeout time. Default is 10 minutes.")
+	return cmd
+}
+
+func (b *BundleCreate) ValidateArgs(cf *genericclioptions.ConfigFlags) error {
+	if b.OpenFunctionVersion == "" {
+		v, e := getLatestStableVersion()
+		if e != nil {
+			return errors.Errorf("failed to fetch OpenFunction latest release, %s, use '--version' to specify the version of OpenFunction", e.Error())
+		}
+		b.OpenFunctionVersion = v
+	}
+
+	if b.OpenFunctionVersion != common.LatestVersion {
+		v, err := version.ParseGeneric(b.OpenFunctionVersion)
+		if err != nil {
+			return errors.Errorf("the specified version %s is not a valid version", b.OpenFunctionVersion)
+		}
+		if valid, err := common.IsVersionValid(v); err != nil {
+			return err
+		} else if !valid {
+			return errors.Errorf(
+				"the specified version %s is lower than the supported version %s",
+				b.OpenFunctionVersion,
+				common.BaseVersion,
+			)
+		}
+	}
+
+	v, err := resolveKubernetesVersion(cf, b.KubernetesVersion)
+	if err != nil {
+		return err
+	}
+	b.KubernetesVersion = v
+	return nil
+}
+
+// resolveKubernetesVersion returns the specified version of Kubernetes,
+// or the version of the current cluster if none is specified.
+func resolveKubernetesVersion(cf *genericclioptions.ConfigFlags, kubernetesVersion string) (string, error) {
+	if kubernetesVersion == "" {
+		_, cl, err := client.NewKubeConfigClient(cf)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to connect to the cluster, use '--kubernetes-version' to specify the version of Kubernetes")
+		}
+		sv, err := cl.ServerVersion()
+		if err != nil {
+			return "", errors.Wrap(err, "failed to get the version of the cluster, use '--kubernetes-version' to specify the version of Kubernetes")
+		}
+		kubernetesVersion = sv.String()
+	}
+
+	if _, err := version.ParseGeneric(kubernetesVersion); err != nil {
+		return "", errors.Errorf("the specified Kubernetes version %s is not a valid version", kubernetesVersion)
+	}
+	return kubernetesVersion, nil
+}
+
+func (b *BundleCreate) RunCreate() error {
+	ctx, done := context.WithTimeout(
+		context.Background(),
+		b.Timeout,
+	)
+	defer done()
+
+	// Bundle every component that may be installed,
+	// the runtimes are selected when installing.
+	inv, err := inventory.GetInventoryWithServerVersion(
+		b.KubernetesVersion,
+		b.RegionCN,
+		true,
+		true,
+		true,
+		true,
+		true,
+		true,
+		b.OpenFunctionVersion,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to get inventory")
+	}
+
+	util.BeforeTask("Start downloading the manifests of the following components:")
+	printInventory(inventory.GetVersionMap(inv))
+
+	f, err := os.Create(b.Output)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	start := time.Now()
+	index := &bundle.Index{
+		OpenFunctionVersion: b.OpenFunctionVersion,
+		KubernetesVersion:   b.KubernetesVersion,
+		RegionCN:            b.RegionCN,
+	}
+	if err := bundle.Create(ctx, f, inv, index); err != nil {
+		os.Remove(b.Output)
+		return errors.New(util.TaskFail(err.Error()))
+	}
+
+	for name := range inv {
+		if _, ok := index.Components[name]; !ok {
+			fmt.Fprintln(b.Out, util.YellowItalic(fmt.Sprintf(" -> %s has no manifest and will be installed by its own CLI, which requires network access.", name)))
+		}
+	}
+	fmt.Fprintln(b.Out, util.YellowItalic(fmt.Sprintf(" -> The bundle has been written to %s", b.Output)))
+
+	util.AllDone(time.Since(start))
+	return nil
+}
@@ -0,0 +1,132 @@
+package subcommand
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/OpenFunction/cli/pkg/cmd/util/spinners"
+	"github.com/OpenFunction/cli/pkg/components/common"
+	"github.com/OpenFunction/cli/pkg/components/config"
+	"github.com/OpenFunction/cli/pkg/components/inventory"
+	"github.com/OpenFunction/cli/pkg/components/scheduler"
+	"github.com/pkg/errors"
+	k8s "k8s.io/client-go/kubernetes"
+)
+
+// installFunc installs a component and reports its progress with the spinner,
+// which is completed by the caller so that more work can be done once the component is installed.
+type installFunc func(ctx context.Context, spinner *spinners.Spinner, cl k8s.Interface, operator *common.Operator) error
+
+type uninstallFunc func(ctx context.Context, spinner *spinners.Spinner, cl k8s.Interface, operator *common.Operator, waitForCleared bool)
+
+// component binds the installation and uninstallation steps of an inventory item.
+type component struct {
+	install   installFunc
+	uninstall uninstallFunc
+}
+
+// registry holds the steps of every component known by the inventory.
+// The order in which the components run is determined by their dependencies,
+// see inventory.Interface.GetDependencies.
+var registry = map[string]*component{
+	inventory.DaprName: {
+		install:   installDapr,
+		uninstall: uninstallDapr,
+	},
+	inventory.KedaName: {
+		install:   installKeda,
+		uninstall: uninstallKeda,
+	},
+	inventory.KnativeServingName: {
+		install:   installKnativeServing,
+		uninstall: uninstallKnativeServing,
+	},
+	inventory.KourierName: {
+		install:   installKourier,
+		uninstall: uninstallKourier,
+	},
+	inventory.ServingDefaultDomainName: {
+		install:   installDefaultDomain,
+		uninstall: uninstallDefaultDomain,
+	},
+	inventory.TektonPipelinesName: {
+		install:   installTektonPipelines,
+		uninstall: uninstallTektonPipelines,
+	},
+	inventory.ShipwrightName: {
+		install:   installShipwright,
+		uninstall: uninstallShipwright,
+	},
+	inventory.CertManagerName: {
+		install:   installCertManager,
+		uninstall: uninstallCertManager,
+	},
+	inventory.IngressName: {
+		install:   installIngress,
+		uninstall: uninstallIngress,
+	},
+	inventory.OpenFunctionName: {
+		install:   installOpenFunction,
+		uninstall: uninstallOpenFunction,
+	},
+}
+
+// newInstallScheduler returns a scheduler installing the components of the operator's inventory,
+// except for the ones in skip. The timeouts and the patches of the components are taken from cfg, which may be nil.
+func newInstallScheduler(cl k8s.Interface, operator *common.Operator, cfg *config.InstallConfig, skip map[string]bool) (*scheduler.Scheduler, error) {
+	sched := scheduler.NewScheduler()
+	for name, inv := range operator.Inventory {
+		comp := cfg.Component(name)
+
+		// The patches are applied to the existing components as well.
+		if comp != nil && len(comp.Patches) != 0 {
+			patches := comp.Patches
+			sched.Add(fmt.Sprintf(patchesNodeTmpl, name), []string{name}, func(ctx context.Context, spinner *spinners.Spinner) {
+				applyPatches(ctx, spinner, operator, patches)
+			})
+		}
+
+		if skip[name] {
+			continue
+		}
+
+		c, ok := registry[name]
+		if !ok {
+			return nil, errors.Errorf("unknown component: %s", name)
+		}
+
+		install := c.install
+		sched.Add(name, inv.GetDependencies(), withTimeout(comp, func(ctx context.Context, spinner *spinners.Spinner) {
+			if err := install(ctx, spinner, cl, operator); err != nil {
+				spinner.Error(err)
+				return
+			}
+			spinner.Done()
+		}))
+	}
+	return sched, nil
+}
+
+// newUninstallScheduler returns a scheduler uninstalling the components of the operator's inventory
+// which have been recorded, the dependents of a component are uninstalled before it.
+func newUninstallScheduler(cl k8s.Interface, operator *common.Operator, waitForCleared bool) (*scheduler.Scheduler, error) {
+	records := operator.Records.ToMap(true)
+
+	sched := scheduler.NewReverseScheduler()
+	for name, inv := range operator.Inventory {
+		if records[name] == "" {
+			continue
+		}
+
+		c, ok := registry[name]
+		if !ok {
+			return nil, errors.Errorf("unknown component: %s", name)
+		}
+
+		uninstall := c.uninstall
+		sched.Add(name, inv.GetDependencies(), func(ctx context.Context, spinner *spinners.Spinner) {
+			uninstall(ctx, spinner, cl, operator, waitForCleared)
+		})
+	}
+	return sched, nil
+}
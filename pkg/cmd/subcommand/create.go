@@ -2,19 +2,28 @@ package subcommand
 
 import (
 	"context"
+	"encoding/csv"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/OpenFunction/cli/pkg/cmd/util"
 	cc "github.com/OpenFunction/cli/pkg/cmd/util/client"
+	componentsv1alpha1 "github.com/dapr/dapr/pkg/apis/components/v1alpha1"
+	kedav1alpha1 "github.com/kedacore/keda/v2/api/v1alpha1"
 	client "github.com/openfunction/pkg/client/clientset/versioned"
 	"github.com/openfunction/pkg/client/clientset/versioned/scheme"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/printers"
 	"k8s.io/cli-runtime/pkg/resource"
 
-	fn "github.com/openfunction/apis/core/v1beta1"
 	openfunction "github.com/openfunction/apis/core/v1beta1"
 )
 
@@ -24,23 +33,68 @@ type Create struct {
 	Printer *util.Printer
 
 	FilenameOptions resource.FilenameOptions
-	DryRun          bool
+	DryRun          string
 
 	Name             string
 	Image            string
 	Version          string
 	Port             int32
 	ImageCredentials string
-	Build            *openfunction.BuildImpl
-	Serving          *openfunction.ServingImpl
-	runtime          string
+	Build            BuildOptions
+	Serving          ServingOptions
+	Service          ServiceOptions
 
 	namespace string
 
-	options metav1.CreateOptions
+	flags   *pflag.FlagSet
 	printer printers.ResourcePrinter
 }
 
+// BuildOptions holds the flags setting spec.build of a function
+type BuildOptions struct {
+	Builder                      string
+	BuilderCredentials           string
+	BuilderMaxAge                time.Duration
+	Env                          map[string]string
+	Params                       map[string]string
+	Dockerfile                   string
+	Timeout                      time.Duration
+	Strategy                     string
+	StrategyKind                 string
+	StrategyTimeout              time.Duration
+	SuccessfulBuildsHistoryLimit int32
+	FailedBuildsHistoryLimit     int32
+	GitRepoURL                   string
+	GitRepoRevision              string
+	GitRepoSourceSubPath         string
+	GitRepoCredentials           string
+}
+
+// ServingOptions holds the flags setting spec.serving of a function
+type ServingOptions struct {
+	Runtime      string
+	Params       map[string]string
+	Labels       map[string]string
+	Annotations  map[string]string
+	Timeout      time.Duration
+	MinReplicas  int32
+	MaxReplicas  int32
+	KnativeScale map[string]string
+	ScaledObject map[string]string
+	ScaledJob    map[string]string
+	Triggers     []string
+	Inputs       []string
+	Outputs      []string
+	Bindings     []string
+	Pubsub       []string
+}
+
+// ServiceOptions holds the flags setting spec.service of a function
+type ServiceOptions struct {
+	StandaloneIngress  bool
+	IngressAnnotations map[string]string
+}
+
 const (
 	createExample = `
 # Create a function using the data in function.yaml
@@ -48,17 +102,44 @@ ofn create -f function.yaml
 
 # Create a function based on the YAML passed into stdin
 cat function.yaml | ofn create -f -
+
+# Build a function from a git repository and run it with Knative
+ofn create function sample --image demo/sample:v1 --version v1.0.0 \
+  --builder openfunction/builder-go:latest \
+  --git-repo-url https://github.com/OpenFunction/samples.git --git-repo-source-sub-path functions/knative/hello-world-go \
+  --runtime knative --min-replicas 1
+
+# Print the manifest of an async function consuming a Kafka topic without contacting the cluster
+ofn create function consumer --image demo/consumer:v1 --version v1.0.0 --runtime async \
+  --dapr-binding name=kafka,type=bindings.kafka,metadata.brokers=kafka:9092,metadata.topics=sample,metadata.consumerGroup=consumer \
+  --dapr-input name=consumer,component=kafka \
+  --trigger type=kafka,metadata.topic=sample,metadata.bootstrapServers=kafka:9092,metadata.consumerGroup=consumer \
+  --dry-run=client -o yaml > consumer.yaml
 `
 )
 
+var (
+	// The flags which take effect only with --git-repo-url.
+	buildFlagNames = []string{
+		"builder", "builder-credentials", "builder-max-age", "env", "build-param", "dockerfile", "build-timeout",
+		"shipwright-strategy", "shipwright-strategy-kind", "shipwright-timeout",
+		"successful-builds-history-limit", "failed-builds-history-limit",
+		"git-repo-revision", "git-repo-source-sub-path", "git-repo-credentials",
+	}
+	// The flags which take effect only with --runtime.
+	servingFlagNames = []string{
+		"serving-param", "serving-label", "serving-annotation", "serving-timeout",
+		"min-replicas", "max-replicas", "knative-scale", "keda-scaled-object", "keda-scaled-job",
+		"trigger", "dapr-input", "dapr-output", "dapr-binding", "dapr-pubsub",
+	}
+)
+
 // NewCreate returns an initialized Create instance
 func NewCreate(ioStreams genericclioptions.IOStreams) *Create {
 	return &Create{
 		IOStreams: ioStreams,
 
-		Printer: util.NewPrinter("create", scheme.Scheme),
-		Build:   &fn.BuildImpl{},
-		Serving: &fn.ServingImpl{},
+		Printer: util.NewPrinter("created", scheme.Scheme),
 	}
 }
 
@@ -67,24 +148,17 @@ func NewCmdCreate(cf *genericclioptions.ConfigFlags, ioStreams genericclioptions
 
 	c := NewCreate(ioStreams)
 	cmd := &cobra.Command{
-		Use:                   "create -f FILENAME",
+		Use:                   "create (-f FILENAME | NAME --image IMAGE --version VERSION) [flags]",
 		DisableFlagsInUseLine: true,
-		Short:                 "Create a resource from a file or from stdin",
+		Short:                 "Create a resource from a file, from stdin or from flags",
 		Long: `
-Create a resource from a file or from stdin
+Create a resource from a file, from stdin or from flags
 `,
 		Example: createExample,
 
 		PreRunE: func(cmd *cobra.Command, args []string) error {
-			config, err := cf.ToRESTConfig()
-			if err != nil {
-				panic(err)
-			}
-			cc.SetConfigDefaults(config)
-			fc = client.NewForConfigOrDie(config)
-
-			c.namespace, _, err = cf.ToRawKubeConfigLoader().Namespace()
-
+			var err error
+			fc, err = c.newClient(cf)
 			return err
 		},
 
@@ -97,53 +171,148 @@ Create a resource from a file or from stdin
 
 	usage := "to use to create the function"
 	AddFilenameOptionFlags(cmd, &c.FilenameOptions, usage)
-	cmd.Flags().StringVarP(&c.Image, "image", "i", c.Image, "Function image name")
-	cmd.Flags().StringVarP(&c.Version, "version", "v", c.Version, "Function version in format like v1.0.0")
-	cmd.Flags().StringVarP(&c.ImageCredentials, "image-credentials", "", c.ImageCredentials, "ImageCredentials references a Secret that contains credentials to access the image repository")
-	cmd.Flags().Int32VarP(&c.Port, "port", "", c.Port, "The port on which the function will be invoked")
-	cmd.Flags().BoolVarP(&c.DryRun, "dry-run", "", c.DryRun, "Only print the object that would be sent, without sending it")
-	cmd.Flags().StringVarP(&c.runtime, "runtime", "", "", "The configuration of the backend runtime for running function.")
-	c.Printer.AddFlags(cmd)
-	AddBuild(cmd, c.Build)
-	AddServing(cmd, c.Serving)
+	c.AddFlags(cmd)
+
+	cmd.AddCommand(newCmdCreateFunction(cf, ioStreams))
 	return cmd
 }
 
-func (c *Create) Complete(cmd *cobra.Command, args []string) error {
-	if len(args) != 0 {
-		c.Name = args[0]
-	}
+func newCmdCreateFunction(cf *genericclioptions.ConfigFlags, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	var fc client.Interface
 
-	if c.DryRun {
-		c.options.DryRun = []string{
-			metav1.DryRunAll,
-		}
+	c := NewCreate(ioStreams)
+	cmd := &cobra.Command{
+		Use:                   "function NAME --image IMAGE --version VERSION [flags]",
+		Aliases:               []string{"functions", "fn"},
+		DisableFlagsInUseLine: true,
+		Short:                 "Create a function from flags",
+		Long: `
+Create a function from flags.
+
+The build is set with --git-repo-url and the serving with --runtime, the other build and serving flags require them.
+The flags --trigger, --dapr-input, --dapr-output, --dapr-binding and --dapr-pubsub take a list of key=value pairs separated by commas,
+a pair containing commas can be quoted as in CSV, e.g. --dapr-binding 'name=kafka,type=bindings.kafka,"metadata.brokers=k1:9092,k2:9092"'.
+
+With --dry-run=client the function is only printed, the cluster isn't contacted at all.
+`,
+		Example: createExample,
+		Args:    cobra.ExactArgs(1),
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+			fc, err = c.newClient(cf)
+			return err
+		},
+
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(c.Complete(cmd, args))
+			util.CheckErr(c.Validate(cmd))
+			util.CheckErr(c.RunCreate(fc, cmd, args))
+		},
 	}
 
-	c.Printer.SetPrinterFunc(util.WithDefaultPrinter(""))
+	c.AddFlags(cmd)
+	return cmd
+}
+
+// AddFlags adds the flags setting the spec of the function to cmd.
+func (c *Create) AddFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringVar(&c.DryRun, "dry-run", dryRunNone, `Must be "none", "client" or "server". If client, only print the object that would be sent, without contacting the cluster. If server, submit the request to the API server without persisting the object.`)
+	flags.Lookup("dry-run").NoOptDefVal = dryRunClient
+	c.Printer.AddFlags(cmd)
 
+	flags.StringVarP(&c.Image, "image", "i", c.Image, "Function image name")
+	flags.StringVarP(&c.Version, "version", "v", c.Version, "Function version in format like v1.0.0")
+	flags.StringVar(&c.ImageCredentials, "image-credentials", c.ImageCredentials, "ImageCredentials references a Secret that contains credentials to access the image repository")
+	flags.Int32Var(&c.Port, "port", c.Port, "The port on which the function will be invoked")
+
+	b := &c.Build
+	flags.StringVar(&b.GitRepoURL, "git-repo-url", "", "Git url to clone, the function is built only if it's set")
+	flags.StringVar(&b.GitRepoRevision, "git-repo-revision", "", "Git revision to check out (branch, tag, sha, ref…)")
+	flags.StringVar(&b.GitRepoSourceSubPath, "git-repo-source-sub-path", "", "A subpath within the source input where the source to build is located")
+	flags.StringVar(&b.GitRepoCredentials, "git-repo-credentials", "", "A Secret that contains credentials to access the git repository")
+	flags.StringVar(&b.Builder, "builder", "", "Cloud Native Buildpacks builders")
+	flags.StringVar(&b.BuilderCredentials, "builder-credentials", "", "A Secret that contains credentials to access the builder image repository")
+	flags.DurationVar(&b.BuilderMaxAge, "builder-max-age", 0, "The duration to retain a completed builder, 0 means forever")
+	flags.StringToStringVar(&b.Env, "env", nil, "Environment variables to pass to the builder.")
+	flags.StringToStringVar(&b.Params, "build-param", nil, "Parameters of the build strategy, e.g. RUN_IMAGE=demo/run:latest")
+	flags.StringVar(&b.Dockerfile, "dockerfile", "", "The path to the Dockerfile used by the build strategies relying on a Dockerfile")
+	flags.DurationVar(&b.Timeout, "build-timeout", 0, "The maximum amount of time the build should take")
+	flags.StringVar(&b.Strategy, "shipwright-strategy", "", "The name of the Shipwright build strategy")
+	flags.StringVar(&b.StrategyKind, "shipwright-strategy-kind", "", "The kind of the Shipwright build strategy, BuildStrategy or ClusterBuildStrategy")
+	flags.DurationVar(&b.StrategyTimeout, "shipwright-timeout", 0, "The maximum amount of time the Shipwright build should take")
+	flags.Int32Var(&b.SuccessfulBuildsHistoryLimit, "successful-builds-history-limit", 0, "The number of successful builds to retain")
+	flags.Int32Var(&b.FailedBuildsHistoryLimit, "failed-builds-history-limit", 0, "The number of failed builds to retain")
+
+	s := &c.Serving
+	flags.StringVar(&s.Runtime, "runtime", "", `The backend runtime for running the function, "knative" or "async", the function is served only if it's set`)
+	flags.StringToStringVar(&s.Params, "serving-param", nil, "Parameters injected into the function as environment variables")
+	flags.StringToStringVar(&s.Labels, "serving-label", nil, "Labels added to the workload")
+	flags.StringToStringVar(&s.Annotations, "serving-annotation", nil, "Annotations added to the workload")
+	flags.DurationVar(&s.Timeout, "serving-timeout", 0, "The maximum amount of time the serving should take to be running")
+	flags.Int32Var(&s.MinReplicas, "min-replicas", 0, "The minimum number of replicas")
+	flags.Int32Var(&s.MaxReplicas, "max-replicas", 0, "The maximum number of replicas")
+	flags.StringToStringVar(&s.KnativeScale, "knative-scale", nil, "Knative autoscaling annotations, e.g. autoscaling.knative.dev/target=10")
+	flags.StringToStringVar(&s.ScaledObject, "keda-scaled-object", nil, "KEDA ScaledObject options: workloadType, pollingInterval, cooldownPeriod, minReplicaCount, maxReplicaCount")
+	flags.StringToStringVar(&s.ScaledJob, "keda-scaled-job", nil, "KEDA ScaledJob options: restartPolicy, pollingInterval, successfulJobsHistoryLimit, failedJobsHistoryLimit, maxReplicaCount")
+	flags.StringArrayVar(&s.Triggers, "trigger", nil, "A KEDA trigger with the keys type, name, targetKind, authenticationRef, authenticationKind, fallback and metadata.KEY. Can be repeated.")
+	flags.StringArrayVar(&s.Inputs, "dapr-input", nil, "An input from a Dapr component with the keys name, component, topic, operation and params.KEY. Can be repeated.")
+	flags.StringArrayVar(&s.Outputs, "dapr-output", nil, "An output to a Dapr component with the keys name, component, topic, operation and params.KEY. Can be repeated.")
+	flags.StringArrayVar(&s.Bindings, "dapr-binding", nil, "A Dapr bindings component with the keys name, type, version, ignoreErrors, initTimeout, metadata.KEY and secretKeyRef.KEY=SECRET/KEY. Can be repeated.")
+	flags.StringArrayVar(&s.Pubsub, "dapr-pubsub", nil, "A Dapr pubsub component with the keys name, type, version, ignoreErrors, initTimeout, metadata.KEY and secretKeyRef.KEY=SECRET/KEY. Can be repeated.")
+
+	flags.BoolVar(&c.Service.StandaloneIngress, "standalone-ingress", false, "Create a standalone ingress for the function")
+	flags.StringToStringVar(&c.Service.IngressAnnotations, "ingress-annotation", nil, "Annotations of the standalone ingress")
+}
+
+// newClient returns the client of the cluster and resolves the namespace,
+// the cluster isn't needed with --dry-run=client.
+func (c *Create) newClient(cf *genericclioptions.ConfigFlags) (client.Interface, error) {
 	var err error
-	c.printer, err = c.Printer.ToPrinter()
+	c.namespace, _, err = cf.ToRawKubeConfigLoader().Namespace()
+	if c.DryRun == dryRunClient {
+		// The namespace is left empty if there is no kubeconfig.
+		return nil, nil
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if c.runtime == "" {
-		c.Serving = nil
-	} else {
-		c.Serving.Runtime = openfunction.Runtime(c.runtime)
+	config, err := cf.ToRESTConfig()
+	if err != nil {
+		return nil, err
 	}
+	cc.SetConfigDefaults(config)
+	return client.NewForConfig(config)
+}
 
-	if c.Build != nil {
-		if c.Build.SrcRepo.Credentials.Name == "" {
-			c.Build.SrcRepo.Credentials = nil
-		}
+func (c *Create) Complete(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		c.Name = args[0]
 	}
-	return nil
+	c.flags = cmd.Flags()
+
+	operation := "created"
+	switch c.DryRun {
+	case dryRunNone:
+	case dryRunClient:
+		operation += " (dry run)"
+	case dryRunServer:
+		operation += " (server dry run)"
+	default:
+		return util.UsageErrorf(cmd, `--dry-run must be "none", "client" or "server"`)
+	}
+
+	c.Printer.SetPrinterFunc(util.WithDefaultPrinter(operation))
+
+	var err error
+	c.printer, err = c.Printer.ToPrinter()
+	return err
 }
 
 func (c *Create) Validate(cmd *cobra.Command) error {
-	if len(c.FilenameOptions.Filenames) != 0 {
+	if len(c.FilenameOptions.Filenames) != 0 || c.FilenameOptions.Kustomize != "" {
 		return nil
 	}
 
@@ -165,32 +334,19 @@ func (c *Create) RunCreate(fc client.Interface, cmd *cobra.Command, args []strin
 		fns []*openfunction.Function
 		err error
 	)
-	if len(c.FilenameOptions.Filenames) != 0 {
+	if len(c.FilenameOptions.Filenames) != 0 || c.FilenameOptions.Kustomize != "" {
 		fns, err = getFromFilenameOptions(cmd, c.FilenameOptions)
 	} else {
-		fns = []*openfunction.Function{{
-			ObjectMeta: metav1.ObjectMeta{
-				Name: c.Name,
-			},
-			Spec: openfunction.FunctionSpec{
-				Version: &c.Version,
-				Image:   c.Image,
-				ImageCredentials: &corev1.LocalObjectReference{
-					Name: c.ImageCredentials,
-				},
-				Port:    &c.Port,
-				Build:   c.Build,
-				Serving: c.Serving,
-			},
-		},
-		}
+		var fn *openfunction.Function
+		fn, err = c.function()
+		fns = []*openfunction.Function{fn}
 	}
 	if err != nil {
 		return err
 	}
 
 	for _, fn := range fns {
-		result, err := c.create(fc, cmd, fn)
+		result, err := c.create(fc, fn)
 		if err != nil {
 			return err
 		}
@@ -205,15 +361,18 @@ func (c *Create) RunCreate(fc client.Interface, cmd *cobra.Command, args []strin
 	return nil
 }
 
-func (c *Create) create(fc client.Interface, cmd *cobra.Command, fn *openfunction.Function) (*openfunction.Function, error) {
-	opt := metav1.CreateOptions{}
-	if c.DryRun {
-		opt.DryRun = []string{metav1.DryRunAll}
-	}
-
+func (c *Create) create(fc client.Interface, fn *openfunction.Function) (*openfunction.Function, error) {
 	if fn.Namespace == "" {
 		fn.Namespace = c.namespace
 	}
+	if c.DryRun == dryRunClient {
+		return fn, nil
+	}
+
+	opt := metav1.CreateOptions{}
+	if c.DryRun == dryRunServer {
+		opt.DryRun = []string{metav1.DryRunAll}
+	}
 
 	result, err := fc.CoreV1beta1().Functions(fn.Namespace).Create(context.Background(), fn, opt)
 	if err != nil {
@@ -222,3 +381,414 @@ func (c *Create) create(fc client.Interface, cmd *cobra.Command, fn *openfunctio
 
 	return result, nil
 }
+
+// function assembles the function from the flags.
+func (c *Create) function() (*openfunction.Function, error) {
+	fn := &openfunction.Function{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: c.Name,
+		},
+		Spec: openfunction.FunctionSpec{
+			Image: c.Image,
+		},
+	}
+	if c.Version != "" {
+		fn.Spec.Version = &c.Version
+	}
+	if c.ImageCredentials != "" {
+		fn.Spec.ImageCredentials = &corev1.LocalObjectReference{Name: c.ImageCredentials}
+	}
+	if c.flags.Changed("port") {
+		fn.Spec.Port = &c.Port
+	}
+
+	var err error
+	if fn.Spec.Build, err = c.Build.buildImpl(c.flags); err != nil {
+		return nil, err
+	}
+	if fn.Spec.Serving, err = c.Serving.servingImpl(c.flags); err != nil {
+		return nil, err
+	}
+	if c.Service.StandaloneIngress || len(c.Service.IngressAnnotations) != 0 {
+		fn.Spec.Service = &openfunction.ServiceImpl{
+			UseStandaloneIngress: c.Service.StandaloneIngress,
+			Annotations:          c.Service.IngressAnnotations,
+		}
+	}
+	return fn, nil
+}
+
+func (o *BuildOptions) buildImpl(flags *pflag.FlagSet) (*openfunction.BuildImpl, error) {
+	if o.GitRepoURL == "" {
+		if name := changedFlag(flags, buildFlagNames); name != "" {
+			return nil, errors.Errorf("--%s requires --git-repo-url", name)
+		}
+		return nil, nil
+	}
+
+	build := &openfunction.BuildImpl{
+		Env:     o.Env,
+		Params:  o.Params,
+		SrcRepo: &openfunction.GitRepo{Url: o.GitRepoURL},
+	}
+	if o.GitRepoRevision != "" {
+		build.SrcRepo.Revision = &o.GitRepoRevision
+	}
+	if o.GitRepoSourceSubPath != "" {
+		build.SrcRepo.SourceSubPath = &o.GitRepoSourceSubPath
+	}
+	if o.GitRepoCredentials != "" {
+		build.SrcRepo.Credentials = &corev1.LocalObjectReference{Name: o.GitRepoCredentials}
+	}
+	if o.Builder != "" {
+		build.Builder = &o.Builder
+	}
+	if o.BuilderCredentials != "" {
+		build.BuilderCredentials = &corev1.LocalObjectReference{Name: o.BuilderCredentials}
+	}
+	if o.Dockerfile != "" {
+		build.Dockerfile = &o.Dockerfile
+	}
+	build.Timeout = toDuration(o.Timeout)
+	build.BuilderMaxAge = toDuration(o.BuilderMaxAge)
+	if flags.Changed("successful-builds-history-limit") {
+		build.SuccessfulBuildsHistoryLimit = &o.SuccessfulBuildsHistoryLimit
+	}
+	if flags.Changed("failed-builds-history-limit") {
+		build.FailedBuildsHistoryLimit = &o.FailedBuildsHistoryLimit
+	}
+
+	if o.StrategyKind != "" && o.Strategy == "" {
+		return nil, errors.New("--shipwright-strategy-kind requires --shipwright-strategy")
+	}
+	if o.Strategy != "" || o.StrategyTimeout > 0 {
+		build.Shipwright = &openfunction.ShipwrightEngine{Timeout: toDuration(o.StrategyTimeout)}
+		if o.Strategy != "" {
+			build.Shipwright.Strategy = &openfunction.Strategy{Name: o.Strategy}
+			if o.StrategyKind != "" {
+				build.Shipwright.Strategy.Kind = &o.StrategyKind
+			}
+		}
+	}
+	return build, nil
+}
+
+func (o *ServingOptions) servingImpl(flags *pflag.FlagSet) (*openfunction.ServingImpl, error) {
+	if o.Runtime == "" {
+		if name := changedFlag(flags, servingFlagNames); name != "" {
+			return nil, errors.Errorf("--%s requires --runtime", name)
+		}
+		return nil, nil
+	}
+
+	runtime := openfunction.Runtime(o.Runtime)
+	if runtime != openfunction.Knative && runtime != openfunction.Async {
+		return nil, errors.Errorf("invalid --runtime %s, must be %s or %s", o.Runtime, openfunction.Knative, openfunction.Async)
+	}
+
+	serving := &openfunction.ServingImpl{
+		Runtime:     runtime,
+		Params:      o.Params,
+		Labels:      o.Labels,
+		Annotations: o.Annotations,
+		Timeout:     toDuration(o.Timeout),
+		Template: &corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:            "function",
+				ImagePullPolicy: corev1.PullAlways,
+			}},
+		},
+	}
+
+	var err error
+	if serving.ScaleOptions, err = o.scaleOptions(flags); err != nil {
+		return nil, err
+	}
+	for _, v := range o.Triggers {
+		trigger, err := parseTrigger(v)
+		if err != nil {
+			return nil, err
+		}
+		serving.Triggers = append(serving.Triggers, *trigger)
+	}
+	if serving.Inputs, err = parseDaprIOs("dapr-input", o.Inputs); err != nil {
+		return nil, err
+	}
+	if serving.Outputs, err = parseDaprIOs("dapr-output", o.Outputs); err != nil {
+		return nil, err
+	}
+	if serving.Bindings, err = parseComponents("dapr-binding", o.Bindings); err != nil {
+		return nil, err
+	}
+	if serving.Pubsub, err = parseComponents("dapr-pubsub", o.Pubsub); err != nil {
+		return nil, err
+	}
+	return serving, nil
+}
+
+func (o *ServingOptions) scaleOptions(flags *pflag.FlagSet) (*openfunction.ScaleOptions, error) {
+	if changedFlag(flags, []string{"min-replicas", "max-replicas", "knative-scale", "keda-scaled-object", "keda-scaled-job"}) == "" {
+		return nil, nil
+	}
+
+	scale := &openfunction.ScaleOptions{}
+	if flags.Changed("min-replicas") {
+		scale.MinReplicas = &o.MinReplicas
+	}
+	if flags.Changed("max-replicas") {
+		scale.MaxReplicas = &o.MaxReplicas
+	}
+	if len(o.KnativeScale) != 0 {
+		scale.Knative = &o.KnativeScale
+	}
+
+	if len(o.ScaledObject) != 0 || len(o.ScaledJob) != 0 {
+		scale.Keda = &openfunction.KedaScaleOptions{}
+	}
+	if len(o.ScaledObject) != 0 {
+		so := &openfunction.KedaScaledObject{}
+		fields := map[string]**int32{
+			"pollingInterval": &so.PollingInterval,
+			"cooldownPeriod":  &so.CooldownPeriod,
+			"minReplicaCount": &so.MinReplicaCount,
+			"maxReplicaCount": &so.MaxReplicaCount,
+		}
+		for k, v := range o.ScaledObject {
+			if k == "workloadType" {
+				so.WorkloadType = v
+				continue
+			}
+			if err := setInt32Field(fields, "keda-scaled-object", k, v); err != nil {
+				return nil, err
+			}
+		}
+		scale.Keda.ScaledObject = so
+	}
+	if len(o.ScaledJob) != 0 {
+		sj := &openfunction.KedaScaledJob{}
+		fields := map[string]**int32{
+			"pollingInterval":            &sj.PollingInterval,
+			"successfulJobsHistoryLimit": &sj.SuccessfulJobsHistoryLimit,
+			"failedJobsHistoryLimit":     &sj.FailedJobsHistoryLimit,
+			"maxReplicaCount":            &sj.MaxReplicaCount,
+		}
+		for k, v := range o.ScaledJob {
+			if k == "restartPolicy" {
+				policy := corev1.RestartPolicy(v)
+				sj.RestartPolicy = &policy
+				continue
+			}
+			if err := setInt32Field(fields, "keda-scaled-job", k, v); err != nil {
+				return nil, err
+			}
+		}
+		scale.Keda.ScaledJob = sj
+	}
+	return scale, nil
+}
+
+func parseTrigger(value string) (*openfunction.Triggers, error) {
+	fields, err := parseFields("trigger", value)
+	if err != nil {
+		return nil, err
+	}
+
+	trigger := &openfunction.Triggers{}
+	trigger.Metadata = map[string]string{}
+	var authKind string
+	for k, v := range fields {
+		switch {
+		case k == "type":
+			trigger.Type = v
+		case k == "name":
+			trigger.Name = v
+		case k == "targetKind":
+			kind := openfunction.ScaleTargetKind(v)
+			if kind != openfunction.ScaledObject && kind != openfunction.ScaledJob {
+				return nil, errors.Errorf("invalid --trigger %s, targetKind must be %s or %s", value, openfunction.ScaledObject, openfunction.ScaledJob)
+			}
+			trigger.TargetKind = &kind
+		case k == "authenticationRef":
+			trigger.AuthenticationRef = &kedav1alpha1.ScaledObjectAuthRef{Name: v}
+		case k == "authenticationKind":
+			authKind = v
+		case k == "fallback":
+			fallback, err := parseInt32("trigger", k, v)
+			if err != nil {
+				return nil, err
+			}
+			trigger.FallbackReplicas = fallback
+		case strings.HasPrefix(k, "metadata."):
+			trigger.Metadata[strings.TrimPrefix(k, "metadata.")] = v
+		default:
+			return nil, errors.Errorf("invalid --trigger %s, unknown key %s", value, k)
+		}
+	}
+
+	if trigger.Type == "" {
+		return nil, errors.Errorf("invalid --trigger %s, type is required", value)
+	}
+	if authKind != "" {
+		if trigger.AuthenticationRef == nil {
+			return nil, errors.Errorf("invalid --trigger %s, authenticationKind requires authenticationRef", value)
+		}
+		trigger.AuthenticationRef.Kind = authKind
+	}
+	return trigger, nil
+}
+
+func parseDaprIOs(flag string, values []string) ([]*openfunction.DaprIO, error) {
+	var ios []*openfunction.DaprIO
+	for _, value := range values {
+		fields, err := parseFields(flag, value)
+		if err != nil {
+			return nil, err
+		}
+
+		io := &openfunction.DaprIO{}
+		for k, v := range fields {
+			switch {
+			case k == "name":
+				io.Name = v
+			case k == "component":
+				io.Component = v
+			case k == "topic":
+				io.Topic = v
+			case k == "operation":
+				io.Operation = v
+			case strings.HasPrefix(k, "params."):
+				if io.Params == nil {
+					io.Params = map[string]string{}
+				}
+				io.Params[strings.TrimPrefix(k, "params.")] = v
+			default:
+				return nil, errors.Errorf("invalid --%s %s, unknown key %s", flag, value, k)
+			}
+		}
+
+		if io.Name == "" || io.Component == "" {
+			return nil, errors.Errorf("invalid --%s %s, name and component are required", flag, value)
+		}
+		ios = append(ios, io)
+	}
+	return ios, nil
+}
+
+func parseComponents(flag string, values []string) (map[string]*componentsv1alpha1.ComponentSpec, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	components := map[string]*componentsv1alpha1.ComponentSpec{}
+	for _, value := range values {
+		fields, err := parseFields(flag, value)
+		if err != nil {
+			return nil, err
+		}
+
+		name := fields["name"]
+		spec := &componentsv1alpha1.ComponentSpec{Version: "v1", Metadata: []componentsv1alpha1.MetadataItem{}}
+		for k, v := range fields {
+			switch {
+			case k == "name":
+			case k == "type":
+				spec.Type = v
+			case k == "version":
+				spec.Version = v
+			case k == "initTimeout":
+				spec.InitTimeout = v
+			case k == "ignoreErrors":
+				if spec.IgnoreErrors, err = strconv.ParseBool(v); err != nil {
+					return nil, errors.Errorf("invalid --%s %s, ignoreErrors must be a boolean", flag, value)
+				}
+			case strings.HasPrefix(k, "metadata."):
+				raw, err := json.Marshal(v)
+				if err != nil {
+					return nil, err
+				}
+				item := componentsv1alpha1.MetadataItem{Name: strings.TrimPrefix(k, "metadata.")}
+				item.Value.Raw = raw
+				spec.Metadata = append(spec.Metadata, item)
+			case strings.HasPrefix(k, "secretKeyRef."):
+				ref := strings.SplitN(v, "/", 2)
+				if len(ref) != 2 || ref[0] == "" || ref[1] == "" {
+					return nil, errors.Errorf("invalid --%s %s, %s must be in the form of SECRET/KEY", flag, value, k)
+				}
+				spec.Metadata = append(spec.Metadata, componentsv1alpha1.MetadataItem{
+					Name:         strings.TrimPrefix(k, "secretKeyRef."),
+					SecretKeyRef: componentsv1alpha1.SecretKeyRef{Name: ref[0], Key: ref[1]},
+				})
+			default:
+				return nil, errors.Errorf("invalid --%s %s, unknown key %s", flag, value, k)
+			}
+		}
+
+		if name == "" || spec.Type == "" {
+			return nil, errors.Errorf("invalid --%s %s, name and type are required", flag, value)
+		}
+		if _, ok := components[name]; ok {
+			return nil, errors.Errorf("duplicate --%s %s", flag, name)
+		}
+		sort.Slice(spec.Metadata, func(i, j int) bool {
+			return spec.Metadata[i].Name < spec.Metadata[j].Name
+		})
+		components[name] = spec
+	}
+	return components, nil
+}
+
+// parseFields parses a flag value in the form of key=value,key=value,
+// the pairs containing commas can be quoted as in CSV.
+func parseFields(flag string, value string) (map[string]string, error) {
+	records, err := csv.NewReader(strings.NewReader(value)).Read()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid --%s %s", flag, value)
+	}
+
+	fields := map[string]string{}
+	for _, record := range records {
+		kv := strings.SplitN(record, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, errors.Errorf("invalid --%s %s, must be in the form of key=value,key=value", flag, value)
+		}
+		fields[kv[0]] = kv[1]
+	}
+	return fields, nil
+}
+
+func setInt32Field(fields map[string]**int32, flag string, key string, value string) error {
+	field, ok := fields[key]
+	if !ok {
+		return errors.Errorf("invalid --%s, unknown key %s", flag, key)
+	}
+
+	var err error
+	*field, err = parseInt32(flag, key, value)
+	return err
+}
+
+func parseInt32(flag string, key string, value string) (*int32, error) {
+	i, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return nil, errors.Errorf("invalid --%s, %s must be an integer", flag, key)
+	}
+	i32 := int32(i)
+	return &i32, nil
+}
+
+func toDuration(d time.Duration) *metav1.Duration {
+	if d <= 0 {
+		return nil
+	}
+	return &metav1.Duration{Duration: d}
+}
+
+// changedFlag returns the first of the flags set on the command line.
+func changedFlag(flags *pflag.FlagSet, names []string) string {
+	for _, name := range names {
+		if flags.Changed(name) {
+			return name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,176 @@
+package subcommand
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	componentsv1alpha1 "github.com/dapr/dapr/pkg/apis/components/v1alpha1"
+	kedav1alpha1 "github.com/kedacore/keda/v2/api/v1alpha1"
+	openfunction "github.com/openfunction/apis/core/v1beta1"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/diff"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func TestCreateFunction(t *testing.T) {
+	var (
+		version  = "v1.0.0"
+		port     = int32(8081)
+		zero     = int32(0)
+		builder  = "openfunction/builder-go:latest"
+		subPath  = "hello-world-go"
+		kind     = openfunction.ScaledObject
+		interval = int32(15)
+		template = &corev1.PodSpec{Containers: []corev1.Container{{Name: "function", ImagePullPolicy: corev1.PullAlways}}}
+	)
+	newFunction := func(spec openfunction.FunctionSpec) *openfunction.Function {
+		spec.Image = "demo/sample:v1"
+		spec.Version = &version
+		return &openfunction.Function{ObjectMeta: metav1.ObjectMeta{Name: "sample"}, Spec: spec}
+	}
+	metadata := func(name string, value string) componentsv1alpha1.MetadataItem {
+		item := componentsv1alpha1.MetadataItem{Name: name}
+		item.Value.Raw = []byte(`"` + value + `"`)
+		return item
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    *openfunction.Function
+		wantErr string
+	}{
+		{
+			name: "image only",
+			want: newFunction(openfunction.FunctionSpec{}),
+		},
+		{
+			name: "build",
+			args: []string{
+				"--port=8081", "--image-credentials=push-secret",
+				"--git-repo-url=https://github.com/OpenFunction/samples.git", "--git-repo-source-sub-path=hello-world-go",
+				"--builder=" + builder, "--build-param=RUN_IMAGE=demo/run", "--build-timeout=10m",
+				"--shipwright-strategy=openfunction", "--successful-builds-history-limit=0",
+			},
+			want: newFunction(openfunction.FunctionSpec{
+				Port:             &port,
+				ImageCredentials: &corev1.LocalObjectReference{Name: "push-secret"},
+				Build: &openfunction.BuildImpl{
+					Builder:                      &builder,
+					Params:                       map[string]string{"RUN_IMAGE": "demo/run"},
+					SrcRepo:                      &openfunction.GitRepo{Url: "https://github.com/OpenFunction/samples.git", SourceSubPath: &subPath},
+					Timeout:                      &metav1.Duration{Duration: 10 * time.Minute},
+					Shipwright:                   &openfunction.ShipwrightEngine{Strategy: &openfunction.Strategy{Name: "openfunction"}},
+					SuccessfulBuildsHistoryLimit: &zero,
+				},
+			}),
+		},
+		{
+			name: "knative serving",
+			args: []string{"--runtime=knative", "--min-replicas=0", "--knative-scale=autoscaling.knative.dev/target=10", "--serving-param=FOO=bar"},
+			want: newFunction(openfunction.FunctionSpec{
+				Serving: &openfunction.ServingImpl{
+					Runtime: openfunction.Knative,
+					Params:  map[string]string{"FOO": "bar"},
+					ScaleOptions: &openfunction.ScaleOptions{
+						MinReplicas: &zero,
+						Knative:     &map[string]string{"autoscaling.knative.dev/target": "10"},
+					},
+					Template: template,
+				},
+			}),
+		},
+		{
+			name: "async serving",
+			args: []string{
+				"--runtime=async", "--keda-scaled-object=pollingInterval=15",
+				"--trigger=type=kafka,targetKind=object,metadata.topic=sample",
+				"--dapr-input=name=consumer,component=kafka",
+				"--dapr-output=name=producer,component=kafka,operation=create,params.key=value",
+				`--dapr-binding=name=kafka,type=bindings.kafka,"metadata.brokers=k1:9092,k2:9092",secretKeyRef.saslPassword=kafka/password`,
+			},
+			want: newFunction(openfunction.FunctionSpec{
+				Serving: &openfunction.ServingImpl{
+					Runtime: openfunction.Async,
+					ScaleOptions: &openfunction.ScaleOptions{
+						Keda: &openfunction.KedaScaleOptions{ScaledObject: &openfunction.KedaScaledObject{PollingInterval: &interval}},
+					},
+					Triggers: []openfunction.Triggers{{
+						ScaleTriggers: kedav1alpha1.ScaleTriggers{Type: "kafka", Metadata: map[string]string{"topic": "sample"}},
+						TargetKind:    &kind,
+					}},
+					Inputs:  []*openfunction.DaprIO{{Name: "consumer", Component: "kafka"}},
+					Outputs: []*openfunction.DaprIO{{Name: "producer", Component: "kafka", Operation: "create", Params: map[string]string{"key": "value"}}},
+					Bindings: map[string]*componentsv1alpha1.ComponentSpec{"kafka": {
+						Type:    "bindings.kafka",
+						Version: "v1",
+						Metadata: []componentsv1alpha1.MetadataItem{
+							metadata("brokers", "k1:9092,k2:9092"),
+							{Name: "saslPassword", SecretKeyRef: componentsv1alpha1.SecretKeyRef{Name: "kafka", Key: "password"}},
+						},
+					}},
+					Template: template,
+				},
+			}),
+		},
+		{
+			name:    "build flag without git repository",
+			args:    []string{"--builder=" + builder},
+			wantErr: "--builder requires --git-repo-url",
+		},
+		{
+			name:    "serving flag without runtime",
+			args:    []string{"--dapr-input=name=consumer,component=kafka"},
+			wantErr: "--dapr-input requires --runtime",
+		},
+		{
+			name:    "invalid runtime",
+			args:    []string{"--runtime=openfuncasync"},
+			wantErr: "invalid --runtime openfuncasync",
+		},
+		{
+			name:    "input without component",
+			args:    []string{"--runtime=async", "--dapr-input=name=consumer"},
+			wantErr: "name and component are required",
+		},
+		{
+			name:    "unknown trigger key",
+			args:    []string{"--runtime=async", "--trigger=type=kafka,topic=sample"},
+			wantErr: "unknown key topic",
+		},
+		{
+			name:    "invalid secret reference",
+			args:    []string{"--runtime=async", "--dapr-pubsub=name=redis,type=pubsub.redis,secretKeyRef.password=redis"},
+			wantErr: "secretKeyRef.password must be in the form of SECRET/KEY",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCreate(genericclioptions.IOStreams{})
+			cmd := &cobra.Command{}
+			c.AddFlags(cmd)
+			if err := cmd.ParseFlags(append([]string{"--image=demo/sample:v1", "--version=" + version}, tt.args...)); err != nil {
+				t.Fatal(err)
+			}
+			if err := c.Complete(cmd, []string{"sample"}); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := c.function()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+			if tt.wantErr == "" && !equality.Semantic.DeepEqual(got, tt.want) {
+				t.Errorf("unexpected function:\n%s", diff.ObjectReflectDiff(tt.want, got))
+			}
+		})
+	}
+}
@@ -3,9 +3,11 @@ package subcommand
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"runtime"
+	"strings"
 	"syscall"
 	"time"
 
@@ -14,9 +16,14 @@ import (
 	"github.com/OpenFunction/cli/pkg/cmd/util/spinners"
 	"github.com/OpenFunction/cli/pkg/components/common"
 	"github.com/OpenFunction/cli/pkg/components/inventory"
+	openfunction "github.com/openfunction/apis/core/v1beta1"
+	fnclient "github.com/openfunction/pkg/client/clientset/versioned"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/dynamic"
 	k8s "k8s.io/client-go/kubernetes"
 )
 
@@ -35,6 +42,7 @@ type Demo struct {
 const (
 	DemoYamlFile   = "https://raw.githubusercontent.com/OpenFunction/OpenFunction/main/config/samples/function-sample-serving-only.yaml"
 	DemoYamlFileCN = "https://cdn.jsdelivr.net/gh/OpenFunction/OpenFunction@main/config/samples/function-sample-serving-only.yaml"
+	DemoFunction   = "function-sample-serving-only"
 )
 
 // NewDemo returns an initialized Init instance
@@ -81,11 +89,11 @@ func (i *Demo) ValidateArgs(cmd *cobra.Command, args []string) error {
 func (i *Demo) RunKind(cf *genericclioptions.ConfigFlags, cmd *cobra.Command) error {
 	defer func() {
 		if i.AutoPrune {
-			i.deleteCluster()
+			i.deleteCluster(cf)
 		}
 	}()
 
-	operator := common.NewOperator(runtime.GOOS, runtime.GOARCH, i.OpenFunctionVersion, i.Timeout, i.RegionCN, i.Verbose)
+	operator := common.NewOperator(cf, runtime.GOOS, runtime.GOARCH, i.OpenFunctionVersion, i.Timeout, i.RegionCN, i.Verbose)
 
 	ctx, done := context.WithTimeout(
 		context.Background(),
@@ -130,7 +138,7 @@ func (i *Demo) RunKind(cf *genericclioptions.ConfigFlags, cmd *cobra.Command) er
 		true,
 		true,
 		true,
-		true,
+		false,
 		i.OpenFunctionVersion,
 	)
 	if err != nil {
@@ -149,79 +157,19 @@ func (i *Demo) RunKind(cf *genericclioptions.ConfigFlags, cmd *cobra.Command) er
 	}
 	defer operator.RecordInventory(ctx)
 
-	// grp2 for installing dependent components
-	grp2 := spinners.NewSpinnerGroup()
-	count := 0
-
-	count += 1
-	grp2.AddSpinner()
-	go func(ctx context.Context, idx int) {
-		spinner := grp2.At(idx).WithName("Dapr")
-		installDapr(ctx, spinner, operator)
-	}(ctx, count-1)
-
-	count += 1
-	grp2.AddSpinner()
-	go func(ctx context.Context, idx int) {
-		spinner := grp2.At(idx).WithName("Keda")
-		installKeda(ctx, spinner, cl, operator)
-	}(ctx, count-1)
-
-	count += 1
-	grp2.AddSpinner()
-	go func(ctx context.Context, idx int) {
-		spinner := grp2.At(idx).WithName("Knative Serving")
-		installKnativeServing(ctx, spinner, cl, operator)
-	}(ctx, count-1)
-
-	count += 1
-	grp2.AddSpinner()
-	go func(ctx context.Context, idx int) {
-		spinner := grp2.At(idx).WithName("Shipwright")
-		installShipwright(ctx, spinner, cl, operator)
-	}(ctx, count-1)
-
-	count += 1
-	grp2.AddSpinner()
-	go func(ctx context.Context, idx int) {
-		spinner := grp2.At(idx).WithName("Cert Manager")
-		installCertManager(ctx, spinner, cl, operator)
-	}(ctx, count-1)
-
-	grp2.Start(ctx)
-	if err := grp2.Wait(); err != nil {
-		return errors.New(util.TaskFail(err.Error()))
-	}
-
-	// grp3 for installing the latest stable OpenFunction
-	grp3 := spinners.NewSpinnerGroup()
-
-	grp3.AddSpinner()
-	go func(ctx context.Context, idx int) {
-		spinner := grp3.At(idx).WithName("OpenFunction")
-		installOpenFunction(ctx, spinner, cl, operator)
-	}(ctx, 0)
-
-	grp3.Start(ctx)
-	if err := grp3.Wait(); err != nil {
-		return errors.New(util.TaskFail(err.Error()))
+	// Install OpenFunction and its dependencies, then provision the demo.
+	sched, err := newInstallScheduler(cl, operator, nil, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to schedule the installation")
 	}
-
-	// grp4 for provisioning the demo
-	grp4 := spinners.NewSpinnerGroup()
-
-	grp4.AddSpinner()
-	go func(ctx context.Context, idx int) {
-		spinner := grp4.At(idx).WithName("Demo")
+	sched.Add("Demo", []string{inventory.OpenFunctionName}, func(ctx context.Context, spinner *spinners.Spinner) {
 		i.provisionDemoFunction(ctx, spinner, cl, operator)
-	}(ctx, 0)
-
-	grp4.Start(ctx)
-	if err := grp4.Wait(); err != nil {
+	})
+	if err := sched.Run(ctx); err != nil {
 		return errors.New(util.TaskFail(err.Error()))
 	}
 
-	if err := i.accessDemoFunction(ctx, cl, operator); err != nil {
+	if err := i.accessDemoFunction(ctx, cf); err != nil {
 		return errors.New(util.TaskFail(err.Error()))
 	}
 
@@ -249,8 +197,8 @@ func (i *Demo) createCluster(ctx context.Context, spinner *spinners.Spinner, ope
 	spinner.Done()
 }
 
-func (i *Demo) deleteCluster() {
-	operator := common.NewOperator(runtime.GOOS, runtime.GOARCH, i.OpenFunctionVersion, i.Timeout, i.RegionCN, i.Verbose)
+func (i *Demo) deleteCluster(cf *genericclioptions.ConfigFlags) {
+	operator := common.NewOperator(cf, runtime.GOOS, runtime.GOARCH, i.OpenFunctionVersion, i.Timeout, i.RegionCN, i.Verbose)
 	ctx, done := context.WithTimeout(
 		context.Background(),
 		i.Timeout,
@@ -263,7 +211,7 @@ func (i *Demo) deleteCluster() {
 	}
 }
 
-func (i *Demo) provisionDemoFunction(ctx context.Context, spinner *spinners.Spinner, cl *k8s.Clientset, operator *common.Operator) {
+func (i *Demo) provisionDemoFunction(ctx context.Context, spinner *spinners.Spinner, cl k8s.Interface, operator *common.Operator) {
 	ctx, done := context.WithCancel(ctx)
 	defer done()
 	var demo string
@@ -298,21 +246,68 @@ func (i *Demo) provisionDemoFunction(ctx context.Context, spinner *spinners.Spin
 	spinner.Done()
 }
 
-func (i *Demo) accessDemoFunction(ctx context.Context, cl *k8s.Clientset, operator *common.Operator) error {
+func (i *Demo) accessDemoFunction(ctx context.Context, cf *genericclioptions.ConfigFlags) error {
 	fmt.Print(util.YellowItalic(" -> Fetching the URL of demo function...\r"))
-	endpoint, err := operator.PrintEndpoint(ctx)
+	endpoint, err := i.getDemoEndpoint(ctx, cf)
 	if err != nil {
 		return errors.Wrap(err, "Failed to fetch the Endpoint")
 	}
 	fmt.Println(util.YellowItalic(" -> You can use the following URL to access the demo function:"))
 	fmt.Println(endpoint)
 	fmt.Print(util.YellowItalic("\n -> We are now accessing the URL above...\r"))
-	if rt, err := operator.CurlOpenFunction(ctx, endpoint); err != nil {
+	req := &invokeRequest{method: http.MethodGet, header: http.Header{}}
+	resp, body, err := req.send(ctx, newInvokeHTTPClient(), endpoint)
+	if err != nil {
 		return errors.Wrap(err, "Failed to access the URL of OpenFunction demo")
-	} else {
-		fmt.Println(util.YellowItalic(" -> We have accessed the URL above and get the following information:"))
-		fmt.Println(rt)
 	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return errors.Errorf("Failed to access the URL of OpenFunction demo, the response is %s", resp.Status)
+	}
+	fmt.Println(util.YellowItalic(" -> We have accessed the URL above and get the following information:"))
+	fmt.Println(strings.TrimSuffix(string(body), "\n"))
 
 	return nil
 }
+
+// getDemoEndpoint waits for the demo function to be running and returns the URL of its Knative Service.
+func (i *Demo) getDemoEndpoint(ctx context.Context, cf *genericclioptions.ConfigFlags) (string, error) {
+	config, err := cf.ToRESTConfig()
+	if err != nil {
+		return "", err
+	}
+	dc, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return "", err
+	}
+	client.SetConfigDefaults(config)
+	fc, err := fnclient.NewForConfig(config)
+	if err != nil {
+		return "", err
+	}
+	namespace, _, err := cf.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return "", err
+	}
+
+	fn, err := waitForFunction(ctx, fc, namespace, DemoFunction, []waitCondition{{Phase: waitForServing, State: openfunction.Running}}, nil)
+	if err != nil {
+		return "", err
+	}
+
+	serving, err := fc.CoreV1beta1().Servings(namespace).Get(ctx, fn.Status.Serving.ResourceRef, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	ksvc, err := dc.Resource(knativeServiceGVR).Namespace(namespace).Get(ctx, serving.Status.ResourceRef[knativeServiceRef], metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	url, _, err := unstructured.NestedString(ksvc.Object, "status", "url")
+	if err != nil {
+		return "", err
+	}
+	if url == "" {
+		return "", errors.Errorf("the Knative Service %s has no URL", ksvc.GetName())
+	}
+	return url, nil
+}
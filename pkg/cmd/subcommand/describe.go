@@ -0,0 +1,604 @@
+package subcommand
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/OpenFunction/cli/pkg/cmd/util"
+	cc "github.com/OpenFunction/cli/pkg/cmd/util/client"
+	"github.com/jedib0t/go-pretty/v6/list"
+	openfunction "github.com/openfunction/apis/core/v1beta1"
+	client "github.com/openfunction/pkg/client/clientset/versioned"
+	swclient "github.com/shipwright-io/build/pkg/client/clientset/versioned"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/dynamic"
+	k8s "k8s.io/client-go/kubernetes"
+)
+
+const (
+	// The keys of the resource references of a Serving.
+	knativeServiceRef = "serving.knative.dev/service"
+	knativeComponents = "Knative/component"
+	asyncWorkloadRef  = "Async/workload"
+	asyncScalerRef    = "Async/scaler"
+	asyncComponents   = "Async/component"
+
+	buildRunLabel = "buildrun.shipwright.io/name"
+	servingLabel  = "openfunction.io/serving"
+	functionLabel = "openfunction.io/function"
+	builderLabel  = "openfunction.io/builder"
+
+	// The number of the most recent events printed for each object.
+	recentEvents = 5
+)
+
+var (
+	knativeServiceGVR = schema.GroupVersionResource{Group: "serving.knative.dev", Version: "v1", Resource: "services"}
+	daprComponentGVR  = schema.GroupVersionResource{Group: "dapr.io", Version: "v1alpha1", Resource: "components"}
+	scaledObjectGVR   = schema.GroupVersionResource{Group: "keda.sh", Version: "v1alpha1", Resource: "scaledobjects"}
+	scaledJobGVR      = schema.GroupVersionResource{Group: "keda.sh", Version: "v1alpha1", Resource: "scaledjobs"}
+)
+
+// Describe is the commandline for 'describe' sub command
+type Describe struct {
+	genericclioptions.IOStreams
+
+	Name string
+
+	namespace      string
+	functionClient client.Interface
+	swClient       swclient.Interface
+	clientSet      k8s.Interface
+	dynamicClient  dynamic.Interface
+
+	events []corev1.Event
+}
+
+const (
+	describeExample = `
+# Describe the function sample along with its builder, serving and the objects they create
+ofn describe function sample
+
+# Describe the function sample in namespace demo
+ofn describe fn sample -n demo
+`
+)
+
+// NewDescribe returns an initialized Describe instance
+func NewDescribe(ioStreams genericclioptions.IOStreams) *Describe {
+	return &Describe{
+		IOStreams: ioStreams,
+	}
+}
+
+func NewCmdDescribe(cf *genericclioptions.ConfigFlags, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "describe",
+		DisableFlagsInUseLine: true,
+		Short:                 "Show the details of a function",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(newCmdDescribeFunction(cf, ioStreams))
+	return cmd
+}
+
+func newCmdDescribeFunction(cf *genericclioptions.ConfigFlags, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	d := NewDescribe(ioStreams)
+	cmd := &cobra.Command{
+		Use:                   "function NAME",
+		Aliases:               []string{"functions", "fn"},
+		DisableFlagsInUseLine: true,
+		Short:                 "Show the details of a function",
+		Long: `
+Show the details of a function as a tree, along with its builder and serving,
+the Shipwright Build and BuildRun, the Knative Service or workload, the Dapr components, the KEDA scaler and the pods created for them,
+and the recent events of each of them.
+`,
+		Example: describeExample,
+		Args:    cobra.ExactArgs(1),
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return d.preRun(cf, args)
+		},
+
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(d.Run())
+		},
+	}
+	return cmd
+}
+
+func (d *Describe) preRun(cf *genericclioptions.ConfigFlags, args []string) error {
+	config, clientSet, err := cc.NewKubeConfigClient(cf)
+	if err != nil {
+		return err
+	}
+	d.clientSet = clientSet
+
+	d.dynamicClient, err = dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if err := cc.SetConfigDefaults(config); err != nil {
+		return err
+	}
+	d.functionClient, err = client.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	d.swClient, err = swclient.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	d.namespace, _, err = cf.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	d.Name = args[0]
+	return nil
+}
+
+func (d *Describe) Run() error {
+	ctx := context.Background()
+
+	fn, err := d.functionClient.CoreV1beta1().Functions(d.namespace).Get(ctx, d.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	events, err := d.clientSet.CoreV1().Events(d.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	d.events = events.Items
+
+	node, err := d.describeFunction(ctx, fn)
+	if err != nil {
+		return err
+	}
+
+	w := list.NewWriter()
+	w.SetStyle(list.StyleConnectedRounded)
+	w.SetOutputMirror(d.Out)
+	node.render(w)
+	w.Render()
+	return nil
+}
+
+// describeNode is an object of the tree printed by describe.
+type describeNode struct {
+	title    string
+	details  []string
+	children []*describeNode
+}
+
+func newDescribeNode(kind string, name string) *describeNode {
+	return &describeNode{title: fmt.Sprintf("%s/%s", kind, name)}
+}
+
+func (n *describeNode) addDetail(format string, a ...interface{}) {
+	n.details = append(n.details, fmt.Sprintf(format, a...))
+}
+
+func (n *describeNode) addChild(child *describeNode) {
+	if child != nil {
+		n.children = append(n.children, child)
+	}
+}
+
+func (n *describeNode) render(w list.Writer) {
+	w.AppendItem(util.WhiteBold(n.title))
+	w.Indent()
+	for _, detail := range n.details {
+		w.AppendItem(detail)
+	}
+	for _, child := range n.children {
+		child.render(w)
+	}
+	w.UnIndent()
+}
+
+func (d *Describe) describeFunction(ctx context.Context, fn *openfunction.Function) (*describeNode, error) {
+	node := newDescribeNode("Function", fn.Name)
+	node.addDetail("Namespace: %s", fn.Namespace)
+	node.addDetail("Created: %s", formatTime(fn.CreationTimestamp))
+	if fn.Status.URL != "" {
+		node.addDetail("URL: %s", fn.Status.URL)
+	}
+
+	if cond := fn.Status.Build; cond != nil {
+		node.addDetail("Build: %s", formatCondition(cond))
+		if cond.ResourceRef != "" {
+			child, err := d.describeBuilder(ctx, cond.ResourceRef)
+			if err != nil {
+				return nil, err
+			}
+			node.addChild(child)
+		}
+	}
+
+	if cond := fn.Status.Serving; cond != nil {
+		node.addDetail("Serving: %s", formatCondition(cond))
+		if cond.ResourceRef != "" {
+			child, err := d.describeServing(ctx, cond.ResourceRef)
+			if err != nil {
+				return nil, err
+			}
+			node.addChild(child)
+		}
+	}
+
+	node.addChild(d.describeEvents("Function", fn.Name))
+	return node, nil
+}
+
+func (d *Describe) describeBuilder(ctx context.Context, name string) (*describeNode, error) {
+	node := newDescribeNode("Builder", name)
+	builder, err := d.functionClient.CoreV1beta1().Builders(d.namespace).Get(ctx, name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		node.addDetail("Not found")
+		return node, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	node.addDetail("Created: %s", formatTime(builder.CreationTimestamp))
+	node.addDetail("Phase: %s, State: %s", builder.Status.Phase, builder.Status.State)
+	if builder.Status.Reason != "" {
+		node.addDetail("Reason: %s", builder.Status.Reason)
+	}
+	if builder.Status.Output != nil && builder.Status.Output.Digest != "" {
+		node.addDetail("Image digest: %s", builder.Status.Output.Digest)
+	}
+
+	buildName, buildRunName := getBuilderResourceRef(builder.Status.ResourceRef)
+	if buildName != "" {
+		child, err := d.describeShipwrightBuild(ctx, buildName)
+		if err != nil {
+			return nil, err
+		}
+		node.addChild(child)
+	}
+	if buildRunName != "" {
+		child, err := d.describeShipwrightBuildRun(ctx, buildRunName)
+		if err != nil {
+			return nil, err
+		}
+		node.addChild(child)
+	}
+
+	node.addChild(d.describeEvents("Builder", name))
+	return node, nil
+}
+
+func (d *Describe) describeShipwrightBuild(ctx context.Context, name string) (*describeNode, error) {
+	node := newDescribeNode("Build", name)
+	build, err := d.swClient.ShipwrightV1alpha1().Builds(d.namespace).Get(ctx, name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		node.addDetail("Not found")
+		return node, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	node.addDetail("Created: %s", formatTime(build.CreationTimestamp))
+	if build.Status.Registered != "" {
+		node.addDetail("Registered: %s%s", build.Status.Registered, formatReason(string(build.Status.Reason), build.Status.Message))
+	}
+	node.addChild(d.describeEvents("Build", name))
+	return node, nil
+}
+
+func (d *Describe) describeShipwrightBuildRun(ctx context.Context, name string) (*describeNode, error) {
+	node := newDescribeNode("BuildRun", name)
+	buildRun, err := d.swClient.ShipwrightV1alpha1().BuildRuns(d.namespace).Get(ctx, name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		node.addDetail("Not found")
+		return node, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	node.addDetail("Created: %s", formatTime(buildRun.CreationTimestamp))
+	if t := buildRun.Status.StartTime; t != nil {
+		node.addDetail("Started: %s", formatTime(*t))
+	}
+	if t := buildRun.Status.CompletionTime; t != nil {
+		node.addDetail("Completed: %s", formatTime(*t))
+	}
+	for _, c := range buildRun.Status.Conditions {
+		node.addDetail("Condition %s: %s%s, %s", c.Type, c.Status, formatReason(c.Reason, c.Message), formatTime(c.LastTransitionTime))
+	}
+	if out := buildRun.Status.Output; out != nil && out.Digest != "" {
+		node.addDetail("Image digest: %s", out.Digest)
+	}
+	node.addChild(d.describeEvents("BuildRun", name))
+
+	if err := d.describePods(ctx, node, fmt.Sprintf("%s=%s", buildRunLabel, name)); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func (d *Describe) describeServing(ctx context.Context, name string) (*describeNode, error) {
+	node := newDescribeNode("Serving", name)
+	serving, err := d.functionClient.CoreV1beta1().Servings(d.namespace).Get(ctx, name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		node.addDetail("Not found")
+		return node, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	node.addDetail("Created: %s", formatTime(serving.CreationTimestamp))
+	node.addDetail("Runtime: %s", serving.Spec.Runtime)
+	node.addDetail("Phase: %s, State: %s", serving.Status.Phase, serving.Status.State)
+
+	ref := serving.Status.ResourceRef
+	if name := ref[knativeServiceRef]; name != "" {
+		child, err := d.describeUnstructured(ctx, "Service", name, knativeServiceGVR)
+		if err != nil {
+			return nil, err
+		}
+		if url, ok, _ := unstructured.NestedString(child.obj, "status", "url"); ok {
+			child.addDetail("URL: %s", url)
+		}
+		node.addChild(child.describeNode)
+	}
+	if name := ref[asyncWorkloadRef]; name != "" {
+		child, err := d.describeWorkload(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		node.addChild(child)
+	}
+	for _, name := range splitRef(ref[knativeComponents] + "," + ref[asyncComponents]) {
+		child, err := d.describeUnstructured(ctx, "Component", name, daprComponentGVR)
+		if err != nil {
+			return nil, err
+		}
+		if typ, ok, _ := unstructured.NestedString(child.obj, "spec", "type"); ok {
+			child.addDetail("Type: %s", typ)
+		}
+		node.addChild(child.describeNode)
+	}
+	if name := ref[asyncScalerRef]; name != "" {
+		child, err := d.describeUnstructured(ctx, "ScaledObject", name, scaledObjectGVR)
+		if err != nil {
+			return nil, err
+		}
+		// The scaler of a job is a ScaledJob.
+		if child.obj == nil {
+			child, err = d.describeUnstructured(ctx, "ScaledJob", name, scaledJobGVR)
+			if err != nil {
+				return nil, err
+			}
+		}
+		node.addChild(child.describeNode)
+	}
+
+	node.addChild(d.describeEvents("Serving", name))
+
+	if err := d.describePods(ctx, node, fmt.Sprintf("%s=%s", servingLabel, name)); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// describeWorkload describes the Deployment or the StatefulSet of an async serving.
+func (d *Describe) describeWorkload(ctx context.Context, name string) (*describeNode, error) {
+	deploy, err := d.clientSet.AppsV1().Deployments(d.namespace).Get(ctx, name, metav1.GetOptions{})
+	if err == nil {
+		node := newDescribeNode("Deployment", name)
+		node.addDetail("Created: %s", formatTime(deploy.CreationTimestamp))
+		node.addDetail("Replicas: %d desired, %d updated, %d ready, %d available",
+			deploy.Status.Replicas, deploy.Status.UpdatedReplicas, deploy.Status.ReadyReplicas, deploy.Status.AvailableReplicas)
+		for _, c := range deploy.Status.Conditions {
+			node.addDetail("Condition %s: %s%s, %s", c.Type, c.Status, formatReason(c.Reason, c.Message), formatTime(c.LastTransitionTime))
+		}
+		node.addChild(d.describeEvents("Deployment", name))
+		return node, nil
+	}
+	if !k8serrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	sts, err := d.clientSet.AppsV1().StatefulSets(d.namespace).Get(ctx, name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		node := newDescribeNode("Deployment", name)
+		node.addDetail("Not found")
+		return node, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	node := newDescribeNode("StatefulSet", name)
+	node.addDetail("Created: %s", formatTime(sts.CreationTimestamp))
+	node.addDetail("Replicas: %d desired, %d updated, %d ready", sts.Status.Replicas, sts.Status.UpdatedReplicas, sts.Status.ReadyReplicas)
+	node.addChild(d.describeEvents("StatefulSet", name))
+	return node, nil
+}
+
+// unstructuredNode is the node of an object without a typed client, obj is nil if the object isn't found.
+type unstructuredNode struct {
+	*describeNode
+	obj map[string]interface{}
+}
+
+// describeUnstructured describes an object with its conditions in the common format of status.conditions.
+func (d *Describe) describeUnstructured(ctx context.Context, kind string, name string, gvr schema.GroupVersionResource) (*unstructuredNode, error) {
+	node := &unstructuredNode{describeNode: newDescribeNode(kind, name)}
+	obj, err := d.dynamicClient.Resource(gvr).Namespace(d.namespace).Get(ctx, name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		node.addDetail("Not found")
+		return node, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	node.obj = obj.Object
+	node.addDetail("Created: %s", formatTime(obj.GetCreationTimestamp()))
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		m, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		typ, _, _ := unstructured.NestedString(m, "type")
+		status, _, _ := unstructured.NestedString(m, "status")
+		reason, _, _ := unstructured.NestedString(m, "reason")
+		message, _, _ := unstructured.NestedString(m, "message")
+		detail := fmt.Sprintf("Condition %s: %s%s", typ, status, formatReason(reason, message))
+		if s, _, _ := unstructured.NestedString(m, "lastTransitionTime"); s != "" {
+			if t, err := time.Parse(time.RFC3339, s); err == nil {
+				detail += ", " + formatTime(metav1.NewTime(t))
+			}
+		}
+		node.addDetail(detail)
+	}
+	node.addChild(d.describeEvents(kind, name))
+	return node, nil
+}
+
+// describePods adds the pods matching selector to node.
+func (d *Describe) describePods(ctx context.Context, node *describeNode, selector string) error {
+	pods, err := d.clientSet.CoreV1().Pods(d.namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return err
+	}
+
+	for _, pod := range pods.Items {
+		child := newDescribeNode("Pod", pod.Name)
+		child.addDetail("Created: %s", formatTime(pod.CreationTimestamp))
+		child.addDetail("Phase: %s", pod.Status.Phase)
+		if pod.Spec.NodeName != "" {
+			child.addDetail("Node: %s", pod.Spec.NodeName)
+		}
+		for _, cs := range pod.Status.ContainerStatuses {
+			child.addDetail("Container %s: %s, ready: %t, restarts: %d", cs.Name, containerState(cs.State), cs.Ready, cs.RestartCount)
+		}
+		child.addChild(d.describeEvents("Pod", pod.Name))
+		node.addChild(child)
+	}
+	return nil
+}
+
+// describeEvents returns the most recent events of the object, or nil if there is none.
+func (d *Describe) describeEvents(kind string, name string) *describeNode {
+	var events []corev1.Event
+	for _, e := range d.events {
+		if e.InvolvedObject.Kind == kind && e.InvolvedObject.Name == name {
+			events = append(events, e)
+		}
+	}
+	if len(events) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		ti, tj := eventTime(events[i]), eventTime(events[j])
+		return ti.Before(&tj)
+	})
+	if len(events) > recentEvents {
+		events = events[len(events)-recentEvents:]
+	}
+
+	node := &describeNode{title: "Events"}
+	for _, e := range events {
+		reason := e.Reason
+		if e.Count > 1 {
+			reason = fmt.Sprintf("%s (x%d)", reason, e.Count)
+		}
+		node.addDetail("%s %s %s: %s", formatTime(eventTime(e)), e.Type, reason, strings.TrimSpace(e.Message))
+	}
+	return node
+}
+
+// eventTime returns the last time the event occurred.
+func eventTime(e corev1.Event) metav1.Time {
+	switch {
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp
+	case !e.EventTime.IsZero():
+		return metav1.NewTime(e.EventTime.Time)
+	default:
+		return e.CreationTimestamp
+	}
+}
+
+func containerState(s corev1.ContainerState) string {
+	switch {
+	case s.Running != nil:
+		return fmt.Sprintf("Running since %s", formatTime(s.Running.StartedAt))
+	case s.Waiting != nil:
+		return fmt.Sprintf("Waiting%s", formatReason(s.Waiting.Reason, s.Waiting.Message))
+	case s.Terminated != nil:
+		return fmt.Sprintf("Terminated%s with exit code %d", formatReason(s.Terminated.Reason, s.Terminated.Message), s.Terminated.ExitCode)
+	default:
+		return "Unknown"
+	}
+}
+
+func formatCondition(c *openfunction.Condition) string {
+	state := c.State
+	if state == "" {
+		state = "Unknown"
+	}
+	if c.ResourceRef != "" {
+		return fmt.Sprintf("%s (%s)", state, c.ResourceRef)
+	}
+	return state
+}
+
+// formatReason returns the reason and the message of a condition to be appended to its status.
+func formatReason(reason string, message string) string {
+	switch {
+	case reason != "" && message != "":
+		return fmt.Sprintf(" (%s: %s)", reason, message)
+	case reason != "":
+		return fmt.Sprintf(" (%s)", reason)
+	case message != "":
+		return fmt.Sprintf(" (%s)", message)
+	default:
+		return ""
+	}
+}
+
+func formatTime(t metav1.Time) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	return fmt.Sprintf("%s (%s ago)", t.UTC().Format(time.RFC3339), util.TranslateTimestampSince(t))
+}
+
+// splitRef returns the names of a comma separated resource reference.
+func splitRef(ref string) []string {
+	var names []string
+	for _, name := range strings.Split(ref, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,133 @@
+package subcommand
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	openfunction "github.com/openfunction/apis/core/v1beta1"
+	fakeclient "github.com/openfunction/pkg/client/clientset/versioned/fake"
+	swv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	fakeswclient "github.com/shipwright-io/build/pkg/client/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	fakek8s "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDescribeFunction(t *testing.T) {
+	const ns = "default"
+	meta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: ns}
+	}
+
+	fn := &openfunction.Function{
+		ObjectMeta: meta("sample"),
+		Status: openfunction.FunctionStatus{
+			Build:   &openfunction.Condition{State: openfunction.Succeeded, ResourceRef: "sample-builder-x"},
+			Serving: &openfunction.Condition{State: openfunction.Running, ResourceRef: "sample-serving-y"},
+			URL:     "http://sample.default.ofn.io",
+		},
+	}
+	builder := &openfunction.Builder{
+		ObjectMeta: meta("sample-builder-x"),
+		Status: openfunction.BuilderStatus{
+			Phase:       openfunction.BuildPhase,
+			State:       openfunction.Succeeded,
+			ResourceRef: map[string]string{"shipwright.io/build": "sample-build", "shipwright.io/buildRun": "sample-buildrun"},
+			Output:      &openfunction.Output{Digest: "sha256:abc"},
+		},
+	}
+	serving := &openfunction.Serving{
+		ObjectMeta: meta("sample-serving-y"),
+		Spec:       openfunction.ServingSpec{Runtime: openfunction.Knative},
+		Status: openfunction.ServingStatus{
+			Phase:       openfunction.ServingPhase,
+			State:       openfunction.Running,
+			ResourceRef: map[string]string{knativeServiceRef: "sample-ksvc", knativeComponents: "sample-component"},
+		},
+	}
+	buildRun := &swv1alpha1.BuildRun{
+		ObjectMeta: meta("sample-buildrun"),
+		Status: swv1alpha1.BuildRunStatus{
+			Conditions: swv1alpha1.Conditions{{Type: swv1alpha1.Succeeded, Status: corev1.ConditionTrue, Reason: "Succeeded"}},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample-pod", Namespace: ns, Labels: map[string]string{servingLabel: serving.Name}},
+		Status: corev1.PodStatus{
+			Phase:             corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{Name: "function", Ready: true, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}},
+		},
+	}
+	event := &corev1.Event{
+		ObjectMeta:     meta("sample-event"),
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: pod.Name},
+		Type:           corev1.EventTypeWarning,
+		Reason:         "BackOff",
+		Message:        "Back-off restarting failed container",
+		Count:          3,
+	}
+	ksvc := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "serving.knative.dev/v1",
+		"kind":       "Service",
+		"metadata":   map[string]interface{}{"name": "sample-ksvc", "namespace": ns},
+		"status": map[string]interface{}{
+			"url":        "http://sample-ksvc.default.example.com",
+			"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "True"}},
+		},
+	}}
+
+	d := NewDescribe(genericclioptions.IOStreams{})
+	d.namespace = ns
+	d.functionClient = fakeclient.NewSimpleClientset(fn, builder, serving)
+	d.swClient = fakeswclient.NewSimpleClientset(buildRun)
+	d.clientSet = fakek8s.NewSimpleClientset(pod)
+	d.dynamicClient = fakedynamic.NewSimpleDynamicClient(runtime.NewScheme(), ksvc)
+	d.events = []corev1.Event{*event}
+
+	node, err := d.describeFunction(context.Background(), fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := &bytes.Buffer{}
+	render(node, out, "")
+	for _, want := range []string{
+		"Function/sample",
+		"Build: Succeeded (sample-builder-x)",
+		"  Builder/sample-builder-x",
+		"    Image digest: sha256:abc",
+		"    Build/sample-build",
+		"      Not found",
+		"    BuildRun/sample-buildrun",
+		"      Condition Succeeded: True (Succeeded), <unknown>",
+		"  Serving/sample-serving-y",
+		"    Service/sample-ksvc",
+		"      Condition Ready: True",
+		"      URL: http://sample-ksvc.default.example.com",
+		"    Component/sample-component",
+		"    Pod/sample-pod",
+		"      Container function: Running since <unknown>, ready: true, restarts: 0",
+		"        <unknown> Warning BackOff (x3): Back-off restarting failed container",
+	} {
+		if !strings.Contains(out.String(), want+"\n") {
+			t.Errorf("the tree doesn't contain %q\n%s", want, out)
+		}
+	}
+}
+
+// render prints the tree without the connectors of the list writer.
+func render(n *describeNode, out *bytes.Buffer, indent string) {
+	out.WriteString(indent + n.title + "\n")
+	for _, detail := range n.details {
+		out.WriteString(indent + "  " + detail + "\n")
+	}
+	for _, child := range n.children {
+		render(child, out, indent+"  ")
+	}
+}
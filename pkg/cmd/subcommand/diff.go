@@ -0,0 +1,334 @@
+package subcommand
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/OpenFunction/cli/pkg/cmd/util"
+	cc "github.com/OpenFunction/cli/pkg/cmd/util/client"
+	"github.com/fatih/color"
+	client "github.com/openfunction/pkg/client/clientset/versioned"
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/resource"
+	"sigs.k8s.io/yaml"
+
+	openfunction "github.com/openfunction/apis/core/v1beta1"
+)
+
+const (
+	// The exit codes of diff, which are the same as the ones of diff(1).
+	diffExitDifferent = 1
+	diffExitError     = 2
+
+	diffContextLines = 3
+)
+
+var (
+	diffHeader  = color.New(color.Bold).SprintFunc()
+	diffHunk    = color.New(color.FgCyan).SprintFunc()
+	diffRemoved = color.New(color.FgRed).SprintFunc()
+	diffAdded   = color.New(color.FgGreen).SprintFunc()
+)
+
+// Diff is the commandline for 'diff' sub command
+type Diff struct {
+	genericclioptions.IOStreams
+
+	FilenameOptions resource.FilenameOptions
+	ForceConflicts  bool
+
+	namespace        string
+	enforceNamespace bool
+}
+
+const (
+	diffExample = `
+# Show the changes function.yaml would make to the functions in the cluster
+ofn diff -f function.yaml
+
+# Fail a CI job if the functions of a kustomization have drifted from the cluster
+ofn diff -k functions/ > /dev/null || echo "functions are out of sync"
+`
+)
+
+// NewDiff returns an initialized Diff instance
+func NewDiff(ioStreams genericclioptions.IOStreams) *Diff {
+	return &Diff{
+		IOStreams: ioStreams,
+	}
+}
+
+func NewCmdDiff(cf *genericclioptions.ConfigFlags, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	var fc client.Interface
+
+	d := NewDiff(ioStreams)
+	cmd := &cobra.Command{
+		Use:                   "diff -f FILENAME",
+		DisableFlagsInUseLine: true,
+		Short:                 "Diff the functions in the cluster against the ones which would be applied",
+		Long: `
+Diff the functions in the cluster against the ones which would be applied by 'ofn apply',
+along with the builders and the servings the changes would create.
+
+The functions are applied with a server-side dry run, nothing is persisted.
+
+Exit status:
+ 0 No differences were found.
+ 1 Differences were found.
+ >1 ofn or diff failed with an error.
+`,
+		Example: diffExample,
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			config, err := cf.ToRESTConfig()
+			if err != nil {
+				return err
+			}
+			cc.SetConfigDefaults(config)
+			fc, err = client.NewForConfig(config)
+			if err != nil {
+				return err
+			}
+
+			d.namespace, d.enforceNamespace, err = cf.ToRawKubeConfigLoader().Namespace()
+			return err
+		},
+
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErrWithCode(d.Validate(cmd), diffExitError)
+			different, err := d.Run(fc, cmd)
+			util.CheckErrWithCode(err, diffExitError)
+			if different {
+				util.Exit(diffExitDifferent)
+			}
+		},
+	}
+
+	usage := "contains the functions to diff"
+	AddFilenameOptionFlags(cmd, &d.FilenameOptions, usage)
+	cmd.Flags().BoolVar(&d.ForceConflicts, "force-conflicts", d.ForceConflicts, "Diff as if the ownership of the fields managed by other field managers was taken.")
+	return cmd
+}
+
+func (d *Diff) Validate(cmd *cobra.Command) error {
+	if len(d.FilenameOptions.Filenames) == 0 && d.FilenameOptions.Kustomize == "" {
+		return util.UsageErrorf(cmd, "-f or -k is required")
+	}
+	return nil
+}
+
+// Run prints the differences of the functions and returns true if there is any.
+func (d *Diff) Run(fc client.Interface, cmd *cobra.Command) (bool, error) {
+	ctx := context.Background()
+
+	fns, err := getApplyConfigurations(d.FilenameOptions)
+	if err != nil {
+		return false, err
+	}
+
+	different := false
+	for _, fn := range fns {
+		if fn.GetNamespace() == "" {
+			fn.SetNamespace(d.namespace)
+		}
+		if d.enforceNamespace && fn.GetNamespace() != d.namespace {
+			return false, errors.Errorf("the namespace of function %s is %s, which doesn't match the namespace %s of the command", fn.GetName(), fn.GetNamespace(), d.namespace)
+		}
+
+		live, err := fc.CoreV1beta1().Functions(fn.GetNamespace()).Get(ctx, fn.GetName(), metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			live = nil
+		} else if err != nil {
+			return false, err
+		}
+
+		merged, err := applyFunction(ctx, fc, fn, true, d.ForceConflicts)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to apply function %s/%s with a server-side dry run", fn.GetNamespace(), fn.GetName())
+		}
+
+		changed, err := diffFunction(d.Out, live, merged)
+		if err != nil {
+			return false, err
+		}
+		different = different || changed
+	}
+	return different, nil
+}
+
+// diffFunction prints the unified diffs of live and merged, and of the builders and the servings the controller derives from them.
+// A nil live means that merged would be created.
+func diffFunction(w io.Writer, live *openfunction.Function, merged *openfunction.Function) (bool, error) {
+	path := fmt.Sprintf("function.%s/%s", openfunction.GroupVersion.Group, merged.Name)
+
+	pairs := []struct {
+		path     string
+		from, to interface{}
+	}{
+		{path, functionView(live), functionView(merged)},
+		{path + "/builder", builderView(live), builderView(merged)},
+		{path + "/serving", servingView(live), servingView(merged)},
+	}
+
+	changed := false
+	for _, p := range pairs {
+		from, err := toYAML(p.from)
+		if err != nil {
+			return false, err
+		}
+		to, err := toYAML(p.to)
+		if err != nil {
+			return false, err
+		}
+
+		text, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(from),
+			B:        difflib.SplitLines(to),
+			FromFile: "live/" + p.path,
+			ToFile:   "merged/" + p.path,
+			Context:  diffContextLines,
+		})
+		if err != nil {
+			return false, err
+		}
+		if text == "" {
+			continue
+		}
+
+		changed = true
+		fmt.Fprint(w, colorizeDiff(text))
+	}
+	return changed, nil
+}
+
+// functionView returns the fields of fn which can be changed by an apply.
+func functionView(fn *openfunction.Function) interface{} {
+	if fn == nil {
+		return nil
+	}
+	return map[string]interface{}{
+		"apiVersion": openfunction.GroupVersion.String(),
+		"kind":       "Function",
+		"metadata": metav1.ObjectMeta{
+			Name:        fn.Name,
+			Namespace:   fn.Namespace,
+			Labels:      fn.Labels,
+			Annotations: fn.Annotations,
+		},
+		"spec": fn.Spec,
+	}
+}
+
+// builderView returns the spec of the builder the controller creates for fn, or nil if fn isn't built.
+func builderView(fn *openfunction.Function) interface{} {
+	if fn == nil || fn.Spec.Build == nil {
+		return nil
+	}
+
+	spec := openfunction.BuilderSpec{
+		Params:             fn.Spec.Build.Params,
+		Env:                fn.Spec.Build.Env,
+		Builder:            fn.Spec.Build.Builder,
+		BuilderCredentials: fn.Spec.Build.BuilderCredentials,
+		Image:              fn.Spec.Image,
+		ImageCredentials:   fn.Spec.ImageCredentials,
+		Port:               fn.Spec.Port,
+		Shipwright:         fn.Spec.Build.Shipwright,
+		Dockerfile:         fn.Spec.Build.Dockerfile,
+		Timeout:            fn.Spec.Build.Timeout,
+		SrcRepo:            fn.Spec.Build.SrcRepo,
+	}
+	return map[string]interface{}{
+		"apiVersion": openfunction.GroupVersion.String(),
+		"kind":       "Builder",
+		"spec":       spec,
+	}
+}
+
+// servingView returns the spec of the serving the controller creates for fn, or nil if fn isn't served.
+func servingView(fn *openfunction.Function) interface{} {
+	if fn == nil || fn.Spec.Serving == nil {
+		return nil
+	}
+
+	spec := openfunction.ServingSpec{
+		Version:          fn.Spec.Version,
+		Image:            fn.Spec.Image,
+		ImageCredentials: fn.Spec.ImageCredentials,
+		Port:             fn.Spec.Port,
+		Timeout:          fn.Spec.Serving.Timeout,
+		Runtime:          fn.Spec.Serving.Runtime,
+		ScaleOptions:     fn.Spec.Serving.ScaleOptions,
+		Bindings:         fn.Spec.Serving.Bindings,
+		Pubsub:           fn.Spec.Serving.Pubsub,
+		Inputs:           fn.Spec.Serving.Inputs,
+		Outputs:          fn.Spec.Serving.Outputs,
+		Params:           fn.Spec.Serving.Params,
+		Labels:           fn.Spec.Serving.Labels,
+		Annotations:      fn.Spec.Serving.Annotations,
+		Template:         fn.Spec.Serving.Template,
+		Triggers:         fn.Spec.Serving.Triggers,
+	}
+	return map[string]interface{}{
+		"apiVersion": openfunction.GroupVersion.String(),
+		"kind":       "Serving",
+		"metadata": metav1.ObjectMeta{
+			Annotations: fn.Annotations,
+		},
+		"spec": spec,
+	}
+}
+
+// toYAML returns obj in YAML without the empty fields, or an empty string if obj is nil.
+func toYAML(obj interface{}) (string, error) {
+	if obj == nil {
+		return "", nil
+	}
+	m, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&obj)
+	if err != nil {
+		return "", err
+	}
+	unstructured.RemoveNestedField(m, "metadata", "creationTimestamp")
+	if meta, ok := m["metadata"].(map[string]interface{}); ok && len(meta) == 0 {
+		delete(m, "metadata")
+	}
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func colorizeDiff(text string) string {
+	lines := strings.SplitAfter(text, "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			lines[i] = colorizeLine(line, diffHeader)
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = colorizeLine(line, diffHunk)
+		case strings.HasPrefix(line, "-"):
+			lines[i] = colorizeLine(line, diffRemoved)
+		case strings.HasPrefix(line, "+"):
+			lines[i] = colorizeLine(line, diffAdded)
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+// colorizeLine colorizes line without its line break so that the escape codes don't span lines.
+func colorizeLine(line string, colorize func(a ...interface{}) string) string {
+	if strings.HasSuffix(line, "\n") {
+		return colorize(strings.TrimSuffix(line, "\n")) + "\n"
+	}
+	return colorize(line)
+}
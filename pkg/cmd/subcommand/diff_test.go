@@ -0,0 +1,106 @@
+package subcommand
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	openfunction "github.com/openfunction/apis/core/v1beta1"
+)
+
+func TestDiffFunction(t *testing.T) {
+	color.NoColor = true
+
+	newFunction := func(image string, serving bool) *openfunction.Function {
+		builder := "openfunction/builder-go:latest"
+		fn := &openfunction.Function{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default"},
+			Spec: openfunction.FunctionSpec{
+				Image: image,
+				Build: &openfunction.BuildImpl{
+					Builder: &builder,
+					SrcRepo: &openfunction.GitRepo{Url: "https://github.com/OpenFunction/samples.git"},
+				},
+			},
+		}
+		if serving {
+			fn.Spec.Serving = &openfunction.ServingImpl{}
+		}
+		return fn
+	}
+
+	tests := []struct {
+		name            string
+		live, merged    *openfunction.Function
+		wantChanged     bool
+		wantContains    []string
+		wantNotContains []string
+	}{
+		{
+			name:        "unchanged",
+			live:        newFunction("demo:v1", true),
+			merged:      newFunction("demo:v1", true),
+			wantChanged: false,
+		},
+		{
+			name:        "image changed",
+			live:        newFunction("demo:v1", true),
+			merged:      newFunction("demo:v2", true),
+			wantChanged: true,
+			wantContains: []string{
+				"--- live/function.core.openfunction.io/demo\n",
+				"+++ merged/function.core.openfunction.io/demo/builder\n",
+				"+++ merged/function.core.openfunction.io/demo/serving\n",
+				"-  image: demo:v1\n",
+				"+  image: demo:v2\n",
+			},
+			wantNotContains: []string{"creationTimestamp"},
+		},
+		{
+			name:        "serving removed",
+			live:        newFunction("demo:v1", true),
+			merged:      newFunction("demo:v1", false),
+			wantChanged: true,
+			wantContains: []string{
+				"-kind: Serving\n",
+			},
+			wantNotContains: []string{"demo/builder"},
+		},
+		{
+			name:        "created",
+			live:        nil,
+			merged:      newFunction("demo:v1", false),
+			wantChanged: true,
+			wantContains: []string{
+				"+kind: Function\n",
+				"+kind: Builder\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			changed, err := diffFunction(out, tt.live, tt.merged)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed != tt.wantChanged {
+				t.Errorf("changed = %v, want %v\n%s", changed, tt.wantChanged, out)
+			}
+			for _, s := range tt.wantContains {
+				if !strings.Contains(out.String(), s) {
+					t.Errorf("diff doesn't contain %q\n%s", s, out)
+				}
+			}
+			for _, s := range tt.wantNotContains {
+				if strings.Contains(out.String(), s) {
+					t.Errorf("diff contains %q\n%s", s, out)
+				}
+			}
+		})
+	}
+}
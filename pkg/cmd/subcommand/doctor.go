@@ -0,0 +1,125 @@
+package subcommand
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/OpenFunction/cli/pkg/cmd/util"
+	"github.com/OpenFunction/cli/pkg/cmd/util/client"
+	"github.com/OpenFunction/cli/pkg/components/doctor"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	k8s "k8s.io/client-go/kubernetes"
+)
+
+const (
+	doctorOutputTable = "table"
+	doctorOutputJSON  = "json"
+)
+
+// Doctor is the commandline for 'doctor' sub command
+type Doctor struct {
+	genericclioptions.IOStreams
+
+	OpenFunctionVersion string
+	Output              string
+	Timeout             time.Duration
+}
+
+// NewDoctor returns an initialized Doctor instance
+func NewDoctor(ioStreams genericclioptions.IOStreams) *Doctor {
+	return &Doctor{
+		IOStreams: ioStreams,
+	}
+}
+
+func NewCmdDoctor(cf *genericclioptions.ConfigFlags, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	var cl k8s.Interface
+
+	d := NewDoctor(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:                   "doctor [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Check whether the cluster is ready for OpenFunction and its dependencies.",
+		Long: `This command will run preflight and health checks against the current cluster and print a report,
+it exits with a non-zero status if any check fails.`,
+		Example: `
+# Check the current cluster
+ofn doctor
+
+# Check the current cluster in CI
+ofn doctor -o json
+`,
+		PreRunE: func(cmd *cobra.Command, args []string) (err error) {
+			_, cl, err = client.NewKubeConfigClient(cf)
+			if err != nil {
+				return err
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(d.ValidateArgs())
+			util.CheckErr(d.RunDoctor(cl))
+		},
+	}
+
+	cmd.Flags().StringVar(&d.OpenFunctionVersion, "version", "", "The version of OpenFunction to be installed, defaults to the default version.")
+	cmd.Flags().StringVarP(&d.Output, "output", "o", doctorOutputTable, "Output format, one of \"table\", \"json\".")
+	cmd.Flags().DurationVar(&d.Timeout, "timeout", time.Minute, "Set timeout time. Default is 1 minute.")
+	return cmd
+}
+
+func (d *Doctor) ValidateArgs() error {
+	if d.Output != doctorOutputTable && d.Output != doctorOutputJSON {
+		return errors.Errorf("invalid output format %s, one of \"table\", \"json\" is expected", d.Output)
+	}
+	return nil
+}
+
+func (d *Doctor) RunDoctor(cl k8s.Interface) error {
+	ctx, done := context.WithTimeout(
+		context.Background(),
+		d.Timeout,
+	)
+	defer done()
+
+	results := doctor.NewDoctor(cl, d.OpenFunctionVersion).Run(ctx)
+
+	switch d.Output {
+	case doctorOutputJSON:
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(d.Out, string(data))
+	default:
+		t := table.NewWriter()
+		t.SetOutputMirror(d.Out)
+		t.AppendHeader(table.Row{"Check", "Status", "Message"})
+		for _, r := range results {
+			t.AppendRow(table.Row{r.Check, colorizeStatus(r.Status), r.Message})
+		}
+		t.Render()
+	}
+
+	if n := doctor.Failed(results); n > 0 {
+		return errors.Errorf("%d check(s) failed", n)
+	}
+	return nil
+}
+
+func colorizeStatus(s doctor.Status) string {
+	switch s {
+	case doctor.Pass:
+		return util.Green(string(s))
+	case doctor.Warn:
+		return util.Yellow(string(s))
+	default:
+		return util.Red(string(s))
+	}
+}
@@ -0,0 +1,328 @@
+package subcommand
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/OpenFunction/cli/pkg/cmd/util"
+	cc "github.com/OpenFunction/cli/pkg/cmd/util/client"
+	client "github.com/openfunction/pkg/client/clientset/versioned"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/yaml"
+
+	openfunction "github.com/openfunction/apis/core/v1beta1"
+)
+
+const (
+	editHeader = `# Please edit the function below. Lines beginning with a '#' will be ignored,
+# and an empty file will abort the edit. If an error occurs while saving this file will be
+# reopened with the relevant failures.
+#
+`
+	editCancelled = "Edit cancelled, no changes made."
+)
+
+// Edit is the commandline for 'edit' sub command
+type Edit struct {
+	genericclioptions.IOStreams
+
+	Name string
+
+	namespace string
+	// editor opens the file at path and returns once it's closed.
+	editor func(path string) error
+}
+
+const (
+	editExample = `
+# Edit the function sample with the editor set in KUBE_EDITOR or EDITOR
+ofn edit function sample
+
+# Edit the function sample with nano
+EDITOR=nano ofn edit fn sample
+`
+)
+
+// NewEdit returns an initialized Edit instance
+func NewEdit(ioStreams genericclioptions.IOStreams) *Edit {
+	e := &Edit{
+		IOStreams: ioStreams,
+	}
+	e.editor = e.launchEditor
+	return e
+}
+
+func NewCmdEdit(cf *genericclioptions.ConfigFlags, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "edit",
+		DisableFlagsInUseLine: true,
+		Short:                 "Edit a resource on the server",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(newCmdEditFunction(cf, ioStreams))
+	return cmd
+}
+
+func newCmdEditFunction(cf *genericclioptions.ConfigFlags, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	var fc client.Interface
+
+	e := NewEdit(ioStreams)
+	cmd := &cobra.Command{
+		Use:                   "function NAME",
+		Aliases:               []string{"functions", "fn"},
+		DisableFlagsInUseLine: true,
+		Short:                 "Edit a function with the default editor",
+		Long: `
+Edit a function with the editor set in the KUBE_EDITOR or the EDITOR environment variable, or vi.
+
+The function is validated before it's sent, the file is reopened with the failures as comments
+if it's invalid or rejected by the server. Saving an empty or unchanged file cancels the edit.
+The function is updated with the resourceVersion it had when the editor was opened,
+so the edit fails rather than overwrites the changes made in the meantime.
+`,
+		Example: editExample,
+		Args:    cobra.ExactArgs(1),
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			config, err := cf.ToRESTConfig()
+			if err != nil {
+				return err
+			}
+			cc.SetConfigDefaults(config)
+			fc, err = client.NewForConfig(config)
+			if err != nil {
+				return err
+			}
+
+			e.namespace, _, err = cf.ToRawKubeConfigLoader().Namespace()
+			return err
+		},
+
+		Run: func(cmd *cobra.Command, args []string) {
+			e.Name = args[0]
+			util.CheckErr(e.Run(fc))
+		},
+	}
+
+	return cmd
+}
+
+func (e *Edit) Run(fc client.Interface) error {
+	ctx := context.Background()
+	original, err := fc.CoreV1beta1().Functions(e.namespace).Get(ctx, e.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	originalYAML, err := editableYAML(original)
+	if err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile("", "ofn-edit-*.yaml")
+	if err != nil {
+		return err
+	}
+	path := f.Name()
+	f.Close()
+	keep := false
+	defer func() {
+		if !keep {
+			os.Remove(path)
+		}
+	}()
+
+	var (
+		content = editHeader + originalYAML
+		invalid string
+	)
+	for {
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			return err
+		}
+		if err := e.editor(path); err != nil {
+			return errors.Wrap(err, "failed to launch the editor")
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		edited := stripComments(string(data))
+		if strings.TrimSpace(edited) == "" || edited == originalYAML {
+			fmt.Fprintln(e.Out, editCancelled)
+			return nil
+		}
+		if edited == invalid {
+			keep = true
+			return errors.Errorf("edit cancelled, no valid changes were saved, a copy of your changes has been stored to %s", path)
+		}
+
+		fn, errs := parseEditedFunction(original, edited)
+		if len(errs) == 0 {
+			// The function is updated only if it hasn't changed since it was fetched.
+			fn.ResourceVersion = original.ResourceVersion
+			_, err = fc.CoreV1beta1().Functions(fn.Namespace).Update(ctx, fn, metav1.UpdateOptions{})
+			if err == nil {
+				fmt.Fprintf(e.Out, "function.%s/%s edited\n", openfunction.GroupVersion.Group, fn.Name)
+				return nil
+			}
+			if !k8serrors.IsInvalid(err) && !k8serrors.IsBadRequest(err) {
+				keep = true
+				return errors.Wrapf(err, "failed to update function %s/%s, a copy of your changes has been stored to %s", fn.Namespace, fn.Name, path)
+			}
+			errs = []string{err.Error()}
+		}
+
+		invalid = edited
+		content = editErrorHeader(original.Name, errs) + edited
+	}
+}
+
+// launchEditor opens path with the editor set in KUBE_EDITOR or EDITOR, or vi.
+func (e *Edit) launchEditor(path string) error {
+	editor := "vi"
+	for _, env := range []string{"KUBE_EDITOR", "EDITOR"} {
+		if v := strings.TrimSpace(os.Getenv(env)); v != "" {
+			editor = v
+			break
+		}
+	}
+
+	// The editor is run by the shell so it can have arguments, e.g. "code --wait".
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", path)
+	cmd.Stdin = e.In
+	cmd.Stdout = e.Out
+	cmd.Stderr = e.ErrOut
+	return cmd.Run()
+}
+
+// editableYAML returns the YAML of fn without its status and managed fields.
+func editableYAML(fn *openfunction.Function) (string, error) {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(fn)
+	if err != nil {
+		return "", err
+	}
+	obj["apiVersion"] = openfunction.GroupVersion.String()
+	obj["kind"] = "Function"
+	delete(obj, "status")
+	unstructured.RemoveNestedField(obj, "metadata", "managedFields")
+
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// parseEditedFunction decodes the edited YAML strictly and validates it against original,
+// the failures are returned as messages.
+func parseEditedFunction(original *openfunction.Function, edited string) (*openfunction.Function, []string) {
+	fn := &openfunction.Function{}
+	if err := yaml.UnmarshalStrict([]byte(edited), fn); err != nil {
+		return nil, []string{err.Error()}
+	}
+
+	var msgs []string
+	for _, err := range validateFunction(original, fn) {
+		msgs = append(msgs, err.Error())
+	}
+	return fn, msgs
+}
+
+func validateFunction(original *openfunction.Function, fn *openfunction.Function) field.ErrorList {
+	var errs field.ErrorList
+	if fn.APIVersion != openfunction.GroupVersion.String() {
+		errs = append(errs, field.Invalid(field.NewPath("apiVersion"), fn.APIVersion, "must be "+openfunction.GroupVersion.String()))
+	}
+	if fn.Kind != "Function" {
+		errs = append(errs, field.Invalid(field.NewPath("kind"), fn.Kind, "must be Function"))
+	}
+	if fn.Name != original.Name {
+		errs = append(errs, field.Invalid(field.NewPath("metadata", "name"), fn.Name, "the name of the function can't be changed"))
+	}
+	if fn.Namespace != original.Namespace {
+		errs = append(errs, field.Invalid(field.NewPath("metadata", "namespace"), fn.Namespace, "the namespace of the function can't be changed"))
+	}
+
+	spec := field.NewPath("spec")
+	if fn.Spec.Image == "" {
+		errs = append(errs, field.Required(spec.Child("image"), ""))
+	}
+	if build := fn.Spec.Build; build != nil && (build.SrcRepo == nil || build.SrcRepo.Url == "") {
+		errs = append(errs, field.Required(spec.Child("build", "srcRepo", "url"), ""))
+	}
+
+	serving := fn.Spec.Serving
+	if serving == nil {
+		return errs
+	}
+	path := spec.Child("serving")
+	if serving.Runtime != openfunction.Knative && serving.Runtime != openfunction.Async {
+		errs = append(errs, field.NotSupported(path.Child("runtime"), serving.Runtime, []string{string(openfunction.Knative), string(openfunction.Async)}))
+	}
+	for i, trigger := range serving.Triggers {
+		if trigger.Type == "" {
+			errs = append(errs, field.Required(path.Child("triggers").Index(i).Child("type"), ""))
+		}
+		if kind := trigger.TargetKind; kind != nil && *kind != openfunction.ScaledObject && *kind != openfunction.ScaledJob {
+			errs = append(errs, field.NotSupported(path.Child("triggers").Index(i).Child("targetKind"), *kind, []string{string(openfunction.ScaledObject), string(openfunction.ScaledJob)}))
+		}
+	}
+	for _, name := range []string{"inputs", "outputs"} {
+		ios := serving.Inputs
+		if name == "outputs" {
+			ios = serving.Outputs
+		}
+		for i, io := range ios {
+			if io == nil || io.Name == "" {
+				errs = append(errs, field.Required(path.Child(name).Index(i).Child("name"), ""))
+			}
+			if io == nil || io.Component == "" {
+				errs = append(errs, field.Required(path.Child(name).Index(i).Child("component"), ""))
+			}
+		}
+	}
+	return errs
+}
+
+// stripComments removes the lines beginning with a '#' from the edited file.
+func stripComments(content string) string {
+	var lines []string
+	for _, line := range strings.SplitAfter(content, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "#") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+func editErrorHeader(name string, errs []string) string {
+	var b strings.Builder
+	b.WriteString(editHeader)
+	fmt.Fprintf(&b, "# function.%s %q was not valid:\n", openfunction.GroupVersion.Group, name)
+	for _, err := range errs {
+		for i, line := range strings.Split(err, "\n") {
+			if i == 0 {
+				fmt.Fprintf(&b, "# * %s\n", line)
+			} else {
+				fmt.Fprintf(&b, "#   %s\n", line)
+			}
+		}
+	}
+	b.WriteString("#\n")
+	return b.String()
+}
@@ -0,0 +1,144 @@
+package subcommand
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	openfunction "github.com/openfunction/apis/core/v1beta1"
+	fakeclient "github.com/openfunction/pkg/client/clientset/versioned/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestEditFunction(t *testing.T) {
+	// The copies of the invalid changes are kept in the temporary directory.
+	tmp := os.Getenv("TMPDIR")
+	os.Setenv("TMPDIR", t.TempDir())
+	defer os.Setenv("TMPDIR", tmp)
+
+	replace := func(old string, new string) func(string) string {
+		return func(s string) string {
+			return strings.Replace(s, old, new, 1)
+		}
+	}
+	same := func(s string) string { return s }
+
+	tests := []struct {
+		name      string
+		edits     []func(string) string
+		wantImage string
+		wantOut   string
+		wantErr   string
+		// wantShown is expected in the file the last time it's opened.
+		wantShown string
+	}{
+		{
+			name: "change image",
+			edits: []func(string) string{func(s string) string {
+				s = strings.Replace(s, `resourceVersion: "1"`, `resourceVersion: "5"`, 1)
+				return strings.Replace(s, "demo:v1", "demo:v2", 1)
+			}},
+			wantImage: "demo:v2",
+			wantOut:   "function.core.openfunction.io/sample edited\n",
+			wantShown: "# Please edit the function below.",
+		},
+		{
+			name:      "unchanged",
+			edits:     []func(string) string{same},
+			wantImage: "demo:v1",
+			wantOut:   editCancelled + "\n",
+		},
+		{
+			name:      "empty",
+			edits:     []func(string) string{func(string) string { return "# nothing\n" }},
+			wantImage: "demo:v1",
+			wantOut:   editCancelled + "\n",
+		},
+		{
+			name:      "unknown field fixed",
+			edits:     []func(string) string{replace("image: demo:v1", "imag: demo:v2"), replace("imag:", "image:")},
+			wantImage: "demo:v2",
+			wantOut:   "function.core.openfunction.io/sample edited\n",
+			wantShown: `unknown field "imag"`,
+		},
+		{
+			name:      "invalid runtime saved twice",
+			edits:     []func(string) string{replace("runtime: knative", "runtime: openfuncasync"), same},
+			wantImage: "demo:v1",
+			wantErr:   "edit cancelled, no valid changes were saved",
+			wantShown: `# * spec.serving.runtime: Unsupported value: "openfuncasync": supported values: "knative", "async"`,
+		},
+		{
+			name:      "renamed",
+			edits:     []func(string) string{replace("name: sample", "name: renamed"), same},
+			wantImage: "demo:v1",
+			wantErr:   "edit cancelled, no valid changes were saved",
+			wantShown: "# * metadata.name: Invalid value: \"renamed\": the name of the function can't be changed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc := fakeclient.NewSimpleClientset(&openfunction.Function{
+				ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default", ResourceVersion: "1"},
+				Spec: openfunction.FunctionSpec{
+					Image:   "demo:v1",
+					Serving: &openfunction.ServingImpl{Runtime: openfunction.Knative},
+				},
+			})
+
+			out := &bytes.Buffer{}
+			e := NewEdit(genericclioptions.IOStreams{Out: out})
+			e.Name = "sample"
+			e.namespace = "default"
+			var shown string
+			e.editor = func(path string) error {
+				if len(tt.edits) == 0 {
+					t.Fatal("the editor is opened too many times")
+				}
+				data, err := ioutil.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				shown = string(data)
+				edit := tt.edits[0]
+				tt.edits = tt.edits[1:]
+				return ioutil.WriteFile(path, []byte(edit(shown)), 0600)
+			}
+
+			err := e.Run(fc)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+			if out.String() != tt.wantOut {
+				t.Errorf("output = %q, want %q", out, tt.wantOut)
+			}
+			if !strings.Contains(shown, tt.wantShown) {
+				t.Errorf("the file doesn't contain %q:\n%s", tt.wantShown, shown)
+			}
+
+			for _, action := range fc.Actions() {
+				if update, ok := action.(k8stesting.UpdateAction); ok {
+					if rv := update.GetObject().(*openfunction.Function).ResourceVersion; rv != "1" {
+						t.Errorf("updated with resourceVersion %s, want the original one", rv)
+					}
+				}
+			}
+			fn, err := fc.CoreV1beta1().Functions("default").Get(context.Background(), "sample", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fn.Spec.Image != tt.wantImage {
+				t.Errorf("image = %s, want %s", fn.Spec.Image, tt.wantImage)
+			}
+		})
+	}
+}
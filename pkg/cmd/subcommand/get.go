@@ -3,15 +3,20 @@ package subcommand
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/OpenFunction/cli/pkg/cmd/util"
 	cc "github.com/OpenFunction/cli/pkg/cmd/util/client"
 	client "github.com/openfunction/pkg/client/clientset/versioned"
 	scheme "github.com/openfunction/pkg/client/clientset/versioned/scheme"
+	swv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	swclient "github.com/shipwright-io/build/pkg/client/clientset/versioned"
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	k8s "k8s.io/client-go/kubernetes"
 
 	"github.com/openfunction/apis/core/v1beta1"
 	openfunction "github.com/openfunction/apis/core/v1beta1"
@@ -28,6 +33,8 @@ type Get struct {
 
 	namespace        string
 	enforceNamespace bool
+	clientSet        k8s.Interface
+	swClient         swclient.Interface
 }
 
 const (
@@ -43,6 +50,15 @@ ofn get sample -o yaml
 
 # Return only the state ofn build
 ofn get sample --template={{.status.build.state}}
+
+# List the functions with the duration, the revision and the failure of their builds
+ofn get -o wide
+
+# List the functions sorted by creation time, with their labels
+ofn get --sort-by=.metadata.creationTimestamp --show-labels
+
+# Watch the functions and print their changes
+ofn get --watch
 `
 	getLong = `
 Prints a table of the most important information.
@@ -50,15 +66,10 @@ Prints a table of the most important information.
 )
 
 var (
-	fnColumnLabels = []string{
-		"NAME",
-		"NAMESPACE",
-		"BUILDSTATE",
-		"SERVINGSTATE",
-		"BUILDER",
-		"SERVING",
-		"AGE",
-	}
+	fnColumns = append(
+		util.Columns("NAME", "NAMESPACE", "BUILDSTATE", "SERVINGSTATE", "BUILDER", "SERVING", "RUNTIME", "IMAGE", "VERSION", "READY", "URL", "AGE"),
+		util.WideColumns("BUILDDURATION", "REVISION", "REASON")...,
+	)
 )
 
 // NewGet returns an initialized Get instance
@@ -82,12 +93,23 @@ func NewCmdGet(cf *genericclioptions.ConfigFlags, ioStreams genericclioptions.IO
 		Example:               getExample,
 
 		PreRunE: func(cmd *cobra.Command, args []string) error {
-			config, err := cf.ToRESTConfig()
+			config, clientSet, err := cc.NewKubeConfigClient(cf)
 			if err != nil {
-				panic(err)
+				return err
+			}
+			g.clientSet = clientSet
+
+			if err := cc.SetConfigDefaults(config); err != nil {
+				return err
+			}
+			fc, err = client.NewForConfig(config)
+			if err != nil {
+				return err
+			}
+			g.swClient, err = swclient.NewForConfig(config)
+			if err != nil {
+				return err
 			}
-			cc.SetConfigDefaults(config)
-			fc = client.NewForConfigOrDie(config)
 
 			g.namespace, g.enforceNamespace, err = cf.ToRawKubeConfigLoader().Namespace()
 			return err
@@ -100,6 +122,7 @@ func NewCmdGet(cf *genericclioptions.ConfigFlags, ioStreams genericclioptions.IO
 	}
 
 	g.Printer.AddFlags(cmd)
+	g.Printer.AddTableFlags(cmd)
 	g.listFlag.addListFlag(cmd)
 
 	cmd.AddCommand(newCmdGetBuilder(cf, ioStreams))
@@ -110,9 +133,13 @@ func NewCmdGet(cf *genericclioptions.ConfigFlags, ioStreams genericclioptions.IO
 func (g *Get) Complete(cmd *cobra.Command, args []string) error {
 	if len(args) != 0 {
 		g.Name = args[0]
-		g.Printer.SetForceDefail()
+		// The changes of the function are printed as a table when watching it.
+		if !g.Watch {
+			g.Printer.SetForceDefail()
+		}
 	}
 
+	g.NamespaceIfScoped = true
 	if !g.enforceNamespace {
 		g.NamespaceIfScoped = !g.AllNamespaces
 	}
@@ -127,9 +154,31 @@ func (g *Get) Run(fc client.Interface, cmd *cobra.Command, args []string) error
 		err  error
 	)
 
+	namespace := g.namespace
+	if !g.NamespaceIfScoped && g.Name == "" {
+		namespace = metav1.NamespaceAll
+	}
+	table := &fnTable{
+		functionClient: fc,
+		clientSet:      g.clientSet,
+		swClient:       g.swClient,
+		namespace:      namespace,
+		wide:           util.IsWide(g.Printer),
+	}
+
+	if g.Watch {
+		lw := g.listWatch(fc.CoreV1beta1().RESTClient(), "functions", g.namespace, g.NamespaceIfScoped || g.Name != "", g.Name, func() runtime.Object {
+			return &v1beta1.FunctionList{}
+		})
+		return g.runWatch(g.Out, g.Printer, fnColumns, table.row, lw)
+	}
+
 	ctx := context.Background()
 	if g.Name != "" {
 		obj, err = fc.CoreV1beta1().Functions(g.namespace).Get(ctx, g.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
 		objs = []runtime.Object{obj}
 	} else {
 		opt := g.listFlag.ToOptions()
@@ -150,21 +199,20 @@ func (g *Get) Run(fc client.Interface, cmd *cobra.Command, args []string) error
 		for i := range result.Items {
 			objs = append(objs, &result.Items[i])
 		}
-
-		if util.IsToTable(g.Printer) {
-			obj, err = util.ToTable(fnRow, objs...)
-			if err != nil {
-				return err
-			}
-			objs = []runtime.Object{obj}
-		}
 	}
 
-	if err != nil {
+	if err = util.SortObjects(objs, g.Printer.SortBy); err != nil {
 		return err
 	}
+	if util.IsToTable(g.Printer) {
+		obj, err = util.ToTable(fnColumns, table.row, objs...)
+		if err != nil {
+			return err
+		}
+		objs = []runtime.Object{obj}
+	}
 
-	printer, err := g.Printer.ToPrinterWitchColumn(fnColumnLabels)
+	printer, err := g.Printer.ToPrinterWithTable(printers.PrintOptions{})
 	if err != nil {
 		return err
 	}
@@ -177,15 +225,95 @@ func (g *Get) Run(fc client.Interface, cmd *cobra.Command, args []string) error
 	return nil
 }
 
-func fnRow(obj interface{}) (metav1.TableRow, error) {
+// fnTable looks up the objects created for the functions, which some of the columns are made of.
+// The objects are listed once for all the functions, then again if they are older than fnTableRefresh.
+type fnTable struct {
+	functionClient client.Interface
+	clientSet      k8s.Interface
+	swClient       swclient.Interface
+	namespace      string
+	wide           bool
+
+	loaded    time.Time
+	replicas  map[string]string
+	builders  map[string]*openfunction.Builder
+	buildRuns map[string]*swv1alpha1.BuildRun
+}
+
+const fnTableRefresh = 5 * time.Second
+
+// load lists the workloads of the servings, and the builders and their build runs with -o wide.
+// The columns made of the objects which can't be listed are left empty, e.g. if listing them is forbidden.
+func (t *fnTable) load() {
+	if time.Since(t.loaded) < fnTableRefresh {
+		return
+	}
+	t.loaded = time.Now()
+	ctx := context.Background()
+
+	// The workloads of the servings, including the Deployments of the revisions of the Knative services, have the label of the serving.
+	type counts struct{ ready, desired int32 }
+	replicas := map[string]*counts{}
+	add := func(namespace string, labels map[string]string, ready int32, desired *int32) {
+		key := namespace + "/" + labels[servingLabel]
+		if replicas[key] == nil {
+			replicas[key] = &counts{}
+		}
+		replicas[key].ready += ready
+		if desired != nil {
+			replicas[key].desired += *desired
+		}
+	}
+	opts := metav1.ListOptions{LabelSelector: servingLabel}
+	if t.clientSet != nil {
+		if deploys, err := t.clientSet.AppsV1().Deployments(t.namespace).List(ctx, opts); err == nil {
+			for _, deploy := range deploys.Items {
+				add(deploy.Namespace, deploy.Labels, deploy.Status.ReadyReplicas, deploy.Spec.Replicas)
+			}
+		}
+		if statefulSets, err := t.clientSet.AppsV1().StatefulSets(t.namespace).List(ctx, opts); err == nil {
+			for _, sts := range statefulSets.Items {
+				add(sts.Namespace, sts.Labels, sts.Status.ReadyReplicas, sts.Spec.Replicas)
+			}
+		}
+	}
+	t.replicas = map[string]string{}
+	for key, c := range replicas {
+		t.replicas[key] = fmt.Sprintf("%d/%d", c.ready, c.desired)
+	}
+
+	if !t.wide {
+		return
+	}
+	t.builders = map[string]*openfunction.Builder{}
+	if builders, err := t.functionClient.CoreV1beta1().Builders(t.namespace).List(ctx, metav1.ListOptions{}); err == nil {
+		for i := range builders.Items {
+			builder := &builders.Items[i]
+			t.builders[builder.Namespace+"/"+builder.Name] = builder
+		}
+	}
+	t.buildRuns = map[string]*swv1alpha1.BuildRun{}
+	if t.swClient == nil {
+		return
+	}
+	if buildRuns, err := t.swClient.ShipwrightV1alpha1().BuildRuns(t.namespace).List(ctx, metav1.ListOptions{}); err == nil {
+		for i := range buildRuns.Items {
+			buildRun := &buildRuns.Items[i]
+			t.buildRuns[buildRun.Namespace+"/"+buildRun.Name] = buildRun
+		}
+	}
+}
+
+func (t *fnTable) row(obj interface{}) (metav1.TableRow, error) {
 	fn, ok := obj.(*openfunction.Function)
 	if !ok {
-		return metav1.TableRow{}, fmt.Errorf("interface conversion: interface {} is not *v1alpha1.Function")
+		return metav1.TableRow{}, fmt.Errorf("interface conversion: interface {} is not *v1beta1.Function")
 	}
+	t.load()
 
 	name := fn.Name
 	namespace := fn.Namespace
-	var builder, buildState, serving, servingState string
+	var builder, buildState, serving, servingState, fnRuntime, version, ready string
 	if fn.Status.Build != nil {
 		buildState = fn.Status.Build.State
 		builder = fn.Status.Build.ResourceRef
@@ -194,6 +322,29 @@ func fnRow(obj interface{}) (metav1.TableRow, error) {
 		servingState = fn.Status.Serving.State
 		serving = fn.Status.Serving.ResourceRef
 	}
+	if fn.Spec.Serving != nil {
+		fnRuntime = string(fn.Spec.Serving.Runtime)
+	}
+	if fn.Spec.Version != nil {
+		version = *fn.Spec.Version
+	}
+	if serving != "" {
+		ready = t.replicas[namespace+"/"+serving]
+	}
+
+	var buildDuration, revision, reason string
+	if fn.Spec.Build != nil && fn.Spec.Build.SrcRepo != nil && fn.Spec.Build.SrcRepo.Revision != nil {
+		revision = *fn.Spec.Build.SrcRepo.Revision
+	}
+	if b := t.builders[namespace+"/"+builder]; builder != "" && b != nil {
+		if containsState(buildFailedStates, b.Status.State) {
+			reason = b.Status.Reason
+		}
+		_, buildRunName := getBuilderResourceRef(b.Status.ResourceRef)
+		if buildRun := t.buildRuns[namespace+"/"+buildRunName]; buildRun != nil {
+			buildDuration = buildRunDuration(buildRun)
+		}
+	}
 
 	age := util.TranslateTimestampSince(fn.CreationTimestamp)
 	row := metav1.TableRow{
@@ -207,7 +358,15 @@ func fnRow(obj interface{}) (metav1.TableRow, error) {
 		servingState,
 		builder,
 		serving,
+		fnRuntime,
+		fn.Spec.Image,
+		version,
+		ready,
+		fn.Status.URL,
 		age,
+		buildDuration,
+		revision,
+		reason,
 	)
 	return row, nil
 }
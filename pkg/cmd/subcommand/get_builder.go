@@ -12,6 +12,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
 
 	"github.com/openfunction/apis/core/v1beta1"
 	openfunction "github.com/openfunction/apis/core/v1beta1"
@@ -39,6 +40,9 @@ ofn get builder sample-builder-m5sbv -o json
 
 # Get builder in YAML output format
 ofn get builder sample-builder-m5sbv -o yaml
+
+# Watch the builders and print the watch events
+ofn get builder --watch --output-watch-events
 `
 
 	getBuildLong = `
@@ -47,15 +51,7 @@ Prints a table ofn the most important information.
 )
 
 var (
-	builderColumnLabels = []string{
-		"NAME",
-		"NAMESPACE",
-		"BUILD",
-		"BUILDRUN",
-		"PHASE",
-		"STATE",
-		"AGE",
-	}
+	builderColumns = util.Columns("NAME", "NAMESPACE", "BUILD", "BUILDRUN", "PHASE", "STATE", "AGE")
 )
 
 // newGetBuiler returns an initialized getBuilder instance
@@ -96,6 +92,7 @@ func newCmdGetBuilder(cf *genericclioptions.ConfigFlags, ioStreams genericcliopt
 	}
 
 	g.Printer.AddFlags(cmd)
+	g.Printer.AddTableFlags(cmd)
 	g.listFlag.addListFlag(cmd)
 
 	return cmd
@@ -104,7 +101,10 @@ func newCmdGetBuilder(cf *genericclioptions.ConfigFlags, ioStreams genericcliopt
 func (g *getBuilder) Complete(cmd *cobra.Command, args []string) error {
 	if len(args) != 0 {
 		g.Name = args[0]
-		g.Printer.SetForceDefail()
+		// The changes of the builder are printed as a table when watching it.
+		if !g.Watch {
+			g.Printer.SetForceDefail()
+		}
 	}
 
 	g.NamespaceIfScoped = true
@@ -122,9 +122,19 @@ func (g *getBuilder) Run(fc client.Interface, cmd *cobra.Command, args []string)
 		err  error
 	)
 
+	if g.Watch {
+		lw := g.listWatch(fc.CoreV1beta1().RESTClient(), "builders", g.namespace, g.NamespaceIfScoped || g.Name != "", g.Name, func() runtime.Object {
+			return &v1beta1.BuilderList{}
+		})
+		return g.runWatch(g.Out, g.Printer, builderColumns, builderRow, lw)
+	}
+
 	ctx := context.Background()
 	if g.Name != "" {
 		obj, err = fc.CoreV1beta1().Builders(g.namespace).Get(ctx, g.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
 		objs = []runtime.Object{obj}
 	} else {
 		opt := g.listFlag.ToOptions()
@@ -143,20 +153,20 @@ func (g *getBuilder) Run(fc client.Interface, cmd *cobra.Command, args []string)
 		for i := range result.Items {
 			objs = append(objs, &result.Items[i])
 		}
-
-		if util.IsToTable(g.Printer) {
-			obj, err = util.ToTable(builderRow, objs...)
-			if err != nil {
-				return err
-			}
-			objs = []runtime.Object{obj}
-		}
 	}
-	if err != nil {
+
+	if err = util.SortObjects(objs, g.Printer.SortBy); err != nil {
 		return err
 	}
+	if util.IsToTable(g.Printer) {
+		obj, err = util.ToTable(builderColumns, builderRow, objs...)
+		if err != nil {
+			return err
+		}
+		objs = []runtime.Object{obj}
+	}
 
-	printer, err := g.Printer.ToPrinterWitchColumn(builderColumnLabels)
+	printer, err := g.Printer.ToPrinterWithTable(printers.PrintOptions{})
 	if err != nil {
 		return err
 	}
@@ -14,6 +14,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
 )
 
 type getServing struct {
@@ -38,6 +39,9 @@ fn get serving sample-serving-c2dsf -o json
 
 # Get serving in YAML output format
 fn get serving sample-serving-c2dsf -o yaml
+
+# Watch the servings and print their changes
+ofn get serving --watch
 `
 
 	getServingLong = `
@@ -46,15 +50,7 @@ Prints a table fn the most important information.
 )
 
 var (
-	servingColumnLabels = []string{
-		"NAME",
-		"NAMESPACE",
-		"RUNTIME",
-		"RESOURCE",
-		"PHASE",
-		"STATE",
-		"AGE",
-	}
+	servingColumns = util.Columns("NAME", "NAMESPACE", "RUNTIME", "RESOURCE", "PHASE", "STATE", "AGE")
 )
 
 // newGetServing returns an initialized getServing instance
@@ -94,6 +90,7 @@ func newCmdGetServing(cf *genericclioptions.ConfigFlags, ioStreams genericcliopt
 	}
 
 	g.Printer.AddFlags(cmd)
+	g.Printer.AddTableFlags(cmd)
 	g.listFlag.addListFlag(cmd)
 
 	return cmd
@@ -102,7 +99,10 @@ func newCmdGetServing(cf *genericclioptions.ConfigFlags, ioStreams genericcliopt
 func (g *getServing) Complete(cmd *cobra.Command, args []string) error {
 	if len(args) != 0 {
 		g.Name = args[0]
-		g.Printer.SetForceDefail()
+		// The changes of the serving are printed as a table when watching it.
+		if !g.Watch {
+			g.Printer.SetForceDefail()
+		}
 	}
 	g.NamespaceIfScoped = true
 	if !g.enforceNamespace {
@@ -119,9 +119,19 @@ func (g *getServing) Run(fc client.Interface, cmd *cobra.Command, args []string)
 		err  error
 	)
 
+	if g.Watch {
+		lw := g.listWatch(fc.CoreV1beta1().RESTClient(), "servings", g.namespace, g.NamespaceIfScoped || g.Name != "", g.Name, func() runtime.Object {
+			return &v1beta1.ServingList{}
+		})
+		return g.runWatch(g.Out, g.Printer, servingColumns, servingRow, lw)
+	}
+
 	ctx := context.Background()
 	if g.Name != "" {
 		obj, err = fc.CoreV1beta1().Servings(g.namespace).Get(ctx, g.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
 		objs = []runtime.Object{obj}
 	} else {
 		opt := g.listFlag.ToOptions()
@@ -140,20 +150,20 @@ func (g *getServing) Run(fc client.Interface, cmd *cobra.Command, args []string)
 		for i := range result.Items {
 			objs = append(objs, &result.Items[i])
 		}
-
-		if util.IsToTable(g.Printer) {
-			obj, err = util.ToTable(servingRow, objs...)
-			if err != nil {
-				return err
-			}
-			objs = []runtime.Object{obj}
-		}
 	}
-	if err != nil {
+
+	if err = util.SortObjects(objs, g.Printer.SortBy); err != nil {
 		return err
 	}
+	if util.IsToTable(g.Printer) {
+		obj, err = util.ToTable(servingColumns, servingRow, objs...)
+		if err != nil {
+			return err
+		}
+		objs = []runtime.Object{obj}
+	}
 
-	printer, err := g.Printer.ToPrinterWitchColumn(servingColumnLabels)
+	printer, err := g.Printer.ToPrinterWithTable(printers.PrintOptions{})
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,131 @@
+package subcommand
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OpenFunction/cli/pkg/cmd/util"
+	openfunction "github.com/openfunction/apis/core/v1beta1"
+	fakeclient "github.com/openfunction/pkg/client/clientset/versioned/fake"
+	"github.com/openfunction/pkg/client/clientset/versioned/scheme"
+	swv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	fakeswclient "github.com/shipwright-io/build/pkg/client/clientset/versioned/fake"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/printers"
+	fakek8s "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestFunctionTable(t *testing.T) {
+	version := "v2.0.0"
+	revision := "main"
+	one, two := int32(1), int32(2)
+	start := metav1.NewTime(time.Now().Add(-3 * time.Minute))
+	end := metav1.NewTime(start.Add(90 * time.Second))
+
+	fn := &openfunction.Function{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default", Labels: map[string]string{"app": "sample"}},
+		Spec: openfunction.FunctionSpec{
+			Version: &version,
+			Image:   "openfunction/sample:v2",
+			Build:   &openfunction.BuildImpl{SrcRepo: &openfunction.GitRepo{Url: "https://github.com/OpenFunction/samples.git", Revision: &revision}},
+			Serving: &openfunction.ServingImpl{Runtime: openfunction.Knative},
+		},
+		Status: openfunction.FunctionStatus{
+			Build:   &openfunction.Condition{State: openfunction.Failed, ResourceRef: "sample-builder"},
+			Serving: &openfunction.Condition{State: openfunction.Running, ResourceRef: "sample-serving"},
+			URL:     "http://openfunction.io/default/sample",
+		},
+	}
+	builder := &openfunction.Builder{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample-builder", Namespace: "default"},
+		Status: openfunction.BuilderStatus{
+			State:       openfunction.Failed,
+			Reason:      "BuildRunTimeout",
+			ResourceRef: map[string]string{"shipwright.io/build": "sample-build", "shipwright.io/buildRun": "sample-buildrun"},
+		},
+	}
+	buildRun := &swv1alpha1.BuildRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample-buildrun", Namespace: "default"},
+		Status:     swv1alpha1.BuildRunStatus{StartTime: &start, CompletionTime: &end},
+	}
+	// The revisions of a Knative service scaled to zero are counted too.
+	deploys := []runtime.Object{
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "sample-v200-deployment", Namespace: "default", Labels: map[string]string{servingLabel: "sample-serving"}},
+			Spec:       appsv1.DeploymentSpec{Replicas: &two},
+			Status:     appsv1.DeploymentStatus{ReadyReplicas: one},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "sample-v100-deployment", Namespace: "default", Labels: map[string]string{servingLabel: "sample-serving"}},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		output      string
+		showLabels  bool
+		wantHeaders []string
+		wantCells   []string
+	}{
+		{
+			name:        "default",
+			wantHeaders: []string{"NAME", "NAMESPACE", "BUILDSTATE", "SERVINGSTATE", "BUILDER", "SERVING", "RUNTIME", "IMAGE", "VERSION", "READY", "URL", "AGE"},
+			wantCells:   []string{"sample", "default", "Failed", "Running", "sample-builder", "sample-serving", "knative", "openfunction/sample:v2", "v2.0.0", "1/2", "http://openfunction.io/default/sample", "<unknown>"},
+		},
+		{
+			name:        "wide",
+			output:      "wide",
+			wantHeaders: []string{"NAME", "NAMESPACE", "BUILDSTATE", "SERVINGSTATE", "BUILDER", "SERVING", "RUNTIME", "IMAGE", "VERSION", "READY", "URL", "AGE", "BUILDDURATION", "REVISION", "REASON"},
+			wantCells:   []string{"sample", "default", "Failed", "Running", "sample-builder", "sample-serving", "knative", "openfunction/sample:v2", "v2.0.0", "1/2", "http://openfunction.io/default/sample", "<unknown>", "90s", "main", "BuildRunTimeout"},
+		},
+		{
+			name:        "show labels",
+			showLabels:  true,
+			wantHeaders: []string{"NAME", "NAMESPACE", "BUILDSTATE", "SERVINGSTATE", "BUILDER", "SERVING", "RUNTIME", "IMAGE", "VERSION", "READY", "URL", "AGE", "LABELS"},
+			wantCells:   []string{"sample", "default", "Failed", "Running", "sample-builder", "sample-serving", "knative", "openfunction/sample:v2", "v2.0.0", "1/2", "http://openfunction.io/default/sample", "<unknown>", "app=sample"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			printer := util.NewPrinter("get", scheme.Scheme)
+			printer.PrintFlags.OutputFormat = &tt.output
+			printer.ShowLabels = tt.showLabels
+
+			ft := &fnTable{
+				functionClient: fakeclient.NewSimpleClientset(builder),
+				clientSet:      fakek8s.NewSimpleClientset(deploys...),
+				swClient:       fakeswclient.NewSimpleClientset(buildRun),
+				wide:           util.IsWide(printer),
+			}
+			table, err := util.ToTable(fnColumns, ft.row, fn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p, err := printer.ToPrinterWithTable(printers.PrintOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			out := &bytes.Buffer{}
+			if err := p.PrintObj(table, out); err != nil {
+				t.Fatal(err)
+			}
+
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			if len(lines) != 2 {
+				t.Fatalf("got %d lines, want 2:\n%s", len(lines), out)
+			}
+			if headers := strings.Fields(lines[0]); !reflect.DeepEqual(headers, tt.wantHeaders) {
+				t.Errorf("headers = %v, want %v", headers, tt.wantHeaders)
+			}
+			if cells := strings.Fields(lines[1]); !reflect.DeepEqual(cells, tt.wantCells) {
+				t.Errorf("cells = %v, want %v", cells, tt.wantCells)
+			}
+		})
+	}
+}
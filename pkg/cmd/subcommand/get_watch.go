@@ -0,0 +1,284 @@
+package subcommand
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/OpenFunction/cli/pkg/cmd/util"
+	"github.com/ahmetalpbalkan/go-cursor"
+	"github.com/mattn/go-isatty"
+	"github.com/openfunction/pkg/client/clientset/versioned/scheme"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+)
+
+// listWatch returns the ListerWatcher of a resource with the selectors of the flags,
+// restricted to the object with the name if it isn't empty.
+func (l *listFlag) listWatch(
+	rc rest.Interface,
+	resource string,
+	namespace string,
+	namespaced bool,
+	name string,
+	newList func() runtime.Object,
+) cache.ListerWatcher {
+	withSelectors := func(options metav1.ListOptions) metav1.ListOptions {
+		options.LabelSelector = l.LabelSelector
+		options.FieldSelector = l.FieldSelector
+		if name != "" {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+		}
+		return options
+	}
+
+	return &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options = withSelectors(options)
+			result := newList()
+			err := rc.Get().
+				NamespaceIfScoped(namespace, namespaced).
+				Resource(resource).
+				VersionedParams(&options, scheme.ParameterCodec).
+				Do(context.Background()).
+				Into(result)
+			return result, err
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options = withSelectors(options)
+			options.Watch = true
+			return rc.Get().
+				NamespaceIfScoped(namespace, namespaced).
+				Resource(resource).
+				VersionedParams(&options, scheme.ParameterCodec).
+				Watch(context.Background())
+		},
+	}
+}
+
+// runWatch prints the objects of lw, then their changes until the timeout of the flags expires.
+func (l *listFlag) runWatch(out io.Writer, printer *util.Printer, columns []metav1.TableColumnDefinition, row util.TableRow, lw cache.ListerWatcher) error {
+	ctx := context.Background()
+	if l.Timeout > 0 {
+		var done context.CancelFunc
+		ctx, done = context.WithTimeout(ctx, time.Duration(l.Timeout)*time.Second)
+		defer done()
+	}
+
+	p, err := newWatchPrinter(out, printer, columns, row, l.OutputWatchEvents)
+	if err != nil {
+		return err
+	}
+	return watchObjects(ctx, lw, p)
+}
+
+func watchObjects(ctx context.Context, lw cache.ListerWatcher, p *watchPrinter) error {
+	list, err := lw.List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	objs, err := meta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+	if err := p.printList(objs); err != nil {
+		return err
+	}
+
+	listMeta, err := meta.ListAccessor(list)
+	if err != nil {
+		return err
+	}
+	// The retry watcher resumes the watch from the last resourceVersion seen when the connection is closed.
+	w, err := watchtools.NewRetryWatcher(listMeta.GetResourceVersion(), lw)
+	if err != nil {
+		return err
+	}
+	defer w.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e, ok := <-w.ResultChan():
+			if !ok {
+				return nil
+			}
+			switch e.Type {
+			case watch.Error:
+				return k8serrors.FromObject(e.Object)
+			case watch.Bookmark:
+				continue
+			}
+			if err := p.print(e.Type, e.Object); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// watchPrinter prints the objects of 'get --watch' as they change.
+// The table is redrawn in place if the output is a terminal and the watch events aren't printed,
+// otherwise the changed objects are appended to the output.
+type watchPrinter struct {
+	out     io.Writer
+	printer printers.ResourcePrinter
+	options printers.PrintOptions
+	sortBy  string
+	columns []metav1.TableColumnDefinition
+	row     util.TableRow
+	table   bool
+	events  bool
+	inPlace bool
+
+	// The objects of the table redrawn in place, in the order they are seen.
+	keys    []string
+	objects map[string]runtime.Object
+	lines   int
+}
+
+func newWatchPrinter(out io.Writer, printer *util.Printer, columns []metav1.TableColumnDefinition, row util.TableRow, events bool) (*watchPrinter, error) {
+	p := &watchPrinter{
+		out:     out,
+		columns: columns,
+		row:     row,
+		options: printers.PrintOptions{Wide: util.IsWide(printer), ShowLabels: printer.ShowLabels},
+		sortBy:  printer.SortBy,
+		table:   util.IsToTable(printer),
+		events:  events,
+		objects: map[string]runtime.Object{},
+	}
+	var err error
+	p.printer, err = printer.ToPrinterWithTable(printers.PrintOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	if f, ok := out.(*os.File); ok && p.table && !events {
+		p.inPlace = isatty.IsTerminal(f.Fd())
+	}
+	return p, nil
+}
+
+func (p *watchPrinter) printList(objs []runtime.Object) error {
+	// Only the objects listed at first are sorted, the changes are printed in the order they happen.
+	if err := util.SortObjects(objs, p.sortBy); err != nil {
+		return err
+	}
+	if p.inPlace {
+		for _, obj := range objs {
+			p.update(watch.Added, obj)
+		}
+		return p.redraw()
+	}
+
+	if p.table && !p.events {
+		table, err := util.ToTable(p.columns, p.row, objs...)
+		if err != nil {
+			return err
+		}
+		return p.printer.PrintObj(table, p.out)
+	}
+	for _, obj := range objs {
+		if err := p.print(watch.Added, obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *watchPrinter) print(eventType watch.EventType, obj runtime.Object) error {
+	if p.inPlace {
+		p.update(eventType, obj)
+		return p.redraw()
+	}
+
+	if p.table {
+		table, err := util.ToTable(p.columns, p.row, obj)
+		if err != nil {
+			return err
+		}
+		obj = table
+	} else if err := setKind(obj); err != nil {
+		return err
+	}
+
+	if p.events {
+		obj = &metav1.WatchEvent{Type: string(eventType), Object: runtime.RawExtension{Object: obj}}
+	}
+	return p.printer.PrintObj(obj, p.out)
+}
+
+func (p *watchPrinter) update(eventType watch.EventType, obj runtime.Object) {
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		return
+	}
+	key := m.GetNamespace() + "/" + m.GetName()
+
+	if eventType == watch.Deleted {
+		delete(p.objects, key)
+		for i, k := range p.keys {
+			if k == key {
+				p.keys = append(p.keys[:i], p.keys[i+1:]...)
+				break
+			}
+		}
+		return
+	}
+
+	if _, ok := p.objects[key]; !ok {
+		p.keys = append(p.keys, key)
+	}
+	p.objects[key] = obj
+}
+
+// redraw prints the table over the previous one.
+func (p *watchPrinter) redraw() error {
+	objs := make([]runtime.Object, 0, len(p.keys))
+	for _, key := range p.keys {
+		objs = append(objs, p.objects[key])
+	}
+	table, err := util.ToTable(p.columns, p.row, objs...)
+	if err != nil {
+		return err
+	}
+
+	// A new table printer prints the headers every time.
+	buf := &bytes.Buffer{}
+	if err := printers.NewTablePrinter(p.options).PrintObj(table, buf); err != nil {
+		return err
+	}
+
+	if p.lines > 0 {
+		fmt.Fprint(p.out, cursor.MoveUp(p.lines)+cursor.ClearScreenDown())
+	}
+	p.lines = strings.Count(buf.String(), "\n")
+	_, err = buf.WriteTo(p.out)
+	return err
+}
+
+// setKind sets the apiVersion and the kind of obj, which are empty in the objects decoded by the clients.
+func setKind(obj runtime.Object) error {
+	if !obj.GetObjectKind().GroupVersionKind().Empty() {
+		return nil
+	}
+	gvks, _, err := scheme.Scheme.ObjectKinds(obj)
+	if err != nil {
+		return err
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvks[0])
+	return nil
+}
@@ -0,0 +1,112 @@
+package subcommand
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/OpenFunction/cli/pkg/cmd/util"
+	openfunction "github.com/openfunction/apis/core/v1beta1"
+	"github.com/openfunction/pkg/client/clientset/versioned/scheme"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestWatchObjects(t *testing.T) {
+	function := func(rv string, buildState string) *openfunction.Function {
+		return &openfunction.Function{
+			ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default", ResourceVersion: rv},
+			Status:     openfunction.FunctionStatus{Build: &openfunction.Condition{State: buildState}},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		output  string
+		events  bool
+		wantOut []string
+	}{
+		{
+			name: "table",
+			wantOut: []string{
+				"NAME NAMESPACE BUILDSTATE SERVINGSTATE BUILDER SERVING RUNTIME IMAGE VERSION READY URL AGE\nsample default Building <unknown>\n",
+				"sample default Succeeded <unknown>\nsample default Succeeded <unknown>\n",
+			},
+		},
+		{
+			name:   "table with events",
+			events: true,
+			wantOut: []string{
+				"EVENT NAME NAMESPACE BUILDSTATE SERVINGSTATE BUILDER SERVING RUNTIME IMAGE VERSION READY URL AGE\nADDED sample default Building <unknown>\n",
+				"MODIFIED sample default Succeeded <unknown>\nDELETED sample default Succeeded <unknown>\n",
+			},
+		},
+		{
+			name:   "json with events",
+			output: "json",
+			events: true,
+			wantOut: []string{
+				`{"type":"ADDED","object":{"kind":"Function","apiVersion":"core.openfunction.io/v1beta1"`,
+				`{"type":"MODIFIED","object":{"kind":"Function"`,
+				`{"type":"DELETED","object":{"kind":"Function"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, done := context.WithCancel(context.Background())
+			defer done()
+
+			fw := watch.NewFake()
+			watches := 0
+			lw := &cache.ListWatch{
+				ListFunc: func(metav1.ListOptions) (runtime.Object, error) {
+					list := &openfunction.FunctionList{Items: []openfunction.Function{*function("1", openfunction.Building)}}
+					list.ResourceVersion = "1"
+					return list, nil
+				},
+				WatchFunc: func(metav1.ListOptions) (watch.Interface, error) {
+					// The watch is created again once the events are sent and the fake watcher is stopped.
+					watches++
+					if watches > 1 {
+						done()
+						return watch.NewFake(), nil
+					}
+					return fw, nil
+				},
+			}
+			go func() {
+				fw.Modify(function("2", openfunction.Succeeded))
+				fw.Delete(function("3", openfunction.Succeeded))
+				fw.Stop()
+			}()
+
+			printer := util.NewPrinter("get", scheme.Scheme)
+			printer.PrintFlags.OutputFormat = &tt.output
+			out := &bytes.Buffer{}
+			p, err := newWatchPrinter(out, printer, fnColumns, (&fnTable{}).row, tt.events)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := watchObjects(ctx, lw, p); err != nil {
+				t.Fatal(err)
+			}
+
+			// The columns are aligned with spaces, the empty cells are ignored.
+			lines := strings.Split(out.String(), "\n")
+			for i := range lines {
+				lines[i] = strings.Join(strings.Fields(lines[i]), " ")
+			}
+			got := strings.Join(lines, "\n")
+			for _, want := range tt.wantOut {
+				if !strings.Contains(got, want) {
+					t.Errorf("the output doesn't contain %q:\n%s", want, out)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package subcommand
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/OpenFunction/cli/pkg/cmd/util"
+	"github.com/OpenFunction/cli/pkg/components/bundle"
+	"github.com/OpenFunction/cli/pkg/components/common"
+	"github.com/OpenFunction/cli/pkg/components/inventory"
+	"github.com/OpenFunction/cli/pkg/components/manifest"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// ImagesList is the commandline for 'images list' sub command
+type ImagesList struct {
+	genericclioptions.IOStreams
+
+	// The components are selected the same way as when installing.
+	install *Install
+
+	KubernetesVersion string
+}
+
+// NewImagesList returns an initialized ImagesList instance
+func NewImagesList(ioStreams genericclioptions.IOStreams) *ImagesList {
+	return &ImagesList{
+		IOStreams: ioStreams,
+		install:   NewInstall(ioStreams),
+	}
+}
+
+func NewCmdImages(cf *genericclioptions.ConfigFlags, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "images",
+		DisableFlagsInUseLine: true,
+		Short:                 "Manage the images of OpenFunction and its dependencies.",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(newCmdImagesList(cf, ioStreams))
+	return cmd
+}
+
+func newCmdImagesList(cf *genericclioptions.ConfigFlags, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	l := NewImagesList(ioStreams)
+	i := l.install
+
+	cmd := &cobra.Command{
+		Use:                   "list [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "List the images used by OpenFunction and its dependencies.",
+		Long: `This command will list the images used by the components that "ofn install" installs with the same flags,
+one image per line, e.g. to mirror them to a private registry before running "ofn install --image-registry".`,
+		Example: `
+# List the images of OpenFunction with all dependencies
+ofn images list --all
+
+# List the images and where they are pulled from when installing with --image-registry
+ofn images list --all --image-registry registry.corp.local/mirror
+
+# List the images of the components of a bundle
+ofn images list --all --from-bundle ofn-bundle.tar.gz
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(l.ValidateArgs(cf))
+			util.CheckErr(l.RunList())
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&i.Runtimes, "runtime", "r", []string{"knative"}, "List of runtimes to be installed, optionally \"knative\", \"async\".")
+	cmd.Flags().StringVar(&i.Ingress, "ingress", "nginx", "The type of ingress controller to be installed, optionally \"nginx\".")
+	cmd.Flags().BoolVar(&i.WithoutCI, "without-ci", false, "Skip the installation of CI components.")
+	cmd.Flags().BoolVar(&i.WithAll, "all", false, "For installing all dependencies.")
+	cmd.Flags().BoolVar(&i.RegionCN, "region-cn", false, "For users who have limited access to gcr.io or github.com.")
+	cmd.Flags().StringVar(&i.OpenFunctionVersion, "version", "", "Used to specify the version of OpenFunction to be installed.")
+	cmd.Flags().StringVar(&i.FromBundle, "from-bundle", "", "Use the manifests of the bundle created by 'ofn bundle create' instead of downloading them.")
+	cmd.Flags().StringVar(&i.ImageRegistry, "image-registry", "", "Print the location of each image in this registry next to it.")
+	cmd.Flags().StringVar(&l.KubernetesVersion, "kubernetes-version", "", "The version of the target Kubernetes cluster, defaults to the version of the current cluster.")
+	cmd.Flags().DurationVar(&i.Timeout, "timeout", 10*time.Minute, "Set timeout time. Default is 10 minutes.")
+	return cmd
+}
+
+func (l *ImagesList) ValidateArgs(cf *genericclioptions.ConfigFlags) error {
+	if err := l.install.ValidateArgs(); err != nil {
+		return err
+	}
+
+	v, err := resolveKubernetesVersion(cf, l.KubernetesVersion)
+	if err != nil {
+		return err
+	}
+	l.KubernetesVersion = v
+	return nil
+}
+
+func (l *ImagesList) RunList() error {
+	i := l.install
+
+	ctx, done := context.WithTimeout(
+		context.Background(),
+		i.Timeout,
+	)
+	defer done()
+
+	if err := i.calculateConditions(); err != nil {
+		return errors.Wrap(err, "failed to calculate conditions")
+	}
+
+	inv, err := inventory.GetInventoryWithServerVersion(
+		l.KubernetesVersion,
+		i.RegionCN,
+		i.WithKnative,
+		i.WithKeda,
+		i.WithDapr,
+		i.WithShipWright,
+		i.WithCertManager,
+		i.WithIngressNginx,
+		i.OpenFunctionVersion,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to get inventory")
+	}
+
+	if i.FromBundle != "" {
+		b, err := bundle.Open(i.FromBundle)
+		if err != nil {
+			return errors.Wrap(err, "failed to open bundle")
+		}
+		defer b.Close()
+
+		if inv, err = b.Inventory(inv); err != nil {
+			return errors.Wrap(err, "failed to use bundle")
+		}
+	}
+
+	images, err := common.GetImages(ctx, inv)
+	if err != nil {
+		return err
+	}
+
+	set := map[string]bool{}
+	for _, imgs := range images {
+		for _, img := range imgs {
+			set[img] = true
+		}
+	}
+	all := make([]string, 0, len(set))
+	for img := range set {
+		all = append(all, img)
+	}
+	sort.Strings(all)
+
+	for _, img := range all {
+		if i.ImageRegistry != "" {
+			fmt.Fprintf(l.Out, "%s %s\n", img, manifest.RelocateImage(img, i.ImageRegistry))
+		} else {
+			fmt.Fprintln(l.Out, img)
+		}
+	}
+	return nil
+}
@@ -18,7 +18,9 @@ import (
 	"github.com/OpenFunction/cli/pkg/cmd/util"
 	"github.com/OpenFunction/cli/pkg/cmd/util/client"
 	"github.com/OpenFunction/cli/pkg/cmd/util/spinners"
+	"github.com/OpenFunction/cli/pkg/components/bundle"
 	"github.com/OpenFunction/cli/pkg/components/common"
+	"github.com/OpenFunction/cli/pkg/components/config"
 	"github.com/OpenFunction/cli/pkg/components/inventory"
 	"github.com/oliveagle/jsonpath"
 	"github.com/pkg/errors"
@@ -49,11 +51,17 @@ type Install struct {
 	WithAll             bool
 	RegionCN            bool
 	OpenFunctionVersion string
+	FromBundle          string
+	ImageRegistry       string
+	ConfigFile          string
+	PrintConfig         bool
 	DryRun              bool
 	Upgrade             bool
+	Atomic              bool
 	Yes                 bool
 	Timeout             time.Duration
 	openFunctionVersion *version.Version
+	config              *config.InstallConfig
 }
 
 // NewInstall returns an initialized Init instance
@@ -64,7 +72,7 @@ func NewInstall(ioStreams genericclioptions.IOStreams) *Install {
 }
 
 func NewCmdInstall(cf *genericclioptions.ConfigFlags, ioStreams genericclioptions.IOStreams) *cobra.Command {
-	var cl *k8s.Clientset
+	var cl k8s.Interface
 
 	i := NewInstall(ioStreams)
 
@@ -86,6 +94,15 @@ ofn install --region-cn --all
 # Install a specific version of OpenFunction
 ofn install --all --version v0.4.0
 
+# Install OpenFunction as described by a config file
+ofn install -c ofn-install.yaml
+
+# Remove what has been installed if the installation fails
+ofn install --all --atomic
+
+# Print the config of an installation with the current flags
+ofn install --all --print-config > ofn-install.yaml
+
 # See more at: https://github.com/OpenFunction/cli/blob/main/docs/install.md
 `,
 		PreRunE: func(cmd *cobra.Command, args []string) (err error) {
@@ -96,8 +113,9 @@ ofn install --all --version v0.4.0
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(i.loadConfig(cmd))
 			util.CheckErr(i.ValidateArgs())
-			util.CheckErr(i.RunInstall(cl, cmd))
+			util.CheckErr(i.RunInstall(cf, cl, cmd))
 		},
 	}
 
@@ -113,8 +131,13 @@ ofn install --all --version v0.4.0
 	cmd.Flags().BoolVar(&i.RegionCN, "region-cn", false, "For users who have limited access to gcr.io or github.com.")
 	cmd.Flags().BoolVar(&i.DryRun, "dry-run", false, "Used to prompt for the components and their versions to be installed by the current command.")
 	cmd.Flags().BoolVar(&i.Upgrade, "upgrade", false, "Upgrade components to target version while installing.")
+	cmd.Flags().BoolVar(&i.Atomic, "atomic", false, "Roll back the changes made by the installation if it fails or is interrupted, the inventory record is left unchanged.")
 	cmd.Flags().BoolVarP(&i.Yes, "yes", "y", false, "Automatic yes to prompts.")
 	cmd.Flags().StringVar(&i.OpenFunctionVersion, "version", "", "Used to specify the version of OpenFunction to be installed.")
+	cmd.Flags().StringVar(&i.FromBundle, "from-bundle", "", "Use the manifests of the bundle created by 'ofn bundle create' instead of downloading them.")
+	cmd.Flags().StringVar(&i.ImageRegistry, "image-registry", "", "Pull the images of the components from this registry, see 'ofn images list' for the images to be mirrored.")
+	cmd.Flags().StringVarP(&i.ConfigFile, "config", "c", "", "The config file describing the installation, the flags which are set take precedence over it.")
+	cmd.Flags().BoolVar(&i.PrintConfig, "print-config", false, "Print the effective config for the current flags instead of installing.")
 	cmd.Flags().DurationVar(&i.Timeout, "timeout", 10*time.Minute, "Set timeout time. Default is 10 minutes.")
 	// In order to avoid too many options causing misunderstandings among users,
 	// we have hidden the following parameters,
@@ -127,6 +150,15 @@ ofn install --all --version v0.4.0
 }
 
 func (i *Install) ValidateArgs() error {
+	// Use the version of OpenFunction the bundle has been created for.
+	if i.FromBundle != "" && i.OpenFunctionVersion == "" {
+		index, err := bundle.ReadIndex(i.FromBundle)
+		if err != nil {
+			return errors.Wrap(err, "failed to read bundle")
+		}
+		i.OpenFunctionVersion = index.OpenFunctionVersion
+	}
+
 	if i.OpenFunctionVersion == common.LatestVersion {
 		return nil
 	}
@@ -164,8 +196,16 @@ func (i *Install) ValidateArgs() error {
 	return nil
 }
 
-func (i *Install) RunInstall(cl *k8s.Clientset, cmd *cobra.Command) error {
-	operator := common.NewOperator(runtime.GOOS, runtime.GOARCH, i.OpenFunctionVersion, i.Timeout, i.RegionCN, i.Verbose)
+func (i *Install) RunInstall(cf *genericclioptions.ConfigFlags, cl k8s.Interface, cmd *cobra.Command) error {
+	operator := common.NewOperator(cf, runtime.GOOS, runtime.GOARCH, i.OpenFunctionVersion, i.Timeout, i.RegionCN, i.Verbose)
+	return i.runInstall(cl, operator)
+}
+
+// runInstall installs the components with the operator, which is made with a fake executor in the tests.
+func (i *Install) runInstall(cl k8s.Interface, operator *common.Operator) error {
+	if i.ImageRegistry != "" {
+		operator.RelocateImages(i.ImageRegistry)
+	}
 	continueFunc := func() bool {
 		reader := bufio.NewReader(os.Stdin)
 		util.BeforeTask("You have specified the `--upgrade` flag, which means that the installation process " +
@@ -215,8 +255,38 @@ func (i *Install) RunInstall(cl *k8s.Clientset, cmd *cobra.Command) error {
 	if err != nil {
 		return errors.Wrap(err, "failed to get pending inventory")
 	}
+	if i.FromBundle != "" {
+		b, err := bundle.Open(i.FromBundle)
+		if err != nil {
+			return errors.Wrap(err, "failed to open bundle")
+		}
+		defer b.Close()
+
+		if inventoryPending, err = b.Inventory(inventoryPending); err != nil {
+			return errors.Wrap(err, "failed to use bundle")
+		}
+	}
+	if i.config != nil {
+		inventoryPending = i.config.Inventory(inventoryPending)
+	}
+	if err := renameNamespaces(i.config, operator); err != nil {
+		return err
+	}
 	operator.Inventory = inventoryPending
-	inventoryExist := getExistComponentsInventory(ctx, cl)
+
+	if i.PrintConfig {
+		c, err := i.effectiveConfig(inventoryPending, operator)
+		if err != nil {
+			return err
+		}
+		data, err := c.Marshal()
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(i.Out, string(data))
+		return nil
+	}
+	inventoryExist := getExistComponentsInventory(ctx, cl, operator)
 
 	util.BeforeTask("Start installing OpenFunction and its dependencies.\n" +
 		"The following components will be installed:")
@@ -249,7 +319,12 @@ func (i *Install) RunInstall(cl *k8s.Clientset, cmd *cobra.Command) error {
 	if _, err := operator.GetInventoryRecord(ctx, false); err != nil {
 		return errors.Wrap(err, "failed to get inventory record")
 	}
-	defer operator.RecordInventory(ctx)
+	// An atomic installation only updates the record once it succeeds.
+	if i.Atomic {
+		operator.BeginTransaction()
+	} else {
+		defer operator.RecordInventory(ctx)
+	}
 
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
@@ -260,96 +335,26 @@ func (i *Install) RunInstall(cl *k8s.Clientset, cmd *cobra.Command) error {
 
 	start := time.Now()
 
-	grp1 := spinners.NewSpinnerGroup()
-	count := 0
-
-	if i.WithDapr {
-		// If Dapr already exists and --upgrade is not specified, skip this step.
-		if !inventoryExist[inventory.DaprName] || i.Upgrade {
-			count += 1
-			grp1.AddSpinner()
-			go func(ctx context.Context, idx int) {
-				spinner := grp1.At(idx).WithName("Dapr")
-				installDapr(ctx, spinner, operator)
-			}(ctx, count-1)
-		}
-	}
-
-	if i.WithKeda {
-		// If Keda already exists and --upgrade is not specified, skip this step.
-		if !inventoryExist[inventory.KedaName] || i.Upgrade {
-			count += 1
-			grp1.AddSpinner()
-			go func(ctx context.Context, idx int) {
-				spinner := grp1.At(idx).WithName("Keda")
-				installKeda(ctx, spinner, cl, operator)
-			}(ctx, count-1)
-		}
-	}
-
-	if i.WithKnative {
-		// If Knative Serving already exists and --upgrade is not specified, skip this step.
-		if !inventoryExist[inventory.KnativeServingName] || i.Upgrade {
-			count += 1
-			grp1.AddSpinner()
-			go func(ctx context.Context, idx int) {
-				spinner := grp1.At(idx).WithName("Knative Serving")
-				installKnativeServing(ctx, spinner, cl, operator)
-			}(ctx, count-1)
-		}
+	// Existing components are skipped unless --upgrade is specified,
+	// the components depending on them no longer need to wait for them.
+	skip := map[string]bool{}
+	if !i.Upgrade {
+		skip = inventoryExist
 	}
-
-	if i.WithShipWright {
-		// If Shipwright already exists and --upgrade is not specified, skip this step.
-		if !inventoryExist[inventory.ShipwrightName] || i.Upgrade {
-			count += 1
-			grp1.AddSpinner()
-			go func(ctx context.Context, idx int) {
-				spinner := grp1.At(idx).WithName("Shipwright")
-				installShipwright(ctx, spinner, cl, operator)
-			}(ctx, count-1)
-		}
-	}
-
-	if i.WithCertManager {
-		// If Cert Manager already exists and --upgrade is not specified, skip this step.
-		if !inventoryExist[inventory.CertManagerName] || i.Upgrade {
-			count += 1
-			grp1.AddSpinner()
-			go func(ctx context.Context, idx int) {
-				spinner := grp1.At(idx).WithName("Cert Manager")
-				installCertManager(ctx, spinner, cl, operator)
-			}(ctx, count-1)
-		}
+	sched, err := newInstallScheduler(cl, operator, i.config, skip)
+	if err != nil {
+		return errors.Wrap(err, "failed to schedule the installation")
 	}
-
-	if i.WithIngressNginx {
-		// If Ingress Nginx already exists and --upgrade is not specified, skip this step.
-		if !inventoryExist[inventory.IngressName] || i.Upgrade {
-			count += 1
-			grp1.AddSpinner()
-			go func(ctx context.Context, idx int) {
-				spinner := grp1.At(idx).WithName("Ingress")
-				installIngress(ctx, spinner, cl, operator)
-			}(ctx, count-1)
+	if err := sched.Run(ctx); err != nil {
+		if i.Atomic {
+			return i.rollback(operator, err)
 		}
-	}
-
-	grp1.Start(ctx)
-	if err := grp1.Wait(); err != nil {
 		return errors.New(util.TaskFail(err.Error()))
 	}
-
-	grp2 := spinners.NewSpinnerGroup()
-	grp2.AddSpinner()
-	go func(ctx context.Context, idx int) {
-		spinner := grp2.At(idx).WithName("OpenFunction")
-		installOpenFunction(ctx, spinner, cl, operator)
-	}(ctx, 0)
-
-	grp2.Start(ctx)
-	if err := grp2.Wait(); err != nil {
-		return errors.New(util.TaskFail(err.Error()))
+	if i.Atomic {
+		if err := operator.RecordInventory(ctx); err != nil {
+			return errors.Wrap(err, "failed to record inventory")
+		}
 	}
 
 	end := time.Since(start)
@@ -359,6 +364,20 @@ func (i *Install) RunInstall(cl *k8s.Clientset, cmd *cobra.Command) error {
 	return nil
 }
 
+// rollback reverts the changes made by the installation which failed with cause.
+func (i *Install) rollback(operator *common.Operator, cause error) error {
+	util.BeforeTask("Rolling back the changes made by the installation...")
+
+	// The context of the installation is gone if it has been interrupted or has timed out.
+	ctx, done := context.WithTimeout(context.Background(), i.Timeout)
+	defer done()
+
+	if err := operator.Rollback(ctx); err != nil {
+		return errors.New(util.TaskFail(fmt.Sprintf("%s\nfailed to roll back: %s", cause, err)))
+	}
+	return errors.New(util.TaskFail(fmt.Sprintf("%s\nthe changes have been rolled back", cause)))
+}
+
 func (i *Install) calculateConditions() error {
 
 	// Enable shipwright by default
@@ -412,49 +431,39 @@ func (i *Install) calculateConditions() error {
 		}
 	}
 
+	// The components enabled or disabled by the config take precedence.
+	for name, enabled := range i.config.Enabled() {
+		switch name {
+		case inventory.DaprName:
+			i.WithDapr = enabled
+		case inventory.KedaName:
+			i.WithKeda = enabled
+		case inventory.KnativeServingName:
+			i.WithKnative = enabled
+		case inventory.ShipwrightName:
+			i.WithShipWright = enabled
+		case inventory.CertManagerName:
+			i.WithCertManager = enabled
+		case inventory.IngressName:
+			i.WithIngressNginx = enabled
+		}
+	}
+
 	return nil
 }
 
-func getExistComponentsInventory(ctx context.Context, cl *k8s.Clientset) map[string]bool {
-	// We assume that a component exists when its deployment is in the available state.
+func getExistComponentsInventory(ctx context.Context, cl k8s.Interface, operator *common.Operator) map[string]bool {
+	// We assume that a component exists when its workload is ready.
+	// OpenFunction itself is always installed.
 	m := map[string]bool{}
-
-	if exist := common.IsComponentExist(ctx, cl, common.DaprNamespace, "dapr-operator"); exist {
-		m[inventory.DaprName] = true
-	}
-
-	if exist := common.IsComponentExist(ctx, cl, common.KedaNamespace, "keda-operator"); exist {
-		m[inventory.KedaName] = true
-	}
-
-	if exist := common.IsComponentExist(ctx, cl, common.KnativeServingNamespace, "controller"); exist {
-		m[inventory.KnativeServingName] = true
-	}
-
-	if exist := common.IsComponentExist(ctx, cl, common.KourierNamespace, "3scale-kourier-gateway"); exist {
-		m[inventory.KourierName] = true
-	}
-
-	if exist := common.IsComponentExist(ctx, cl, common.KnativeServingNamespace, "default-domain"); exist {
-		m[inventory.ServingDefaultDomainName] = true
-	}
-
-	if exist := common.IsComponentExist(ctx, cl, common.TektonPipelineNamespace, "tekton-pipelines-controller"); exist {
-		m[inventory.TektonPipelinesName] = true
-	}
-
-	if exist := common.IsComponentExist(ctx, cl, common.ShipwrightNamespace, "shipwright-build-controller"); exist {
-		m[inventory.ShipwrightName] = true
-	}
-
-	if exist := common.IsComponentExist(ctx, cl, common.CertManagerNamespace, "cert-manager"); exist {
-		m[inventory.CertManagerName] = true
-	}
-
-	if exist := common.IsComponentExist(ctx, cl, common.IngressNginxNamespace, "ingress-nginx-controller"); exist {
-		m[inventory.IngressName] = true
+	for _, name := range common.GetComponentNames() {
+		if name == inventory.OpenFunctionName {
+			continue
+		}
+		if status, err := operator.DetectComponent(ctx, cl, name); err == nil && status.Ready {
+			m[name] = true
+		}
 	}
-
 	return m
 }
 
@@ -462,7 +471,7 @@ func printInventory(inventory map[string]string) {
 	util.PrintInventory(inventory)
 }
 
-func installDapr(ctx context.Context, spinner *spinners.Spinner, operator *common.Operator) {
+func installDapr(ctx context.Context, spinner *spinners.Spinner, cl k8s.Interface, operator *common.Operator) error {
 	ctx, done := context.WithCancel(ctx)
 	defer done()
 
@@ -470,23 +479,21 @@ func installDapr(ctx context.Context, spinner *spinners.Spinner, operator *commo
 
 	spinner.Update("Downloading Dapr CLI...")
 	if err := operator.DownloadDaprClient(ctx, v); err != nil {
-		spinner.Error(errors.Wrap(err, "Failed to download Dapr CLI"))
-		return
+		return errors.Wrap(err, "Failed to download Dapr CLI")
 	}
 
 	spinner.Update("Initializing Dapr with Kubernetes mode...")
-	if err := operator.InitDapr(ctx, v); err != nil {
-		spinner.Error(errors.Wrap(err, "Failed to init Dapr"))
-		return
+	if err := operator.InitDapr(ctx, cl, v); err != nil {
+		return errors.Wrap(err, "Failed to init Dapr")
 	}
 
 	// Record the version of Dapr
 	operator.Records.Dapr = v
 
-	spinner.Done()
+	return nil
 }
 
-func installKeda(ctx context.Context, spinner *spinners.Spinner, cl *k8s.Clientset, operator *common.Operator) {
+func installKeda(ctx context.Context, spinner *spinners.Spinner, cl k8s.Interface, operator *common.Operator) error {
 	ctx, done := context.WithCancel(ctx)
 	defer done()
 
@@ -495,144 +502,155 @@ func installKeda(ctx context.Context, spinner *spinners.Spinner, cl *k8s.Clients
 	v := operator.Inventory[inventory.KedaName].GetVersion()
 	yamls, err := operator.Inventory[inventory.KedaName].GetYamlFile(v)
 	if err != nil {
-		spinner.Error(errors.Wrap(err, "Failed to get yaml file"))
-		return
+		return errors.Wrap(err, "Failed to get yaml file")
 	}
 
 	if err := operator.InstallKeda(ctx, yamls["MAIN"]); err != nil {
-		spinner.Error(errors.Wrap(err, "Failed to install Keda"))
-		return
+		return errors.Wrap(err, "Failed to install Keda")
 	}
 
-	// Record the version of Keda
-	operator.Records.Keda = v
-
 	spinner.Update("Checking if Keda is ready...")
 	if err := operator.CheckKedaIsReady(ctx, cl); err != nil {
-		spinner.Error(errors.Wrap(err, "Failed to check Keda readiness"))
-		return
+		return errors.Wrap(err, "Failed to check Keda readiness")
 	}
 
-	spinner.Done()
+	// Record the version of Keda
+	operator.Records.Keda = v
+
+	return nil
 }
 
-func installKnativeServing(ctx context.Context, spinner *spinners.Spinner, cl *k8s.Clientset, operator *common.Operator) {
+func installKnativeServing(ctx context.Context, spinner *spinners.Spinner, cl k8s.Interface, operator *common.Operator) error {
 	ctx, done := context.WithCancel(ctx)
 	defer done()
 
-	spinner.Update("Installing Knative Serving...")
+	spinner.Update("Installing...")
 
-	knv := operator.Inventory[inventory.KnativeServingName].GetVersion()
-	knYamls, err := operator.Inventory[inventory.KnativeServingName].GetYamlFile(knv)
+	v := operator.Inventory[inventory.KnativeServingName].GetVersion()
+	yamls, err := operator.Inventory[inventory.KnativeServingName].GetYamlFile(v)
 	if err != nil {
-		spinner.Error(errors.Wrap(err, "Failed to get yaml file"))
-		return
+		return errors.Wrap(err, "Failed to get yaml file")
 	}
 
-	if err := operator.InstallKnativeServing(ctx, knYamls["CRD"], knYamls["CORE"]); err != nil {
-		spinner.Error(errors.Wrap(err, "Failed to install Knative Serving"))
-		return
+	if err := operator.InstallKnativeServing(ctx, yamls["CRD"], yamls["CORE"]); err != nil {
+		return errors.Wrap(err, "Failed to install Knative Serving")
 	}
 
-	// Record the version of KnativeServing
-	operator.Records.KnativeServing = knv
-
 	spinner.Update("Checking if Knative Serving is ready...")
 	if err := operator.CheckKnativeServingIsReady(ctx, cl); err != nil {
-		spinner.Error(errors.Wrap(err, "Failed to check Knative Serving readiness"))
-		return
+		return errors.Wrap(err, "Failed to check Knative Serving readiness")
 	}
 
+	// Record the version of KnativeServing
+	operator.Records.KnativeServing = v
+
+	return nil
+}
+
+func installDefaultDomain(ctx context.Context, spinner *spinners.Spinner, cl k8s.Interface, operator *common.Operator) error {
+	ctx, done := context.WithCancel(ctx)
+	defer done()
+
 	spinner.Update("Configuring Knative Serving's DNS...")
-	ddv := operator.Inventory[inventory.ServingDefaultDomainName].GetVersion()
-	ddYamls, err := operator.Inventory[inventory.ServingDefaultDomainName].GetYamlFile(ddv)
+
+	v := operator.Inventory[inventory.ServingDefaultDomainName].GetVersion()
+	yamls, err := operator.Inventory[inventory.ServingDefaultDomainName].GetYamlFile(v)
 	if err != nil {
-		spinner.Error(errors.Wrap(err, "Failed to get yaml file"))
-		return
+		return errors.Wrap(err, "Failed to get yaml file")
 	}
-	if err := operator.ConfigKnativeServingDefaultDomain(ctx, ddYamls["MAIN"]); err != nil {
-		spinner.Error(errors.Wrap(err, "Failed to config Knative Serving's DNS"))
-		return
+
+	if err := operator.ConfigKnativeServingDefaultDomain(ctx, yamls["MAIN"]); err != nil {
+		return errors.Wrap(err, "Failed to config Knative Serving's DNS")
 	}
 
 	// Record the version of DefaultDomain
-	operator.Records.DefaultDomain = ddv
+	operator.Records.DefaultDomain = v
+
+	return nil
+}
+
+func installKourier(ctx context.Context, spinner *spinners.Spinner, cl k8s.Interface, operator *common.Operator) error {
+	ctx, done := context.WithCancel(ctx)
+	defer done()
 
 	spinner.Update("Installing Kourier as Knative's gateway...")
-	krv := operator.Inventory[inventory.KourierName].GetVersion()
-	krYamls, err := operator.Inventory[inventory.KourierName].GetYamlFile(krv)
+
+	v := operator.Inventory[inventory.KourierName].GetVersion()
+	yamls, err := operator.Inventory[inventory.KourierName].GetYamlFile(v)
 	if err != nil {
-		spinner.Error(errors.Wrap(err, "Failed to get yaml file"))
-		return
-	}
-	if err := operator.InstallKourier(ctx, cl, krYamls["MAIN"]); err != nil {
-		spinner.Error(errors.Wrap(err, "Failed to install Kourier"))
-		return
+		return errors.Wrap(err, "Failed to get yaml file")
 	}
 
-	// Record the version of Kourier
-	operator.Records.Kourier = krv
+	if err := operator.InstallKourier(ctx, cl, yamls["MAIN"]); err != nil {
+		return errors.Wrap(err, "Failed to install Kourier")
+	}
 
 	spinner.Update("Checking if Kourier is ready...")
 	if err := operator.CheckKourierIsReady(ctx, cl); err != nil {
-		spinner.Error(errors.Wrap(err, "Failed to check Kourier readiness"))
-		return
+		return errors.Wrap(err, "Failed to check Kourier readiness")
 	}
 
-	spinner.Done()
+	// Record the version of Kourier
+	operator.Records.Kourier = v
+
+	return nil
 }
 
-func installShipwright(ctx context.Context, spinner *spinners.Spinner, cl *k8s.Clientset, operator *common.Operator) {
+func installTektonPipelines(ctx context.Context, spinner *spinners.Spinner, cl k8s.Interface, operator *common.Operator) error {
 	ctx, done := context.WithCancel(ctx)
 	defer done()
 
-	spinner.Update("Installing Tekton Pipelines...")
-	tkv := operator.Inventory[inventory.TektonPipelinesName].GetVersion()
-	tkYamls, err := operator.Inventory[inventory.TektonPipelinesName].GetYamlFile(tkv)
+	spinner.Update("Installing...")
+
+	v := operator.Inventory[inventory.TektonPipelinesName].GetVersion()
+	yamls, err := operator.Inventory[inventory.TektonPipelinesName].GetYamlFile(v)
 	if err != nil {
-		spinner.Error(errors.Wrap(err, "Failed to get yaml file"))
-		return
-	}
-	if err := operator.InstallTektonPipelines(ctx, tkYamls["MAIN"]); err != nil {
-		spinner.Error(errors.Wrap(err, "Failed to install Tekton Pipelines"))
-		return
+		return errors.Wrap(err, "Failed to get yaml file")
 	}
 
-	// Record the version of TektonPipelines
-	operator.Records.TektonPipelines = tkv
+	if err := operator.InstallTektonPipelines(ctx, yamls["MAIN"]); err != nil {
+		return errors.Wrap(err, "Failed to install Tekton Pipelines")
+	}
 
 	spinner.Update("Checking if Tekton Pipelines is ready...")
 	if err := operator.CheckTektonPipelinesIsReady(ctx, cl); err != nil {
-		spinner.Error(errors.Wrap(err, "Failed to check Tekton Pipelines readiness"))
-		return
+		return errors.Wrap(err, "Failed to check Tekton Pipelines readiness")
 	}
 
-	spinner.Update("Installing Shipwright...")
-	swv := operator.Inventory[inventory.ShipwrightName].GetVersion()
-	swYamls, err := operator.Inventory[inventory.ShipwrightName].GetYamlFile(swv)
+	// Record the version of TektonPipelines
+	operator.Records.TektonPipelines = v
+
+	return nil
+}
+
+func installShipwright(ctx context.Context, spinner *spinners.Spinner, cl k8s.Interface, operator *common.Operator) error {
+	ctx, done := context.WithCancel(ctx)
+	defer done()
+
+	spinner.Update("Installing...")
+
+	v := operator.Inventory[inventory.ShipwrightName].GetVersion()
+	yamls, err := operator.Inventory[inventory.ShipwrightName].GetYamlFile(v)
 	if err != nil {
-		spinner.Error(errors.Wrap(err, "Failed to get yaml file"))
-		return
+		return errors.Wrap(err, "Failed to get yaml file")
 	}
 
-	if err := operator.InstallShipwright(ctx, swYamls["MAIN"]); err != nil {
-		spinner.Error(errors.Wrap(err, "Failed to install Shipwright"))
-		return
+	if err := operator.InstallShipwright(ctx, yamls["MAIN"]); err != nil {
+		return errors.Wrap(err, "Failed to install Shipwright")
 	}
 
-	// Record the version of Shipwright
-	operator.Records.Shipwright = swv
-
 	spinner.Update("Checking if Shipwright is ready...")
 	if err := operator.CheckShipwrightIsReady(ctx, cl); err != nil {
-		spinner.Error(errors.Wrap(err, "Failed to check Shipwright readiness"))
-		return
+		return errors.Wrap(err, "Failed to check Shipwright readiness")
 	}
 
-	spinner.Done()
+	// Record the version of Shipwright
+	operator.Records.Shipwright = v
+
+	return nil
 }
 
-func installCertManager(ctx context.Context, spinner *spinners.Spinner, cl *k8s.Clientset, operator *common.Operator) {
+func installCertManager(ctx context.Context, spinner *spinners.Spinner, cl k8s.Interface, operator *common.Operator) error {
 	ctx, done := context.WithCancel(ctx)
 	defer done()
 
@@ -640,28 +658,25 @@ func installCertManager(ctx context.Context, spinner *spinners.Spinner, cl *k8s.
 	v := operator.Inventory[inventory.CertManagerName].GetVersion()
 	yamls, err := operator.Inventory[inventory.CertManagerName].GetYamlFile(v)
 	if err != nil {
-		spinner.Error(errors.Wrap(err, "Failed to get yaml file"))
-		return
+		return errors.Wrap(err, "Failed to get yaml file")
 	}
 
 	if err := operator.InstallCertManager(ctx, yamls["MAIN"]); err != nil {
-		spinner.Error(errors.Wrap(err, "Failed to install Cert Manager"))
-		return
+		return errors.Wrap(err, "Failed to install Cert Manager")
 	}
 
-	// Record the version of CertManager
-	operator.Records.CertManager = v
-
 	spinner.Update("Checking if Cert Manager is ready...")
 	if err := operator.CheckCertManagerIsReady(ctx, cl); err != nil {
-		spinner.Error(errors.Wrap(err, "Failed to check Cert Manager readiness"))
-		return
+		return errors.Wrap(err, "Failed to check Cert Manager readiness")
 	}
 
-	spinner.Done()
+	// Record the version of CertManager
+	operator.Records.CertManager = v
+
+	return nil
 }
 
-func installIngress(ctx context.Context, spinner *spinners.Spinner, cl *k8s.Clientset, operator *common.Operator) {
+func installIngress(ctx context.Context, spinner *spinners.Spinner, cl k8s.Interface, operator *common.Operator) error {
 	ctx, done := context.WithCancel(ctx)
 	defer done()
 
@@ -669,28 +684,36 @@ func installIngress(ctx context.Context, spinner *spinners.Spinner, cl *k8s.Clie
 	v := operator.Inventory[inventory.IngressName].GetVersion()
 	yamls, err := operator.Inventory[inventory.IngressName].GetYamlFile(v)
 	if err != nil {
-		spinner.Error(errors.Wrap(err, "Failed to get yaml file"))
-		return
+		return errors.Wrap(err, "Failed to get yaml file")
 	}
 
 	if err := operator.InstallIngressNginx(ctx, yamls["MAIN"]); err != nil {
-		spinner.Error(errors.Wrap(err, "Failed to install Ingress"))
-		return
+		return errors.Wrap(err, "Failed to install Ingress")
 	}
 
-	// Record the version of Ingress
-	operator.Records.Ingress = v
-
 	spinner.Update("Checking if Ingress is ready...")
 	if err := operator.CheckIngressNginxIsReady(ctx, cl); err != nil {
-		spinner.Error(errors.Wrap(err, "Failed to check Ingress Nginx readiness"))
-		return
+		return errors.Wrap(err, "Failed to check Ingress Nginx readiness")
 	}
 
-	spinner.Done()
+	// Record the version of Ingress
+	operator.Records.Ingress = v
+
+	return nil
+}
+
+func installOpenFunction(ctx context.Context, spinner *spinners.Spinner, cl k8s.Interface, operator *common.Operator) error {
+	return deployOpenFunction(ctx, spinner, cl, operator, operator.InstallOpenFunction)
 }
 
-func installOpenFunction(ctx context.Context, spinner *spinners.Spinner, cl *k8s.Clientset, operator *common.Operator) {
+// deployOpenFunction installs OpenFunction with deploy, which either installs or upgrades the manifests.
+func deployOpenFunction(
+	ctx context.Context,
+	spinner *spinners.Spinner,
+	cl k8s.Interface,
+	operator *common.Operator,
+	deploy func(ctx context.Context, yamlFile string) error,
+) error {
 	ctx, done := context.WithCancel(ctx)
 	defer done()
 
@@ -698,25 +721,22 @@ func installOpenFunction(ctx context.Context, spinner *spinners.Spinner, cl *k8s
 	v := operator.Inventory[inventory.OpenFunctionName].GetVersion()
 	yamls, err := operator.Inventory[inventory.OpenFunctionName].GetYamlFile(v)
 	if err != nil {
-		spinner.Error(errors.Wrap(err, "Failed to get yaml file"))
-		return
+		return errors.Wrap(err, "Failed to get yaml file")
 	}
 
-	if err := operator.InstallOpenFunction(ctx, yamls["MAIN"]); err != nil {
-		spinner.Error(errors.Wrap(err, "Failed to install OpenFunction"))
-		return
+	if err := deploy(ctx, yamls["MAIN"]); err != nil {
+		return errors.Wrap(err, "Failed to install OpenFunction")
 	}
 
-	// Record the version of OpenFunction
-	operator.Records.OpenFunction = v
-
 	spinner.Update("Checking if OpenFunction is ready..")
 	if err := operator.CheckOpenFunctionIsReady(ctx, cl); err != nil {
-		spinner.Error(errors.Wrap(err, "Failed to check OpenFunction readiness"))
-		return
+		return errors.Wrap(err, "Failed to check OpenFunction readiness")
 	}
 
-	spinner.Done()
+	// Record the version of OpenFunction
+	operator.Records.OpenFunction = v
+
+	return nil
 }
 
 func getLatestStableVersion() (string, error) {
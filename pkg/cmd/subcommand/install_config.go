@@ -0,0 +1,166 @@
+package subcommand
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/OpenFunction/cli/pkg/cmd/util/spinners"
+	"github.com/OpenFunction/cli/pkg/components/common"
+	"github.com/OpenFunction/cli/pkg/components/config"
+	"github.com/OpenFunction/cli/pkg/components/inventory"
+	"github.com/OpenFunction/cli/pkg/components/scheduler"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	patchesNodeTmpl = "%s patches"
+)
+
+// loadConfig loads the config file of the install command,
+// its settings are used for the flags which aren't set.
+func (i *Install) loadConfig(cmd *cobra.Command) error {
+	if i.ConfigFile == "" {
+		return nil
+	}
+
+	c, err := config.Load(i.ConfigFile)
+	if err != nil {
+		return err
+	}
+	i.config = c
+
+	flags := cmd.Flags()
+	if !flags.Changed("version") && c.Version != "" {
+		i.OpenFunctionVersion = c.Version
+	}
+	if !flags.Changed("runtime") && len(c.Runtimes) != 0 {
+		i.Runtimes = c.Runtimes
+	}
+	if !flags.Changed("ingress") && c.Ingress != "" {
+		i.Ingress = c.Ingress
+	}
+	if !flags.Changed("without-ci") {
+		i.WithoutCI = c.WithoutCI
+	}
+	if !flags.Changed("region-cn") {
+		i.RegionCN = c.RegionCN
+	}
+	if !flags.Changed("from-bundle") && c.FromBundle != "" {
+		i.FromBundle = c.FromBundle
+	}
+	if !flags.Changed("image-registry") && c.ImageRegistry != "" {
+		i.ImageRegistry = c.ImageRegistry
+	}
+	if !flags.Changed("timeout") && c.Timeout != 0 {
+		i.Timeout = time.Duration(c.Timeout)
+	}
+	return nil
+}
+
+// renameNamespaces makes the operator use the namespaces the components are moved to by the config.
+func renameNamespaces(c *config.InstallConfig, operator *common.Operator) error {
+	if c == nil {
+		return nil
+	}
+	namespaces, err := c.Namespaces(common.GetComponentNamespace)
+	if err != nil {
+		return err
+	}
+	if len(namespaces) != 0 {
+		operator.RenameNamespaces(namespaces)
+	}
+	return nil
+}
+
+// effectiveConfig returns the config reproducing the installation of the inventory with the current flags.
+func (i *Install) effectiveConfig(inv map[string]inventory.Interface, operator *common.Operator) (*config.InstallConfig, error) {
+	c := config.NewInstallConfig()
+	c.Version = i.OpenFunctionVersion
+	c.Runtimes = i.Runtimes
+	c.Ingress = i.Ingress
+	c.WithoutCI = i.WithoutCI
+	c.RegionCN = i.RegionCN
+	c.FromBundle = i.FromBundle
+	c.ImageRegistry = i.ImageRegistry
+	c.Timeout = config.Duration(i.Timeout)
+
+	disabled := false
+	for name := range config.Toggleable {
+		if _, ok := inv[name]; !ok {
+			c.SetComponent(name, &config.Component{Enabled: &disabled})
+		}
+	}
+
+	for name, iv := range inv {
+		comp := &config.Component{
+			Version: iv.GetVersion(),
+		}
+		if config.Toggleable[name] {
+			enabled := true
+			comp.Enabled = &enabled
+		}
+		if name != inventory.ServingDefaultDomainName {
+			comp.Namespace = operator.Namespace(common.GetComponentNamespace(name))
+		}
+		if prev := i.config.Component(name); prev != nil {
+			comp.Timeout = prev.Timeout
+			comp.Patches = prev.Patches
+		}
+
+		// The manifests of a bundle are extracted to a temporary directory.
+		if i.FromBundle == "" && name != inventory.DaprName {
+			yamls, err := iv.GetYamlFile(comp.Version)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to get the manifests of %s", name)
+			}
+			comp.Manifests = yamls
+		}
+		c.SetComponent(name, comp)
+	}
+	return c, nil
+}
+
+// withTimeout limits the time the task of the component takes to the timeout of its config.
+func withTimeout(comp *config.Component, task scheduler.Task) scheduler.Task {
+	if comp == nil || comp.Timeout == 0 {
+		return task
+	}
+	return func(ctx context.Context, spinner *spinners.Spinner) {
+		ctx, done := context.WithTimeout(ctx, time.Duration(comp.Timeout))
+		defer done()
+		task(ctx, spinner)
+	}
+}
+
+func applyPatches(ctx context.Context, spinner *spinners.Spinner, operator *common.Operator, patches []*config.Patch) {
+	for _, p := range patches {
+		spinner.Update(fmt.Sprintf("Patching %s %s...", p.Kind, p.Name))
+
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(p.APIVersion)
+		obj.SetKind(p.Kind)
+		obj.SetNamespace(p.Namespace)
+		obj.SetName(p.Name)
+
+		pt, err := p.PatchType()
+		if err != nil {
+			spinner.Error(err)
+			return
+		}
+		data, err := p.Data()
+		if err != nil {
+			spinner.Error(err)
+			return
+		}
+
+		if err := operator.Patch(ctx, obj, pt, data); err != nil {
+			spinner.Error(errors.Wrapf(err, "Failed to patch %s %s", p.Kind, p.Name))
+			return
+		}
+	}
+
+	spinner.Done()
+}
@@ -3,8 +3,20 @@ package subcommand
 import (
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/OpenFunction/cli/pkg/components/common"
+	"github.com/OpenFunction/cli/pkg/components/fake"
+	"github.com/OpenFunction/cli/pkg/components/inventory"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	fakek8s "k8s.io/client-go/kubernetes/fake"
 )
 
 type installConditions struct {
@@ -97,3 +109,272 @@ func TestInstallCalculateConditions(t *testing.T) {
 		}
 	}
 }
+
+// newInstallClientset returns a fake clientset of a Kubernetes v1.21 cluster with the objects.
+func newInstallClientset(objs ...runtime.Object) *fakek8s.Clientset {
+	cl := fakek8s.NewSimpleClientset(objs...)
+	cl.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: "v1.21.0"}
+	return cl
+}
+
+func newInstallDeployment(ns, name string, available bool) *appsv1.Deployment {
+	status := corev1.ConditionFalse
+	if available {
+		status = corev1.ConditionTrue
+	}
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name},
+		Status: appsv1.DeploymentStatus{
+			Conditions: []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: status}},
+		},
+	}
+}
+
+// componentCalls maps the calls of the executor to the components of the inventory,
+// and returns the positions of the calls of each component.
+func componentCalls(t *testing.T, inv map[string]inventory.Interface, record *inventory.Record, calls []string) map[string][]int {
+	versions := record.ToMap(true)
+	owners := map[string]string{
+		"download-dapr " + inventory.DefaultDaprVersion:                                     inventory.DaprName,
+		"exec dapr init -k --log-as-json --runtime-version " + inventory.DefaultDaprVersion: inventory.DaprName,
+		"exec dapr uninstall -k --all":                                                      inventory.DaprName,
+		"patch ConfigMap knative-serving/config-network":                                    inventory.KourierName,
+	}
+	for name, iv := range inv {
+		for _, v := range []string{iv.GetVersion(), versions[name]} {
+			if v == "" {
+				continue
+			}
+			yamls, err := iv.GetYamlFile(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, source := range yamls {
+				for _, op := range []string{"apply", "create", "delete"} {
+					owners[op+" "+source] = name
+				}
+			}
+		}
+	}
+
+	positions := map[string][]int{}
+	for i, c := range calls {
+		if name, ok := owners[c]; ok {
+			positions[name] = append(positions[name], i)
+		}
+	}
+	return positions
+}
+
+// installSource returns the manifest of the component installed with the default versions on Kubernetes v1.21.
+func installSource(t *testing.T, name string, key string) string {
+	inv, err := inventory.GetInventoryWithServerVersion("v1.21.0", false, true, true, true, true, true, true, "v0.6.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	yamls, err := inv[name].GetYamlFile(inv[name].GetVersion())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return yamls[key]
+}
+
+func TestRunInstall(t *testing.T) {
+	kedaSource := installSource(t, inventory.KedaName, "MAIN")
+	existingKeda := newInstallDeployment(common.KedaNamespace, "keda-operator", true)
+	webhookPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: common.CertManagerNamespace, Name: "cert-manager-webhook", Labels: map[string]string{"app.kubernetes.io/name": "webhook"}},
+		Status:     corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}},
+	}
+
+	tests := []struct {
+		name string
+		// all installs all the components, otherwise only the ones of the async runtime are installed with OpenFunction.
+		all     bool
+		upgrade bool
+		atomic  bool
+		timeout time.Duration
+		objs    []runtime.Object
+		errors  map[string]error
+		// record is the inventory record before the installation.
+		record *inventory.Record
+
+		wantInstalled []string
+		wantSkipped   []string
+		wantCalls     []string
+		wantNoCalls   []string
+		wantRecord    map[string]string
+		// wantNotRecorded are the components which must be absent from the record.
+		wantNotRecorded []string
+		wantErr         string
+	}{
+		{
+			name:          "all components",
+			all:           true,
+			record:        &inventory.Record{},
+			wantInstalled: []string{inventory.DaprName, inventory.KedaName, inventory.KnativeServingName, inventory.KourierName, inventory.ServingDefaultDomainName, inventory.TektonPipelinesName, inventory.ShipwrightName, inventory.CertManagerName, inventory.IngressName, inventory.OpenFunctionName},
+			wantCalls:     []string{"get-inventory-record", "record-inventory"},
+			wantNoCalls:   []string{"begin-transaction", "rollback"},
+			wantRecord:    map[string]string{inventory.KedaName: inventory.DefaultKedaVersion, inventory.DaprName: inventory.DefaultDaprVersion, inventory.OpenFunctionName: "0.6.0"},
+		},
+		{
+			name:          "existing component skipped",
+			objs:          []runtime.Object{existingKeda},
+			record:        &inventory.Record{Keda: "2.3.0"},
+			wantInstalled: []string{inventory.DaprName, inventory.OpenFunctionName},
+			wantSkipped:   []string{inventory.KedaName},
+			wantRecord:    map[string]string{inventory.KedaName: "2.3.0", inventory.OpenFunctionName: "0.6.0"},
+		},
+		{
+			name:          "existing component upgraded",
+			upgrade:       true,
+			objs:          []runtime.Object{existingKeda},
+			record:        &inventory.Record{Keda: "2.3.0"},
+			wantInstalled: []string{inventory.DaprName, inventory.KedaName, inventory.OpenFunctionName},
+			wantRecord:    map[string]string{inventory.KedaName: inventory.DefaultKedaVersion, inventory.OpenFunctionName: "0.6.0"},
+		},
+		{
+			name:            "failure recorded",
+			errors:          map[string]error{"apply " + kedaSource: errors.New("connection refused")},
+			record:          &inventory.Record{},
+			wantInstalled:   []string{inventory.KedaName},
+			wantSkipped:     []string{inventory.OpenFunctionName},
+			wantCalls:       []string{"record-inventory"},
+			wantNotRecorded: []string{inventory.KedaName, inventory.OpenFunctionName},
+			// The components running along with Keda fail too once they are cancelled,
+			// so the error reported may be theirs.
+			wantErr: "Failed to",
+		},
+		{
+			name:            "readiness failure recorded",
+			timeout:         300 * time.Millisecond,
+			objs:            []runtime.Object{newInstallDeployment(common.KedaNamespace, "keda-metrics-apiserver", false)},
+			record:          &inventory.Record{},
+			wantInstalled:   []string{inventory.KedaName},
+			wantSkipped:     []string{inventory.OpenFunctionName},
+			wantCalls:       []string{"record-inventory"},
+			wantNotRecorded: []string{inventory.KedaName, inventory.OpenFunctionName},
+			wantErr:         "Failed to",
+		},
+		{
+			name:          "atomic failure rolled back",
+			atomic:        true,
+			errors:        map[string]error{"apply " + kedaSource: errors.New("connection refused")},
+			record:        &inventory.Record{Keda: "2.3.0"},
+			wantInstalled: []string{inventory.KedaName},
+			wantSkipped:   []string{inventory.OpenFunctionName},
+			wantCalls:     []string{"begin-transaction", "rollback"},
+			wantNoCalls:   []string{"record-inventory"},
+			wantRecord:    map[string]string{inventory.KedaName: "2.3.0"},
+			wantErr:       "the changes have been rolled back",
+		},
+		{
+			name:          "timeout",
+			atomic:        true,
+			timeout:       300 * time.Millisecond,
+			objs:          []runtime.Object{newInstallDeployment(common.KedaNamespace, "keda-metrics-apiserver", false)},
+			record:        &inventory.Record{},
+			wantInstalled: []string{inventory.KedaName},
+			wantSkipped:   []string{inventory.OpenFunctionName},
+			wantCalls:     []string{"rollback"},
+			wantNoCalls:   []string{"record-inventory"},
+			wantRecord:    map[string]string{},
+			wantErr:       "Failed to check Keda readiness",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ioStreams, _, _, _ := genericclioptions.NewTestIOStreams()
+			i := NewInstall(ioStreams)
+			i.Ingress = "nginx"
+			i.Runtimes = []string{"async"}
+			i.WithAll = tt.all
+			i.WithoutCI = !tt.all
+			i.OpenFunctionVersion = "v0.6.0"
+			i.Upgrade = tt.upgrade
+			i.Atomic = tt.atomic
+			i.Yes = true
+			i.Timeout = time.Minute
+			if tt.timeout != 0 {
+				i.Timeout = tt.timeout
+			}
+			if err := i.ValidateArgs(); err != nil {
+				t.Fatal(err)
+			}
+
+			executor := fake.NewExecutor()
+			executor.Record = tt.record
+			for call, err := range tt.errors {
+				executor.Errors[call] = err
+			}
+			operator := common.NewOperatorWithExecutor(executor, i.OpenFunctionVersion, i.Timeout, false, false)
+			operator.CheckInterval = 10 * time.Millisecond
+
+			// Cert Manager is ready once its webhook is.
+			objs := append([]runtime.Object{webhookPod}, tt.objs...)
+			err := i.runInstall(newInstallClientset(objs...), operator)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			calls := executor.Calls()
+			positions := componentCalls(t, operator.Inventory, tt.record, calls)
+			for _, name := range tt.wantInstalled {
+				if len(positions[name]) == 0 {
+					t.Errorf("%s isn't installed, calls: %q", name, calls)
+				}
+			}
+			for _, name := range tt.wantSkipped {
+				if len(positions[name]) != 0 {
+					t.Errorf("%s is installed, calls: %q", name, calls)
+				}
+			}
+			// The dependencies of a component are installed before it.
+			for name, iv := range operator.Inventory {
+				for _, dep := range iv.GetDependencies() {
+					if len(positions[name]) == 0 || len(positions[dep]) == 0 {
+						continue
+					}
+					if last := positions[dep][len(positions[dep])-1]; last > positions[name][0] {
+						t.Errorf("%s is installed before its dependency %s, calls: %q", name, dep, calls)
+					}
+				}
+			}
+
+			called := map[string]bool{}
+			for _, c := range calls {
+				called[c] = true
+			}
+			for _, c := range tt.wantCalls {
+				if !called[c] {
+					t.Errorf("%q isn't called, calls: %q", c, calls)
+				}
+			}
+			for _, c := range tt.wantNoCalls {
+				if called[c] {
+					t.Errorf("%q is called, calls: %q", c, calls)
+				}
+			}
+
+			record := executor.Record.ToMap(true)
+			for name, v := range tt.wantRecord {
+				if record[name] != v {
+					t.Errorf("record of %s = %q, want %q", name, record[name], v)
+				}
+			}
+			for _, name := range tt.wantNotRecorded {
+				if v, ok := record[name]; ok {
+					t.Errorf("%s is recorded as %q, want it absent", name, v)
+				}
+			}
+			if len(tt.wantRecord) == 0 && len(tt.wantNotRecorded) == 0 && len(record) != 0 {
+				t.Errorf("record = %v, want it empty", record)
+			}
+		})
+	}
+}
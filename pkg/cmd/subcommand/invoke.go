@@ -0,0 +1,484 @@
+package subcommand
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/OpenFunction/cli/pkg/cmd/util"
+	cc "github.com/OpenFunction/cli/pkg/cmd/util/client"
+	openfunction "github.com/openfunction/apis/core/v1beta1"
+	client "github.com/openfunction/pkg/client/clientset/versioned"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/dynamic"
+	k8s "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+const (
+	ceModeNone       = ""
+	ceModeBinary     = "binary"
+	ceModeStructured = "structured"
+
+	ceSpecVersion        = "1.0"
+	ceStructuredJSONType = "application/cloudevents+json"
+
+	// The port the function listens on if the function doesn't specify one.
+	defaultFunctionPort = 8080
+
+	invokeDialTimeout = 5 * time.Second
+)
+
+// Invoke is the commandline for 'invoke' sub command
+type Invoke struct {
+	genericclioptions.IOStreams
+
+	Name     string
+	Method   string
+	Path     string
+	Headers  []string
+	Data     string
+	CEMode   string
+	CEType   string
+	CESource string
+	CEID     string
+	Timeout  time.Duration
+
+	namespace      string
+	config         *rest.Config
+	functionClient client.Interface
+	clientSet      k8s.Interface
+	dynamicClient  dynamic.Interface
+
+	request *invokeRequest
+}
+
+// invokeRequest is the request sent to a function, it can be sent to several addresses.
+type invokeRequest struct {
+	method string
+	path   string
+	header http.Header
+	body   []byte
+}
+
+const (
+	invokeExample = `
+# Send a GET request to the function sample
+ofn invoke sample
+
+# Send the content of payload.json to the function sample
+ofn invoke sample -d @payload.json
+
+# Send a PUT request with a header to the path /items of the function sample
+ofn invoke sample -X PUT --path /items -H "Authorization: Bearer token" -d '{"name": "item"}'
+
+# Send a CloudEvent in binary mode
+ofn invoke sample --ce-mode binary --ce-type dev.openfunction.sample --ce-source ofn -d '{"message": "hello"}'
+
+# Send a CloudEvent in structured mode, reading the data from stdin
+echo '{"message": "hello"}' | ofn invoke sample --ce-mode structured --ce-type dev.openfunction.sample -d @-
+`
+)
+
+// NewInvoke returns an initialized Invoke instance
+func NewInvoke(ioStreams genericclioptions.IOStreams) *Invoke {
+	return &Invoke{
+		IOStreams: ioStreams,
+	}
+}
+
+func NewCmdInvoke(cf *genericclioptions.ConfigFlags, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	i := NewInvoke(ioStreams)
+	cmd := &cobra.Command{
+		Use:                   "invoke NAME [-X METHOD] [-H HEADER]... [-d DATA|@FILE]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Invoke a function over HTTP or with a CloudEvent",
+		Long: `
+Invoke a function over HTTP or with a CloudEvent, then print the status, the headers and the body of the response.
+
+The function is reached with the URL of its Knative Service, then with its route of the OpenFunction domain.
+If the function has none of them or they can't be reached, a port of a running pod of the function is forwarded to a local port.
+The next address is only tried if the connection to an address fails, so that a request is never sent twice.
+`,
+		Example: invokeExample,
+		Args:    cobra.ExactArgs(1),
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return i.preRun(cf)
+		},
+
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(i.Complete(cmd, args))
+			util.CheckErr(i.Run())
+		},
+	}
+
+	cmd.Flags().StringVarP(&i.Method, "method", "X", "", "The HTTP method of the request, defaults to POST if there is data or a CloudEvent to send, otherwise GET.")
+	cmd.Flags().StringVar(&i.Path, "path", "/", "The path of the request.")
+	cmd.Flags().StringArrayVarP(&i.Headers, "header", "H", nil, `The headers of the request in the form of "Name: Value". Can be repeated.`)
+	cmd.Flags().StringVarP(&i.Data, "data", "d", "", "The body of the request, use @FILE to read it from a file or @- to read it from stdin.")
+	cmd.Flags().StringVar(&i.CEMode, "ce-mode", ceModeNone, `Send the data as a CloudEvent, one of "binary", "structured". Defaults to "binary" if --ce-type is set.`)
+	cmd.Flags().StringVar(&i.CEType, "ce-type", "", "The type of the CloudEvent.")
+	cmd.Flags().StringVar(&i.CESource, "ce-source", "ofn", "The source of the CloudEvent.")
+	cmd.Flags().StringVar(&i.CEID, "ce-id", "", "The id of the CloudEvent, defaults to a random UUID.")
+	cmd.Flags().DurationVar(&i.Timeout, "timeout", 30*time.Second, "The length of time to wait for the response.")
+	return cmd
+}
+
+func (i *Invoke) preRun(cf *genericclioptions.ConfigFlags) error {
+	config, clientSet, err := cc.NewKubeConfigClient(cf)
+	if err != nil {
+		return err
+	}
+	i.clientSet = clientSet
+	i.config = rest.CopyConfig(config)
+
+	i.dynamicClient, err = dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if err := cc.SetConfigDefaults(config); err != nil {
+		return err
+	}
+	i.functionClient, err = client.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	i.namespace, _, err = cf.ToRawKubeConfigLoader().Namespace()
+	return err
+}
+
+func (i *Invoke) Complete(cmd *cobra.Command, args []string) error {
+	i.Name = args[0]
+
+	if i.CEMode == ceModeNone && i.CEType != "" {
+		i.CEMode = ceModeBinary
+	}
+	if i.CEMode != ceModeNone && i.CEMode != ceModeBinary && i.CEMode != ceModeStructured {
+		return util.UsageErrorf(cmd, `invalid --ce-mode %s, must be "binary" or "structured"`, i.CEMode)
+	}
+	if i.CEMode != ceModeNone && i.CEType == "" {
+		return util.UsageErrorf(cmd, "--ce-type is required to send a CloudEvent")
+	}
+
+	data, err := readInvokeData(i.Data, i.In)
+	if err != nil {
+		return err
+	}
+
+	i.request, err = i.newInvokeRequest(data)
+	if err != nil {
+		return util.UsageErrorf(cmd, err.Error())
+	}
+	return nil
+}
+
+func (i *Invoke) Run() error {
+	ctx, done := context.WithTimeout(context.Background(), i.Timeout)
+	defer done()
+
+	fn, err := i.functionClient.CoreV1beta1().Functions(i.namespace).Get(ctx, i.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	httpClient := newInvokeHTTPClient()
+	for _, address := range i.functionAddresses(ctx, fn) {
+		resp, body, err := i.request.send(ctx, httpClient, address)
+		if err != nil {
+			// The request may have been delivered unless the address couldn't be reached.
+			if !isUnreachable(err) {
+				return err
+			}
+			fmt.Fprintf(i.ErrOut, "Failed to reach %s: %s\n", address, err)
+			continue
+		}
+		printResponse(i.Out, resp, body)
+		return nil
+	}
+
+	fmt.Fprintf(i.ErrOut, "Forwarding a local port to function %s\n", fn.Name)
+	address, stop, err := i.portForward(ctx, fn)
+	if err != nil {
+		return errors.Wrapf(err, "failed to forward a local port to function %s", fn.Name)
+	}
+	defer stop()
+
+	resp, body, err := i.request.send(ctx, httpClient, address)
+	if err != nil {
+		return err
+	}
+	printResponse(i.Out, resp, body)
+	return nil
+}
+
+// functionAddresses returns the addresses of fn in the order they are tried,
+// the URL of its Knative Service then its route of the OpenFunction domain.
+func (i *Invoke) functionAddresses(ctx context.Context, fn *openfunction.Function) []string {
+	var addresses []string
+	if fn.Status.Serving != nil && fn.Status.Serving.ResourceRef != "" {
+		serving, err := i.functionClient.CoreV1beta1().Servings(fn.Namespace).Get(ctx, fn.Status.Serving.ResourceRef, metav1.GetOptions{})
+		if err == nil && serving.Status.ResourceRef[knativeServiceRef] != "" {
+			ksvc, err := i.dynamicClient.Resource(knativeServiceGVR).Namespace(fn.Namespace).Get(ctx, serving.Status.ResourceRef[knativeServiceRef], metav1.GetOptions{})
+			if err == nil {
+				if u, _, _ := unstructured.NestedString(ksvc.Object, "status", "url"); u != "" {
+					addresses = append(addresses, u)
+				}
+			}
+		}
+	}
+	if fn.Status.URL != "" {
+		addresses = append(addresses, fn.Status.URL)
+	}
+	return addresses
+}
+
+// portForward forwards a random local port to the port of a running pod of fn,
+// and returns the local address and the function stopping the forwarding.
+func (i *Invoke) portForward(ctx context.Context, fn *openfunction.Function) (string, func(), error) {
+	if fn.Status.Serving == nil || fn.Status.Serving.ResourceRef == "" {
+		return "", nil, errors.New("the function has no serving")
+	}
+
+	pods, err := i.clientSet.CoreV1().Pods(fn.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", servingLabel, fn.Status.Serving.ResourceRef),
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	pod := findReadyPod(pods.Items)
+	if pod == nil {
+		return "", nil, errors.New("the function has no running pod, it may have been scaled to zero")
+	}
+
+	port := int32(defaultFunctionPort)
+	if fn.Spec.Port != nil {
+		port = *fn.Spec.Port
+	}
+
+	transport, upgrader, err := spdy.RoundTripperFor(i.config)
+	if err != nil {
+		return "", nil, err
+	}
+	req := i.clientSet.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("portforward")
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, req.URL())
+
+	stopCh, readyCh := make(chan struct{}), make(chan struct{})
+	fw, err := portforward.NewOnAddresses(dialer, []string{"localhost"}, []string{fmt.Sprintf("0:%d", port)}, stopCh, readyCh, ioutil.Discard, i.ErrOut)
+	if err != nil {
+		return "", nil, err
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- fw.ForwardPorts()
+	}()
+
+	select {
+	case <-readyCh:
+	case err := <-errCh:
+		return "", nil, err
+	case <-ctx.Done():
+		close(stopCh)
+		return "", nil, ctx.Err()
+	}
+
+	ports, err := fw.GetPorts()
+	if err != nil || len(ports) == 0 {
+		close(stopCh)
+		return "", nil, errors.Errorf("failed to get the forwarded port: %v", err)
+	}
+	return fmt.Sprintf("http://localhost:%d", ports[0].Local), func() { close(stopCh) }, nil
+}
+
+// newInvokeRequest returns the request sending data, as a CloudEvent in the CloudEvent mode.
+func (i *Invoke) newInvokeRequest(data []byte) (*invokeRequest, error) {
+	header := http.Header{}
+	for _, h := range i.Headers {
+		kv := strings.SplitN(h, ":", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, errors.Errorf(`invalid header %s, must be in the form of "Name: Value"`, h)
+		}
+		header.Add(strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1]))
+	}
+	if header.Get("Content-Type") == "" && len(data) != 0 {
+		if json.Valid(data) {
+			header.Set("Content-Type", "application/json")
+		} else {
+			header.Set("Content-Type", "text/plain")
+		}
+	}
+
+	id := i.CEID
+	if id == "" {
+		id = string(uuid.NewUUID())
+	}
+
+	body := data
+	switch i.CEMode {
+	case ceModeBinary:
+		header.Set("Ce-Specversion", ceSpecVersion)
+		header.Set("Ce-Id", id)
+		header.Set("Ce-Type", i.CEType)
+		header.Set("Ce-Source", i.CESource)
+	case ceModeStructured:
+		event := map[string]interface{}{
+			"specversion": ceSpecVersion,
+			"id":          id,
+			"type":        i.CEType,
+			"source":      i.CESource,
+		}
+		if len(data) != 0 {
+			contentType := header.Get("Content-Type")
+			event["datacontenttype"] = contentType
+			if strings.Contains(contentType, "json") && json.Valid(data) {
+				event["data"] = json.RawMessage(data)
+			} else {
+				event["data"] = string(data)
+			}
+		}
+
+		var err error
+		if body, err = json.Marshal(event); err != nil {
+			return nil, err
+		}
+		header.Set("Content-Type", ceStructuredJSONType)
+	}
+
+	method := strings.ToUpper(i.Method)
+	if method == "" {
+		method = http.MethodGet
+		if len(data) != 0 || i.CEMode != ceModeNone {
+			method = http.MethodPost
+		}
+	}
+
+	return &invokeRequest{
+		method: method,
+		path:   i.Path,
+		header: header,
+		body:   body,
+	}, nil
+}
+
+// send sends the request to address and returns the response along with its body.
+func (r *invokeRequest) send(ctx context.Context, httpClient *http.Client, address string) (*http.Response, []byte, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, nil, err
+	}
+	if p := strings.TrimPrefix(r.path, "/"); p != "" {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + p
+	} else if u.Path == "" {
+		u.Path = "/"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, r.method, u.String(), bytes.NewReader(r.body))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header = r.header.Clone()
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, body, nil
+}
+
+// isUnreachable returns true if err is a DNS or a dial error, the request hasn't been sent then.
+func isUnreachable(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+func newInvokeHTTPClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: invokeDialTimeout}).DialContext
+	return &http.Client{Transport: transport}
+}
+
+// readInvokeData returns data, or the content of the file if data is @FILE, or the content of in if data is @-.
+func readInvokeData(data string, in io.Reader) ([]byte, error) {
+	switch {
+	case data == "@-":
+		return ioutil.ReadAll(in)
+	case strings.HasPrefix(data, "@"):
+		b, err := ioutil.ReadFile(strings.TrimPrefix(data, "@"))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read the data")
+		}
+		return b, nil
+	default:
+		return []byte(data), nil
+	}
+}
+
+func findReadyPod(pods []corev1.Pod) *corev1.Pod {
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+		for _, c := range pod.Status.Conditions {
+			if c.Type == corev1.PodReady && c.Status == corev1.ConditionTrue {
+				return pod
+			}
+		}
+	}
+	return nil
+}
+
+func printResponse(w io.Writer, resp *http.Response, body []byte) {
+	fmt.Fprintf(w, "%s %s\n", resp.Proto, resp.Status)
+
+	names := make([]string, 0, len(resp.Header))
+	for name := range resp.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, v := range resp.Header[name] {
+			fmt.Fprintf(w, "%s: %s\n", name, v)
+		}
+	}
+
+	fmt.Fprintln(w)
+	w.Write(body)
+	if len(body) != 0 && !bytes.HasSuffix(body, []byte("\n")) {
+		fmt.Fprintln(w)
+	}
+}
@@ -0,0 +1,138 @@
+package subcommand
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestInvokeRequest(t *testing.T) {
+	type received struct {
+		method string
+		path   string
+		header http.Header
+		body   string
+	}
+
+	tests := []struct {
+		name   string
+		invoke *Invoke
+		data   string
+		want   received
+	}{
+		{
+			name:   "get",
+			invoke: &Invoke{Path: "/"},
+			want:   received{method: http.MethodGet, path: "/base"},
+		},
+		{
+			name:   "post json with header",
+			invoke: &Invoke{Path: "/items", Headers: []string{"Authorization: Bearer token"}},
+			data:   `{"name":"item"}`,
+			want: received{
+				method: http.MethodPost,
+				path:   "/base/items",
+				header: http.Header{"Authorization": {"Bearer token"}, "Content-Type": {"application/json"}},
+				body:   `{"name":"item"}`,
+			},
+		},
+		{
+			name:   "binary cloudevent",
+			invoke: &Invoke{Path: "/", CEMode: ceModeBinary, CEType: "sample", CESource: "ofn", CEID: "1"},
+			data:   "hello",
+			want: received{
+				method: http.MethodPost,
+				path:   "/base",
+				header: http.Header{
+					"Ce-Specversion": {"1.0"},
+					"Ce-Id":          {"1"},
+					"Ce-Type":        {"sample"},
+					"Ce-Source":      {"ofn"},
+					"Content-Type":   {"text/plain"},
+				},
+				body: "hello",
+			},
+		},
+		{
+			name:   "structured cloudevent",
+			invoke: &Invoke{Method: "put", Path: "/", CEMode: ceModeStructured, CEType: "sample", CESource: "ofn", CEID: "1"},
+			data:   `{"message":"hello"}`,
+			want: received{
+				method: http.MethodPut,
+				path:   "/base",
+				header: http.Header{"Content-Type": {ceStructuredJSONType}},
+				body:   `{"data":{"message":"hello"},"datacontenttype":"application/json","id":"1","source":"ofn","specversion":"1.0","type":"sample"}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got received
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				got = received{method: r.Method, path: r.URL.Path, header: r.Header, body: string(body)}
+			}))
+			defer srv.Close()
+
+			req, err := tt.invoke.newInvokeRequest([]byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := req.send(context.Background(), newInvokeHTTPClient(), srv.URL+"/base"); err != nil {
+				t.Fatal(err)
+			}
+
+			if got.method != tt.want.method || got.path != tt.want.path || got.body != tt.want.body {
+				t.Errorf("got %s %s %q, want %s %s %q", got.method, got.path, got.body, tt.want.method, tt.want.path, tt.want.body)
+			}
+			for name := range tt.want.header {
+				if got.header.Get(name) != tt.want.header.Get(name) {
+					t.Errorf("header %s = %q, want %q", name, got.header.Get(name), tt.want.header.Get(name))
+				}
+			}
+			if tt.invoke.CEMode == ceModeStructured && !json.Valid([]byte(got.body)) {
+				t.Errorf("the structured CloudEvent isn't valid JSON: %s", got.body)
+			}
+		})
+	}
+}
+
+func TestIsUnreachable(t *testing.T) {
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	dropped := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+	}))
+	defer dropped.Close()
+
+	tests := []struct {
+		name    string
+		address string
+		want    bool
+	}{
+		{name: "connection refused", address: closed.URL, want: true},
+		{name: "unknown host", address: "http://sample.invalid", want: true},
+		{name: "connection dropped after the request", address: dropped.URL, want: false},
+	}
+
+	req, err := (&Invoke{Method: http.MethodPost}).newInvokeRequest([]byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := req.send(context.Background(), newInvokeHTTPClient(), tt.address)
+			if err == nil {
+				t.Fatal("want an error")
+			}
+			if got := isUnreachable(err); got != tt.want {
+				t.Errorf("isUnreachable(%v) = %t, want %t", err, got, tt.want)
+			}
+		})
+	}
+}
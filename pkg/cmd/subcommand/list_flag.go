@@ -10,8 +10,10 @@ type listFlag struct {
 	FieldSelector string
 	AllNamespaces bool
 	Watch         bool
-	Limit         int64
-	Timeout       int64
+	// OutputWatchEvents prints the type of the watch events with the objects.
+	OutputWatchEvents bool
+	Limit             int64
+	Timeout           int64
 }
 
 func (l *listFlag) addListFlag(cmd *cobra.Command) {
@@ -21,6 +23,7 @@ func (l *listFlag) addListFlag(cmd *cobra.Command) {
 	cmd.Flags().Int64VarP(&l.Timeout, "timeout", "", l.Timeout, "Timeout for the list/watch call")
 	cmd.Flags().Int64VarP(&l.Limit, "limit", "", l.Limit, "limit is a maximum number of responses to return for a list call")
 	cmd.Flags().BoolVarP(&l.Watch, "watch", "w", l.Watch, "After listing the requested object, watch for changes. Uninitialized objects are excluded if no object name is provided")
+	cmd.Flags().BoolVar(&l.OutputWatchEvents, "output-watch-events", l.OutputWatchEvents, "Output watch event objects when --watch is used. Existing objects are output as initial ADDED events")
 }
 
 func (l *listFlag) ToOptions() metav1.ListOptions {
@@ -37,7 +40,6 @@ func (l *listFlag) ToOptions() metav1.ListOptions {
 	options.TimeoutSeconds = &timeout
 
 	options.Limit = l.Limit
-	options.Watch = l.Watch
 
 	return options
 }
@@ -20,7 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io"
+	"time"
 
 	"github.com/OpenFunction/cli/pkg/cmd/util"
 	cc "github.com/OpenFunction/cli/pkg/cmd/util/client"
@@ -43,10 +43,23 @@ type Logs struct {
 	containerName string
 	namespace     string
 
-	Follow bool
+	Phase      string
+	Follow     bool
+	Since      time.Duration
+	Tail       int64
+	Timestamps bool
+	Previous   bool
+	Output     string
+	Grep       string
+	Level      string
+
+	BuildHistory bool
+	BuildRun     string
+
+	formatter *logFormatter
 
 	functionClient client.Interface
-	clientSet      *k8s.Clientset
+	clientSet      k8s.Interface
 	swClient       swclient.Interface
 }
 
@@ -61,7 +74,13 @@ func NewCmdLogs(cf *genericclioptions.ConfigFlags, ioStreams genericclioptions.I
 		Use:                   `logs [OPTIONS] FUNCTION_NAME [CONTAINER_NAME]`,
 		DisableFlagsInUseLine: true,
 		Short:                 "Get the logs from the build and serving pods created by the function",
-		Long:                  `Get the logs from the build and serving pods created by the function`,
+		Long: `Get the logs from the build and serving pods created by the function.
+
+The logs of the build are printed step by step in the order the steps run, with the exit code of each step.
+The logs of the containers of the serving pods are streamed concurrently, each line is prefixed with the name of its pod and container.
+With --follow the pods created later, e.g. when the function is scaled out, are streamed too.
+The past builds of the function are listed with --build-history, and the logs of one of them are printed with --build-run
+until its pod is cleaned up.`,
 		Example: `
   # Get tht logs from all container in the build and serving pods created by the function whose name is 'demo-function'
   ofn logs demo-function
@@ -71,6 +90,22 @@ func NewCmdLogs(cf *genericclioptions.ConfigFlags, ioStreams genericclioptions.I
 
   # Begin streaming the logs from the 'function' container in the build and serving pods created by the function whose name is 'demo-function'
   ofn logs -f demo-function
+
+  # Get the logs of the steps of the build of the function whose name is 'demo-function'
+  ofn logs demo-function --phase build
+
+  # Get the errors of the function whose name is 'demo-function' written in JSON, formatted for reading
+  ofn logs demo-function -o pretty --level=error
+
+  # Get the logs as JSON objects with their pod, container and phase, and filter the messages of the lines with jq
+  ofn logs demo-function -o json --grep=timeout | jq -r '.line | fromjson | .msg'
+
+  # List the past builds of the function whose name is 'demo-function', then get the logs of one of them
+  ofn logs demo-function --build-history
+  ofn logs demo-function --build-run demo-function-buildrun-x7k2p
+
+  # Get the last 20 lines of the logs written in the last hour, with their timestamps
+  ofn logs demo-function --since=1h --tail=20 --timestamps
 `,
 		PreRunE: func(cmd *cobra.Command, args []string) (err error) {
 			return l.preRun(cf, args)
@@ -79,7 +114,17 @@ func NewCmdLogs(cf *genericclioptions.ConfigFlags, ioStreams genericclioptions.I
 			util.CheckErr(l.run())
 		},
 	}
+	cmd.Flags().StringVar(&l.Phase, "phase", l.Phase, "Only print the logs of the build or the serving of the function, one of: build|serving")
 	cmd.Flags().BoolVarP(&l.Follow, "follow", "f", l.Follow, "Specify if the logs should be streamed")
+	cmd.Flags().DurationVar(&l.Since, "since", l.Since, "Only return logs newer than a relative duration like 5s, 2m, or 3h. Defaults to all logs")
+	cmd.Flags().Int64Var(&l.Tail, "tail", -1, "Lines of recent log file to display, -1 shows all log lines")
+	cmd.Flags().BoolVar(&l.Timestamps, "timestamps", l.Timestamps, "Include timestamps on each line in the log output")
+	cmd.Flags().BoolVarP(&l.Previous, "previous", "p", l.Previous, "If true, print the logs for the previous instance of the containers if they exist")
+	cmd.Flags().StringVarP(&l.Output, "output", "o", logOutputRaw, "Output format, one of: raw|pretty|json. pretty formats the lines written in JSON, json prints each line in a JSON object with its pod, container and phase")
+	cmd.Flags().StringVar(&l.Grep, "grep", l.Grep, "Only print the lines matching the regular expression")
+	cmd.Flags().StringVar(&l.Level, "level", l.Level, "Only print the lines with at least the level, one of: trace|debug|info|warn|error|fatal. The level is read from the lines written in JSON, in the logfmt format or with klog")
+	cmd.Flags().BoolVar(&l.BuildHistory, "build-history", l.BuildHistory, "List the past builds of the function with their state and duration, and whether their logs are still available")
+	cmd.Flags().StringVar(&l.BuildRun, "build-run", l.BuildRun, "Only print the logs of the build run, or of the build run of the builder, listed with --build-history")
 	return cmd
 }
 
@@ -107,6 +152,16 @@ func (l *Logs) preRun(cf *genericclioptions.ConfigFlags, args []string) error {
 	if len(args) < 1 {
 		return errors.New("missing argument: FUNCTION_NAME")
 	}
+	if l.Phase != "" && l.Phase != waitForBuild && l.Phase != waitForServing {
+		return fmt.Errorf("invalid --phase %q, must be one of: %s|%s", l.Phase, waitForBuild, waitForServing)
+	}
+	if l.BuildHistory && l.BuildRun != "" {
+		return errors.New("--build-history and --build-run can't be used together")
+	}
+	l.formatter, err = newLogFormatter(l.Output, l.Grep, l.Level, l.Timestamps)
+	if err != nil {
+		return err
+	}
 	l.functionName = args[0]
 	if len(args) > 1 {
 		l.containerName = args[1]
@@ -116,51 +171,33 @@ func (l *Logs) preRun(cf *genericclioptions.ConfigFlags, args []string) error {
 
 func (l *Logs) run() error {
 	ctx := context.Background()
+	if l.BuildHistory {
+		return l.printBuildHistory(ctx)
+	}
+	if l.BuildRun != "" {
+		return l.logsForBuildRun(ctx, l.BuildRun)
+	}
+
 	f, err := l.functionClient.CoreV1beta1().Functions(l.namespace).Get(ctx, l.functionName, metav1.GetOptions{})
 	if err != nil {
 		return err
 	}
 
 	// Build stage
-	if f.Status.Build != nil && f.Status.Build.State != openfunction.Skipped {
-		builderRef := f.Status.Build.ResourceRef
-		if builderRef != "" {
-			// get openfunction builder ref
-			builder, err := l.functionClient.CoreV1beta1().Builders(f.Namespace).Get(ctx, builderRef, metav1.GetOptions{})
-			if err != nil {
-				statusError, ok := err.(*k8serrors.StatusError)
-				// builder has been cleaned up
-				if !ok || statusError.Status().Code != 404 {
-					return err
-				}
-			}
-			// get shipwright builder-buildrun
-			swbuildrunRef := builder.Status.ResourceRef["shipwright.io/buildRun"]
-			if swbuildrunRef != "" {
-				swBuildRun, err := l.swClient.ShipwrightV1alpha1().BuildRuns(f.Namespace).Get(ctx, swbuildrunRef, metav1.GetOptions{})
-				if err != nil {
-					statusError, ok := err.(*k8serrors.StatusError)
-					// buildrun has been cleaned up
-					if !ok || statusError.Status().Code != 404 {
-						return err
-					}
-				}
-				err = l.logsForPods(ctx, metav1.ListOptions{LabelSelector: fmt.Sprintf("buildrun.shipwright.io/name=%s", swBuildRun.Name)})
-				if err != nil {
-					return err
-				}
-			}
+	if l.Phase != waitForServing && f.Status.Build != nil && f.Status.Build.State != openfunction.Skipped {
+		if err := l.logsForLatestBuild(ctx, f.Status.Build.ResourceRef); err != nil {
+			return err
 		}
 	}
 
 	// Serving stage
-	if f.Status.Serving != nil && f.Status.Serving.State != openfunction.Skipped {
+	if l.Phase != waitForBuild && f.Status.Serving != nil && f.Status.Serving.State != openfunction.Skipped {
 		servingRef := f.Status.Serving.ResourceRef
 		if servingRef != "" {
 			if l.containerName == "" {
 				l.containerName = "function"
 			}
-			err := l.logsForPods(ctx, metav1.ListOptions{LabelSelector: fmt.Sprintf("openfunction.io/serving=%s", servingRef)})
+			err := logsForPods(ctx, l.newPodLogs(waitForServing), fmt.Sprintf("%s=%s", servingLabel, servingRef), l.Follow)
 			if err != nil {
 				return err
 			}
@@ -170,45 +207,105 @@ func (l *Logs) run() error {
 	return nil
 }
 
-func (l *Logs) logsForPods(ctx context.Context, listOpt metav1.ListOptions) error {
-	// retrieves pod list according to the options
-	podInterface := l.clientSet.CoreV1().Pods(l.namespace)
-	readerList := make([]io.Reader, 0, 5)
-	podList, err := podInterface.List(ctx, listOpt)
+// logsForLatestBuild prints the logs of the build of the builder, or a notice if they have expired
+// as the builder, its build run or its pod has been cleaned up.
+func (l *Logs) logsForLatestBuild(ctx context.Context, builderRef string) error {
+	if builderRef == "" {
+		return nil
+	}
+	builder, err := l.functionClient.CoreV1beta1().Builders(l.namespace).Get(ctx, builderRef, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		l.printExpired(fmt.Sprintf("The logs of the build have expired as builder %s has been cleaned up", builderRef))
+		return nil
+	}
 	if err != nil {
 		return err
 	}
 
-	// append stream of each container
-	for _, pod := range podList.Items {
-		var logReader io.ReadCloser
-		if l.containerName != "" {
-			logReader, err = podInterface.GetLogs(pod.Name, &corev1.PodLogOptions{Follow: l.Follow, Container: l.containerName}).Stream(ctx)
-			// the pod may be deleted due to scale, so we should exclude NotFound errors
-			if err != nil && !k8serrors.IsNotFound(err) {
-				return err
-			}
-			defer logReader.Close()
-			readerList = append(readerList, logReader)
-		} else {
-			for _, container := range pod.Spec.Containers {
-				logReader, err = podInterface.GetLogs(pod.Name, &corev1.PodLogOptions{Follow: l.Follow, Container: container.Name}).Stream(ctx)
-				// the pod may be deleted due to scale, so we should exclude NotFound errors
-				if err != nil && !k8serrors.IsNotFound(err) {
-					return err
-				}
-
-				defer logReader.Close()
-				readerList = append(readerList, logReader)
-			}
+	_, buildRun := getBuilderResourceRef(builder.Status.ResourceRef)
+	if buildRun == "" {
+		return nil
+	}
+	if _, err := l.swClient.ShipwrightV1alpha1().BuildRuns(l.namespace).Get(ctx, buildRun, metav1.GetOptions{}); k8serrors.IsNotFound(err) {
+		l.printExpired(fmt.Sprintf("The logs of the build have expired as build run %s has been cleaned up", buildRun))
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	err = l.logsForBuild(ctx, builder.Name, buildRun)
+	if err == errBuildLogsExpired {
+		l.printExpired(fmt.Sprintf("The logs of the build have expired as the pod of build run %s has been cleaned up", buildRun))
+		return nil
+	}
+	return err
+}
+
+func (l *Logs) printExpired(msg string) {
+	fmt.Fprintln(l.ErrOut, util.YellowItalic(msg+", the past builds are listed with --build-history"))
+}
+
+// logsForBuild prints the logs of the steps of the pod of the build run of the builder.
+// The pod is followed until it completes, unless the build has already finished.
+func (l *Logs) logsForBuild(ctx context.Context, builderName string, buildRun string) error {
+	finished := builderFinished(ctx, l.functionClient, l.namespace, builderName)
+	done, err := finished()
+	if err != nil {
+		return err
+	}
+	follow := l.Follow && !done
+
+	p := l.newPodLogs(waitForBuild)
+	pod, err := buildRunPod(ctx, p.pods, buildRun, follow, finished)
+	if err != nil {
+		return err
+	}
+	// The pod of a finished build is cleaned up with its TaskRun.
+	if pod == nil {
+		if done {
+			return errBuildLogsExpired
+		}
+		return nil
+	}
+	if !follow {
+		p.options = func(container string) *corev1.PodLogOptions {
+			options := l.podLogOptions(container)
+			options.Follow = false
+			return options
 		}
+	}
+	return streamBuildSteps(ctx, p, pod)
+}
+
+// builderFinished returns a function telling whether the build of the builder has ended.
+func builderFinished(ctx context.Context, fc client.Interface, namespace string, builderName string) func() (bool, error) {
+	return func() (bool, error) {
+		builder, err := fc.CoreV1beta1().Builders(namespace).Get(ctx, builderName, metav1.GetOptions{})
 		if err != nil {
-			return err
+			return false, err
 		}
+		return builder.Status.State == openfunction.Succeeded || containsState(buildFailedStates, builder.Status.State), nil
 	}
+}
 
-	reader := io.MultiReader(readerList...)
-	io.Copy(l.IOStreams.Out, reader)
+func (l *Logs) newPodLogs(phase string) *podLogs {
+	return newPodLogs(l.clientSet.CoreV1().Pods(l.namespace), l.containerName, phase, l.podLogOptions, l.formatter, l.Out, l.ErrOut)
+}
 
-	return err
+func (l *Logs) podLogOptions(container string) *corev1.PodLogOptions {
+	options := &corev1.PodLogOptions{
+		Container:  container,
+		Follow:     l.Follow,
+		Timestamps: l.Timestamps,
+		Previous:   l.Previous,
+	}
+	if l.Since != 0 {
+		seconds := int64(l.Since.Round(time.Second).Seconds())
+		options.SinceSeconds = &seconds
+	}
+	if l.Tail >= 0 {
+		tail := l.Tail
+		options.TailLines = &tail
+	}
+	return options
 }
@@ -0,0 +1,223 @@
+package subcommand
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/OpenFunction/cli/pkg/cmd/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	logOutputRaw    = "raw"
+	logOutputPretty = "pretty"
+	logOutputJSON   = "json"
+)
+
+// The levels of the logs, from the least to the most severe.
+var logLevels = []string{"trace", "debug", "info", "warn", "error", "fatal"}
+
+var (
+	// klogLine matches the header of the lines written with klog, e.g. 'I0610 08:21:42.123456'.
+	klogLine = regexp.MustCompile(`^([IWEF])\d{4} `)
+	// logfmtLevel matches the level of the lines in the logfmt format, e.g. 'level=info'.
+	logfmtLevel = regexp.MustCompile(`\blevel=("?)(\w+)`)
+)
+
+// logLine is a line of the logs of a container, printed as is with -o json.
+type logLine struct {
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+	Phase     string `json:"phase"`
+	Timestamp string `json:"timestamp,omitempty"`
+	Line      string `json:"line"`
+}
+
+// logFormatter filters the lines of the logs and formats them for the output.
+type logFormatter struct {
+	output     string
+	grep       *regexp.Regexp
+	minLevel   int
+	timestamps bool
+}
+
+// newLogFormatter returns a logFormatter printing the lines which match grep and whose level is at least level if they aren't empty.
+func newLogFormatter(output string, grep string, level string, timestamps bool) (*logFormatter, error) {
+	f := &logFormatter{output: output, minLevel: -1, timestamps: timestamps}
+	switch output {
+	case logOutputRaw, logOutputPretty, logOutputJSON:
+	default:
+		return nil, errors.Errorf("invalid output %q, must be one of: %s|%s|%s", output, logOutputRaw, logOutputPretty, logOutputJSON)
+	}
+
+	if grep != "" {
+		var err error
+		if f.grep, err = regexp.Compile(grep); err != nil {
+			return nil, errors.Wrapf(err, "invalid --grep %q", grep)
+		}
+	}
+	if level != "" {
+		if f.minLevel = levelIndex(level); f.minLevel < 0 {
+			return nil, errors.Errorf("invalid --level %q, must be one of: %s", level, strings.Join(logLevels, "|"))
+		}
+	}
+	return f, nil
+}
+
+// format returns the line to print with the prefix, or false if the line is filtered out.
+func (f *logFormatter) format(line logLine, prefix string) (string, bool) {
+	// The timestamp added by Kubernetes comes first.
+	if f.timestamps {
+		if i := strings.IndexByte(line.Line, ' '); i > 0 {
+			line.Timestamp, line.Line = line.Line[:i], line.Line[i+1:]
+		}
+	}
+
+	if f.grep != nil && !f.grep.MatchString(line.Line) {
+		return "", false
+	}
+	fields := parseJSONLine(line.Line)
+	if f.minLevel >= 0 && levelIndex(lineLevel(line.Line, fields)) < f.minLevel {
+		return "", false
+	}
+
+	switch f.output {
+	case logOutputJSON:
+		data, err := json.Marshal(line)
+		if err != nil {
+			return "", false
+		}
+		return string(data), true
+	case logOutputPretty:
+		if fields != nil {
+			return prefix + prettyLine(line.Timestamp, fields), true
+		}
+	}
+	if line.Timestamp != "" {
+		return fmt.Sprintf("%s%s %s", prefix, line.Timestamp, line.Line), true
+	}
+	return prefix + line.Line, true
+}
+
+// parseJSONLine returns the fields of a line which is a JSON object, or nil.
+func parseJSONLine(line string) map[string]interface{} {
+	if !strings.HasPrefix(strings.TrimSpace(line), "{") {
+		return nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &fields); err != nil {
+		return nil
+	}
+	return fields
+}
+
+// lineLevel returns the level of a line written in JSON, with klog or in the logfmt format.
+func lineLevel(line string, fields map[string]interface{}) string {
+	if fields != nil {
+		for _, key := range []string{"level", "severity", "lvl"} {
+			switch level := fields[key].(type) {
+			case string:
+				return level
+			case float64:
+				// The levels of pino, e.g. 30 for info.
+				if i := int(level)/10 - 1; i >= 0 && i < len(logLevels) {
+					return logLevels[i]
+				}
+			}
+		}
+		return ""
+	}
+	if m := klogLine.FindStringSubmatch(line); m != nil {
+		return map[string]string{"I": "info", "W": "warn", "E": "error", "F": "fatal"}[m[1]]
+	}
+	if m := logfmtLevel.FindStringSubmatch(line); m != nil {
+		return m[2]
+	}
+	return ""
+}
+
+// levelIndex returns the index of the level in logLevels, or -1 if it's unknown.
+func levelIndex(level string) int {
+	switch level = strings.ToLower(level); level {
+	case "warning":
+		level = "warn"
+	case "err":
+		level = "error"
+	case "panic", "critical":
+		level = "fatal"
+	}
+	for i, l := range logLevels {
+		if l == level {
+			return i
+		}
+	}
+	return -1
+}
+
+// prettyLine formats the fields of a JSON line as the timestamp, the level and the message followed by the other fields.
+func prettyLine(timestamp string, fields map[string]interface{}) string {
+	take := func(keys ...string) string {
+		for _, key := range keys {
+			if value, ok := fields[key]; ok {
+				delete(fields, key)
+				if s, ok := value.(string); ok {
+					return s
+				}
+				return strconv.Quote(fmt.Sprint(value))
+			}
+		}
+		return ""
+	}
+
+	if t := take("time", "ts", "timestamp", "@timestamp"); t != "" && timestamp == "" {
+		timestamp = t
+	}
+	level := lineLevel("", fields)
+	take("level", "severity", "lvl")
+	message := take("msg", "message")
+
+	var parts []string
+	if timestamp != "" {
+		parts = append(parts, timestamp)
+	}
+	if level != "" {
+		parts = append(parts, colorLevel(level))
+	}
+	if message != "" {
+		parts = append(parts, message)
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value, err := json.Marshal(fields[key])
+		if err != nil {
+			continue
+		}
+		if s, ok := fields[key].(string); ok && !strings.ContainsAny(s, " \"=") {
+			value = []byte(s)
+		}
+		parts = append(parts, fmt.Sprintf("%s=%s", util.White(key), value))
+	}
+	return strings.Join(parts, " ")
+}
+
+func colorLevel(level string) string {
+	name := fmt.Sprintf("%-5s", strings.ToUpper(level))
+	switch i := levelIndex(level); {
+	case i >= levelIndex("error"):
+		return util.Red(name)
+	case i == levelIndex("warn"):
+		return util.Yellow(name)
+	case i == levelIndex("info"):
+		return util.Blue(name)
+	}
+	return name
+}
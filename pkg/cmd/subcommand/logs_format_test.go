@@ -0,0 +1,71 @@
+package subcommand
+
+import "testing"
+
+func TestLogFormatter(t *testing.T) {
+	daprLine := `{"app_id":"sample","level":"info","msg":"dapr initialized","time":"2021-06-10T08:21:42Z","ver":"1.3.0"}`
+
+	tests := []struct {
+		name       string
+		output     string
+		grep       string
+		level      string
+		timestamps bool
+		line       string
+		want       string
+		wantOK     bool
+	}{
+		{name: "raw", output: logOutputRaw, line: daprLine, want: "[p] " + daprLine, wantOK: true},
+		{
+			name:   "pretty",
+			output: logOutputPretty,
+			line:   daprLine,
+			want:   "[p] 2021-06-10T08:21:42Z INFO  dapr initialized app_id=sample ver=1.3.0",
+			wantOK: true,
+		},
+		{name: "pretty not JSON", output: logOutputPretty, line: "listening on :8080", want: "[p] listening on :8080", wantOK: true},
+		{
+			name:   "json",
+			output: logOutputJSON,
+			line:   "listening on :8080",
+			want:   `{"pod":"sample-pod","container":"function","phase":"serving","line":"listening on :8080"}`,
+			wantOK: true,
+		},
+		{
+			name:       "json with timestamps",
+			output:     logOutputJSON,
+			timestamps: true,
+			line:       "2021-06-10T08:21:42.123456789Z listening on :8080",
+			want:       `{"pod":"sample-pod","container":"function","phase":"serving","timestamp":"2021-06-10T08:21:42.123456789Z","line":"listening on :8080"}`,
+			wantOK:     true,
+		},
+		{name: "grep", output: logOutputRaw, grep: "listen", line: "listening on :8080", want: "[p] listening on :8080", wantOK: true},
+		{name: "grep filtered", output: logOutputRaw, grep: "^error", line: "listening on :8080"},
+		{name: "grep after timestamp", output: logOutputRaw, grep: "^listen", timestamps: true, line: "2021-06-10T08:21:42Z listening", want: "[p] 2021-06-10T08:21:42Z listening", wantOK: true},
+		{name: "level JSON filtered", output: logOutputRaw, level: "warn", line: daprLine},
+		{name: "level JSON", output: logOutputRaw, level: "warn", line: `{"severity":"ERROR","message":"failed"}`, want: `[p] {"severity":"ERROR","message":"failed"}`, wantOK: true},
+		{name: "level pino", output: logOutputRaw, level: "warn", line: `{"level":40,"msg":"slow"}`, want: `[p] {"level":40,"msg":"slow"}`, wantOK: true},
+		{name: "level klog", output: logOutputRaw, level: "warning", line: "W0610 08:21:42.123456 1 main.go:10] retrying", want: "[p] W0610 08:21:42.123456 1 main.go:10] retrying", wantOK: true},
+		{name: "level logfmt filtered", output: logOutputRaw, level: "warn", line: `time=2021-06-10T08:21:42Z level=debug msg="connected"`},
+		{name: "level unknown filtered", output: logOutputRaw, level: "trace", line: "listening on :8080"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := newLogFormatter(tt.output, tt.grep, tt.level, tt.timestamps)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok := f.format(logLine{Pod: "sample-pod", Container: "function", Phase: waitForServing, Line: tt.line}, "[p] ")
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("format() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	for _, args := range [][]string{{"yaml", "", ""}, {logOutputRaw, "(", ""}, {logOutputRaw, "", "verbose"}} {
+		if _, err := newLogFormatter(args[0], args[1], args[2], false); err == nil {
+			t.Errorf("newLogFormatter(%q) succeeded, want an error", args)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package subcommand
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/OpenFunction/cli/pkg/cmd/util"
+	openfunction "github.com/openfunction/apis/core/v1beta1"
+	"github.com/pkg/errors"
+	swv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+var buildHistoryColumns = util.Columns("BUILDRUN", "BUILDER", "STATE", "REASON", "DURATION", "LOGS", "AGE")
+
+// errBuildLogsExpired is returned when the pod of a finished build run has been cleaned up.
+var errBuildLogsExpired = errors.New("the logs have expired as the pod of the build run has been cleaned up")
+
+// buildHistory is the builders of a function kept by OpenFunction with their build runs,
+// whose pods are kept until they are cleaned up by Tekton.
+type buildHistory struct {
+	builders  []*openfunction.Builder
+	buildRuns map[string]*swv1alpha1.BuildRun
+	pods      map[string]bool
+}
+
+// loadBuildHistory lists the builders of the function from the newest.
+func (l *Logs) loadBuildHistory(ctx context.Context) (*buildHistory, error) {
+	builders, err := l.functionClient.CoreV1beta1().Builders(l.namespace).List(ctx, metav1.ListOptions{LabelSelector: functionLabel + "=" + l.functionName})
+	if err != nil {
+		return nil, err
+	}
+	buildRuns, err := l.swClient.ShipwrightV1alpha1().BuildRuns(l.namespace).List(ctx, metav1.ListOptions{LabelSelector: builderLabel})
+	if err != nil {
+		return nil, err
+	}
+	pods, err := l.clientSet.CoreV1().Pods(l.namespace).List(ctx, metav1.ListOptions{LabelSelector: buildRunLabel})
+	if err != nil {
+		return nil, err
+	}
+
+	h := &buildHistory{buildRuns: map[string]*swv1alpha1.BuildRun{}, pods: map[string]bool{}}
+	for i := range builders.Items {
+		h.builders = append(h.builders, &builders.Items[i])
+	}
+	sort.SliceStable(h.builders, func(i, j int) bool {
+		return h.builders[j].CreationTimestamp.Before(&h.builders[i].CreationTimestamp)
+	})
+	for i := range buildRuns.Items {
+		h.buildRuns[buildRuns.Items[i].Name] = &buildRuns.Items[i]
+	}
+	for _, pod := range pods.Items {
+		h.pods[pod.Labels[buildRunLabel]] = true
+	}
+	return h, nil
+}
+
+// find returns the builder of the build run, which can be given by the name of its builder too.
+func (h *buildHistory) find(name string) (*openfunction.Builder, string) {
+	for _, builder := range h.builders {
+		_, buildRun := getBuilderResourceRef(builder.Status.ResourceRef)
+		if buildRun == name || builder.Name == name {
+			return builder, buildRun
+		}
+	}
+	return nil, ""
+}
+
+func (h *buildHistory) row(obj interface{}) (metav1.TableRow, error) {
+	builder := obj.(*openfunction.Builder)
+	_, buildRunName := getBuilderResourceRef(builder.Status.ResourceRef)
+
+	buildRun := h.buildRuns[buildRunName]
+	name, buildDuration, logs := "<none>", "", "expired"
+	if buildRunName != "" {
+		name = buildRunName
+	}
+	if buildRun != nil {
+		buildDuration = buildRunDuration(buildRun)
+	}
+	if h.pods[buildRunName] {
+		logs = "available"
+	}
+
+	row := metav1.TableRow{
+		Object: runtime.RawExtension{Object: builder},
+	}
+	row.Cells = append(row.Cells,
+		name,
+		builder.Name,
+		string(builder.Status.State),
+		builder.Status.Reason,
+		buildDuration,
+		logs,
+		util.TranslateTimestampSince(builder.CreationTimestamp),
+	)
+	return row, nil
+}
+
+// printBuildHistory prints the past builds of the function, the logs of the build runs whose pods still exist can be printed with --build-run.
+func (l *Logs) printBuildHistory(ctx context.Context) error {
+	h, err := l.loadBuildHistory(ctx)
+	if err != nil {
+		return err
+	}
+	if len(h.builders) == 0 {
+		fmt.Fprintf(l.ErrOut, "No builds found for function %s\n", l.functionName)
+		return nil
+	}
+
+	objs := make([]runtime.Object, 0, len(h.builders))
+	for _, builder := range h.builders {
+		objs = append(objs, builder)
+	}
+	table, err := util.ToTable(buildHistoryColumns, h.row, objs...)
+	if err != nil {
+		return err
+	}
+	return printers.NewTablePrinter(printers.PrintOptions{}).PrintObj(table, l.Out)
+}
+
+// logsForBuildRun prints the logs of a past build run of the function.
+func (l *Logs) logsForBuildRun(ctx context.Context, name string) error {
+	h, err := l.loadBuildHistory(ctx)
+	if err != nil {
+		return err
+	}
+	builder, buildRun := h.find(name)
+	if builder == nil {
+		return errors.Errorf("build run %s of function %s not found, the builds are listed with --build-history", name, l.functionName)
+	}
+	if buildRun == "" {
+		return errors.Errorf("builder %s hasn't started a build run", builder.Name)
+	}
+	if h.buildRuns[buildRun] == nil && !h.pods[buildRun] {
+		return errors.Errorf("the logs of build run %s have expired as it has been cleaned up", buildRun)
+	}
+	err = l.logsForBuild(ctx, builder.Name, buildRun)
+	if err == errBuildLogsExpired {
+		return errors.Errorf("the logs of build run %s have expired as its pod has been cleaned up", buildRun)
+	}
+	return err
+}
+
+// buildRunDuration returns the time the build run took, or has taken so far if it's running.
+func buildRunDuration(buildRun *swv1alpha1.BuildRun) string {
+	if buildRun.Status.StartTime == nil {
+		return ""
+	}
+	end := time.Now()
+	if buildRun.Status.CompletionTime != nil {
+		end = buildRun.Status.CompletionTime.Time
+	}
+	return duration.HumanDuration(end.Sub(buildRun.Status.StartTime.Time))
+}
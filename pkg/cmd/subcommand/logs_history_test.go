@@ -0,0 +1,152 @@
+package subcommand
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	openfunction "github.com/openfunction/apis/core/v1beta1"
+	fakeclient "github.com/openfunction/pkg/client/clientset/versioned/fake"
+	swv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	fakeswclient "github.com/shipwright-io/build/pkg/client/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	fakek8s "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestBuildHistory(t *testing.T) {
+	now := time.Now()
+	builder := func(name string, age time.Duration, state string, buildRun string) *openfunction.Builder {
+		b := &openfunction.Builder{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				Labels:            map[string]string{functionLabel: "sample"},
+				CreationTimestamp: metav1.NewTime(now.Add(-age)),
+			},
+			Status: openfunction.BuilderStatus{State: state},
+		}
+		if buildRun != "" {
+			b.Status.ResourceRef = map[string]string{"shipwright.io/buildRun": buildRun}
+		}
+		return b
+	}
+	start := metav1.NewTime(now.Add(-time.Hour))
+	end := metav1.NewTime(start.Add(90 * time.Second))
+	buildRun := func(name string, builder string) *swv1alpha1.BuildRun {
+		return &swv1alpha1.BuildRun{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{builderLabel: builder}},
+			Status:     swv1alpha1.BuildRunStatus{StartTime: &start, CompletionTime: &end},
+		}
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample-buildrun-new-pod", Namespace: "default", Labels: map[string]string{buildRunLabel: "sample-buildrun-new"}},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "step-build-and-push"}}},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+			{Name: "step-build-and-push", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}}},
+		}},
+	}
+
+	tests := []struct {
+		name     string
+		history  bool
+		buildRun string
+		// builderRef is the builder of the status of the function whose latest build is printed.
+		builderRef string
+		want       []string
+		wantErrOut string
+		wantErr    string
+	}{
+		{
+			name:    "history",
+			history: true,
+			want: []string{
+				"BUILDRUN BUILDER STATE REASON DURATION LOGS AGE",
+				"sample-buildrun-new sample-builder-new Succeeded 90s available 5m",
+				"sample-buildrun-old sample-builder-old Failed 90s expired 2d",
+				"sample-buildrun-gone sample-builder-gone Succeeded expired 3d",
+				"<none> sample-builder-pending Building expired 4d",
+			},
+		},
+		{name: "build run", buildRun: "sample-buildrun-new", want: []string{"==> Step 1/1: build-and-push", "fake logs", "==> Step 1/1: build-and-push exited with code 0"}},
+		{name: "build run of builder", buildRun: "sample-builder-new", want: []string{"==> Step 1/1: build-and-push", "fake logs", "==> Step 1/1: build-and-push exited with code 0"}},
+		{name: "pod cleaned up", buildRun: "sample-buildrun-old", wantErr: "the logs of build run sample-buildrun-old have expired as its pod has been cleaned up"},
+		{name: "build run cleaned up", buildRun: "sample-buildrun-gone", wantErr: "the logs of build run sample-buildrun-gone have expired as it has been cleaned up"},
+		{name: "build run not found", buildRun: "unknown", wantErr: "build run unknown of function sample not found"},
+		{name: "build run not started", buildRun: "sample-builder-pending", wantErr: "builder sample-builder-pending hasn't started a build run"},
+		{
+			name:       "latest builder cleaned up",
+			builderRef: "sample-builder-deleted",
+			wantErrOut: "The logs of the build have expired as builder sample-builder-deleted has been cleaned up",
+		},
+		{
+			name:       "latest build run cleaned up",
+			builderRef: "sample-builder-gone",
+			wantErrOut: "The logs of the build have expired as build run sample-buildrun-gone has been cleaned up",
+		},
+		{
+			name:       "latest pod cleaned up",
+			builderRef: "sample-builder-old",
+			wantErrOut: "The logs of the build have expired as the pod of build run sample-buildrun-old has been cleaned up",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+			l := &Logs{
+				IOStreams:    &genericclioptions.IOStreams{Out: out, ErrOut: errOut},
+				functionName: "sample",
+				namespace:    "default",
+				Tail:         -1,
+				formatter:    &logFormatter{output: logOutputRaw, minLevel: -1},
+				functionClient: fakeclient.NewSimpleClientset(
+					builder("sample-builder-old", 48*time.Hour, openfunction.Failed, "sample-buildrun-old"),
+					builder("sample-builder-new", 5*time.Minute, openfunction.Succeeded, "sample-buildrun-new"),
+					builder("sample-builder-pending", 96*time.Hour, openfunction.Building, ""),
+					builder("sample-builder-gone", 72*time.Hour, openfunction.Succeeded, "sample-buildrun-gone"),
+				),
+				swClient: fakeswclient.NewSimpleClientset(
+					buildRun("sample-buildrun-new", "sample-builder-new"),
+					buildRun("sample-buildrun-old", "sample-builder-old"),
+				),
+				clientSet: fakek8s.NewSimpleClientset(pod),
+			}
+
+			var err error
+			switch {
+			case tt.history:
+				err = l.printBuildHistory(context.Background())
+			case tt.buildRun != "":
+				err = l.logsForBuildRun(context.Background(), tt.buildRun)
+			default:
+				err = l.logsForLatestBuild(context.Background(), tt.builderRef)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var lines []string
+			for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+				if line != "" {
+					lines = append(lines, strings.Join(strings.Fields(line), " "))
+				}
+			}
+			if strings.Join(lines, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("output = %q, want %q", lines, tt.want)
+			}
+			if !strings.HasPrefix(errOut.String(), tt.wantErrOut) {
+				t.Errorf("error output = %q, want %q", errOut, tt.wantErrOut)
+			}
+		})
+	}
+}
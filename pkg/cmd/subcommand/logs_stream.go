@@ -0,0 +1,342 @@
+package subcommand
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OpenFunction/cli/pkg/cmd/util"
+	"github.com/fatih/color"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+var logPrefixColors = []*color.Color{
+	color.New(color.FgHiCyan),
+	color.New(color.FgHiGreen),
+	color.New(color.FgHiMagenta),
+	color.New(color.FgHiYellow),
+	color.New(color.FgHiBlue),
+	color.New(color.FgHiRed),
+}
+
+// podLogs streams the logs of the containers of pods concurrently,
+// each line is prefixed with the name of its pod and container.
+type podLogs struct {
+	pods      corev1client.PodInterface
+	container string
+	phase     string
+	options   func(container string) *corev1.PodLogOptions
+	formatter *logFormatter
+	errOut    io.Writer
+
+	// mu guards out, errOut, the streams and the count of the failed streams.
+	mu      sync.Mutex
+	out     io.Writer
+	streams map[string]context.CancelFunc
+	failed  int
+	wg      sync.WaitGroup
+}
+
+func newPodLogs(
+	pods corev1client.PodInterface,
+	container string,
+	phase string,
+	options func(string) *corev1.PodLogOptions,
+	formatter *logFormatter,
+	out io.Writer,
+	errOut io.Writer,
+) *podLogs {
+	return &podLogs{
+		pods:      pods,
+		container: container,
+		phase:     phase,
+		options:   options,
+		formatter: formatter,
+		out:       out,
+		errOut:    errOut,
+		streams:   map[string]context.CancelFunc{},
+	}
+}
+
+// Write writes the lines of the streams one at a time.
+func (p *podLogs) Write(data []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.out.Write(data)
+}
+
+// streamPod starts the streams of the containers of the pod which have started and aren't streamed yet.
+func (p *podLogs) streamPod(ctx context.Context, pod *corev1.Pod) {
+	statuses := map[string]corev1.ContainerStatus{}
+	for _, status := range pod.Status.ContainerStatuses {
+		statuses[status.Name] = status
+	}
+
+	for _, container := range pod.Spec.Containers {
+		if p.container != "" && container.Name != p.container {
+			continue
+		}
+		options := p.options(container.Name)
+		status := statuses[container.Name]
+		started := status.State.Running != nil || status.State.Terminated != nil
+		if options.Previous {
+			started = status.LastTerminationState.Terminated != nil
+		}
+		if !started {
+			continue
+		}
+
+		key := pod.Name + "/" + container.Name
+		p.mu.Lock()
+		if _, ok := p.streams[key]; ok {
+			p.mu.Unlock()
+			continue
+		}
+		streamCtx, cancel := context.WithCancel(ctx)
+		p.streams[key] = cancel
+		prefix := logPrefixColors[(len(p.streams)-1)%len(logPrefixColors)].Sprintf("[%s]", key) + " "
+		p.mu.Unlock()
+
+		p.wg.Add(1)
+		go func(name string) {
+			defer p.wg.Done()
+			// The errors are printed as they happen, as the pods may be followed until the command is interrupted.
+			if err := p.stream(streamCtx, name, options, prefix); err != nil {
+				p.mu.Lock()
+				p.failed++
+				fmt.Fprintf(p.errOut, "error: %v\n", err)
+				p.mu.Unlock()
+			}
+		}(pod.Name)
+	}
+}
+
+func (p *podLogs) stream(ctx context.Context, pod string, options *corev1.PodLogOptions, prefix string) error {
+	logs, err := p.pods.GetLogs(pod, options).Stream(ctx)
+	// The pod may be deleted due to scale in.
+	if k8serrors.IsNotFound(err) || ctx.Err() != nil {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get the logs of %s/%s: %v", pod, options.Container, err)
+	}
+	defer logs.Close()
+
+	if err := p.copyLines(logs, pod, options.Container, prefix); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("failed to stream the logs of %s/%s: %v", pod, options.Container, err)
+	}
+	return nil
+}
+
+// copyLines prints the lines of the logs of the container which aren't filtered out.
+func (p *podLogs) copyLines(in io.Reader, pod string, container string, prefix string) error {
+	r := bufio.NewReader(in)
+	for {
+		line, err := r.ReadString('\n')
+		if line != "" {
+			line = strings.TrimSuffix(line, "\n")
+			if out, ok := p.formatter.format(logLine{Pod: pod, Container: container, Phase: p.phase, Line: line}, prefix); ok {
+				if _, err := io.WriteString(p, out+"\n"); err != nil {
+					return err
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// printStep prints a header or the result of a build step, except with -o json
+// so that the output only has the lines of the logs.
+func (p *podLogs) printStep(line string) {
+	if p.formatter.output != logOutputJSON {
+		fmt.Fprintf(p, "%s\n", line)
+	}
+}
+
+// stopPod stops the streams of the containers of a deleted pod, a new pod with the same name is streamed again.
+func (p *podLogs) stopPod(pod *corev1.Pod) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key, cancel := range p.streams {
+		if strings.HasPrefix(key, pod.Name+"/") {
+			cancel()
+			delete(p.streams, key)
+		}
+	}
+}
+
+// wait waits for the streams to end.
+func (p *podLogs) wait() error {
+	p.wg.Wait()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.failed != 0 {
+		return errors.Errorf("failed to get the logs of %d container(s)", p.failed)
+	}
+	return nil
+}
+
+// logsForPods prints the logs of the pods matching the selector.
+// Without follow the logs of the existing pods are printed once, otherwise the pods are watched,
+// so the pods created later, e.g. when the function is scaled out, are streamed too until ctx is done.
+func logsForPods(ctx context.Context, p *podLogs, selector string, follow bool) error {
+	if !follow {
+		pods, err := p.pods.List(ctx, metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			return err
+		}
+		for i := range pods.Items {
+			p.streamPod(ctx, &pods.Items[i])
+		}
+		return p.wait()
+	}
+
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.LabelSelector = selector
+			return p.pods.List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.LabelSelector = selector
+			return p.pods.Watch(ctx, options)
+		},
+	}
+
+	_, controller := cache.NewInformer(lw, &corev1.Pod{}, 0, cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if pod, ok := obj.(*corev1.Pod); ok {
+				p.streamPod(ctx, pod)
+			}
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			if pod, ok := obj.(*corev1.Pod); ok {
+				p.streamPod(ctx, pod)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if unknown, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = unknown.Obj
+			}
+			if pod, ok := obj.(*corev1.Pod); ok {
+				p.stopPod(pod)
+			}
+		},
+	})
+	controller.Run(ctx.Done())
+	return p.wait()
+}
+
+// buildRunPod returns the pod of the build run. With follow it waits for the pod to be created until finished returns true,
+// as the build may fail before, e.g. if its strategy doesn't exist.
+func buildRunPod(ctx context.Context, pods corev1client.PodInterface, buildRun string, follow bool, finished func() (bool, error)) (*corev1.Pod, error) {
+	var pod *corev1.Pod
+	err := wait.PollImmediateUntil(time.Second, func() (bool, error) {
+		list, err := pods.List(ctx, metav1.ListOptions{LabelSelector: fmt.Sprintf("%s=%s", buildRunLabel, buildRun)})
+		if err != nil {
+			return false, err
+		}
+		if len(list.Items) != 0 {
+			pod = &list.Items[0]
+			return true, nil
+		}
+		if !follow {
+			return true, nil
+		}
+		return finished()
+	}, ctx.Done())
+	return pod, err
+}
+
+// streamBuildSteps prints the logs of the steps of a build pod in the order they run, i.e. the order of the containers.
+// The logs of each step are printed after a header with its name, then its exit code is printed once it's terminated.
+func streamBuildSteps(ctx context.Context, p *podLogs, pod *corev1.Pod) error {
+	var steps []corev1.Container
+	for _, container := range pod.Spec.Containers {
+		if p.container == "" || container.Name == p.container {
+			steps = append(steps, container)
+		}
+	}
+
+	for i, step := range steps {
+		header := fmt.Sprintf("Step %d/%d: %s", i+1, len(steps), strings.TrimPrefix(step.Name, "step-"))
+		options := p.options(step.Name)
+		status, err := waitForContainer(ctx, p.pods, pod.Name, step.Name, options.Follow)
+		if err != nil {
+			return err
+		}
+		if status == nil {
+			p.printStep(util.WhiteBold(fmt.Sprintf("==> %s didn't run", header)))
+			continue
+		}
+		p.printStep(util.WhiteBold("==> " + header))
+
+		logs, err := p.pods.GetLogs(pod.Name, options).Stream(ctx)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get the logs of step %s", step.Name)
+		}
+		err = p.copyLines(logs, pod.Name, step.Name, "")
+		logs.Close()
+		if err != nil {
+			return err
+		}
+
+		// The step has terminated once its logs end if they are followed.
+		if options.Follow {
+			if status, err = waitForContainer(ctx, p.pods, pod.Name, step.Name, false); err != nil {
+				return err
+			}
+		}
+		if status == nil || status.State.Terminated == nil {
+			continue
+		}
+		terminated := status.State.Terminated
+		result := fmt.Sprintf("==> %s exited with code %d", header, terminated.ExitCode)
+		if terminated.Reason != "" && terminated.Reason != "Completed" {
+			result += fmt.Sprintf(" (%s)", terminated.Reason)
+		}
+		if terminated.ExitCode == 0 {
+			p.printStep(util.Green(result))
+		} else {
+			p.printStep(util.Red(result))
+		}
+	}
+	return nil
+}
+
+// waitForContainer returns the status of the container once it has started,
+// or nil if the pod completes without starting it. Without untilStarted the status is returned at once.
+func waitForContainer(ctx context.Context, pods corev1client.PodInterface, pod string, container string, untilStarted bool) (*corev1.ContainerStatus, error) {
+	var result *corev1.ContainerStatus
+	err := wait.PollImmediateUntil(time.Second, func() (bool, error) {
+		p, err := pods.Get(ctx, pod, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		for i, status := range p.Status.ContainerStatuses {
+			if status.Name == container && (status.State.Running != nil || status.State.Terminated != nil) {
+				result = &p.Status.ContainerStatuses[i]
+				return true, nil
+			}
+		}
+		// The steps after a failed one never start.
+		return !untilStarted || p.Status.Phase == corev1.PodSucceeded || p.Status.Phase == corev1.PodFailed, nil
+	}, ctx.Done())
+	return result, err
+}
@@ -0,0 +1,277 @@
+package subcommand
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	openfunction "github.com/openfunction/apis/core/v1beta1"
+	fakeclient "github.com/openfunction/pkg/client/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	fakek8s "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestLogsForPods(t *testing.T) {
+	running := corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	waiting := corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{}}
+	pod := func(name string, phase corev1.PodPhase, states ...corev1.ContainerState) *corev1.Pod {
+		p := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{servingLabel: "sample-serving"}},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+		for i, state := range states {
+			container := []string{"function", "dapr"}[i]
+			p.Spec.Containers = append(p.Spec.Containers, corev1.Container{Name: container})
+			p.Status.ContainerStatuses = append(p.Status.ContainerStatuses, corev1.ContainerStatus{Name: container, State: state})
+		}
+		return p
+	}
+
+	tests := []struct {
+		name      string
+		container string
+		follow    bool
+		pods      []*corev1.Pod
+		// events are sent once the pods are watched.
+		events    []watch.Event
+		wantLines []string
+	}{
+		{
+			name: "all containers",
+			pods: []*corev1.Pod{pod("sample-a", corev1.PodRunning, running, running), pod("sample-b", corev1.PodPending, running, waiting)},
+			wantLines: []string{
+				"[sample-a/dapr] fake logs",
+				"[sample-a/function] fake logs",
+				"[sample-b/function] fake logs",
+			},
+		},
+		{
+			name:      "one container",
+			container: "dapr",
+			pods:      []*corev1.Pod{pod("sample-a", corev1.PodRunning, running, running), pod("sample-b", corev1.PodRunning, running, running)},
+			wantLines: []string{
+				"[sample-a/dapr] fake logs",
+				"[sample-b/dapr] fake logs",
+			},
+		},
+		{
+			name:   "follow new pods and containers",
+			follow: true,
+			pods:   []*corev1.Pod{pod("sample-a", corev1.PodRunning, running, waiting)},
+			events: []watch.Event{
+				{Type: watch.Added, Object: pod("sample-b", corev1.PodRunning, running)},
+				{Type: watch.Modified, Object: pod("sample-a", corev1.PodRunning, running, running)},
+			},
+			wantLines: []string{
+				"[sample-a/dapr] fake logs",
+				"[sample-a/function] fake logs",
+				"[sample-b/function] fake logs",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientSet := fakek8s.NewSimpleClientset()
+			for _, p := range tt.pods {
+				if _, err := clientSet.CoreV1().Pods("default").Create(context.Background(), p, metav1.CreateOptions{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			fw := watch.NewFake()
+			clientSet.PrependWatchReactor("pods", k8stesting.DefaultWatchReactor(fw, nil))
+			events := tt.events
+			go func() {
+				for _, e := range events {
+					fw.Action(e.Type, e.Object)
+				}
+			}()
+
+			out := &bytes.Buffer{}
+			options := func(container string) *corev1.PodLogOptions {
+				return &corev1.PodLogOptions{Container: container, Follow: tt.follow}
+			}
+			p := newPodLogs(clientSet.CoreV1().Pods("default"), tt.container, waitForServing, options, &logFormatter{output: logOutputRaw, minLevel: -1}, out, out)
+			ctx, done := context.WithCancel(context.Background())
+			defer done()
+			if tt.follow {
+				// The pods are followed until the lines are printed.
+				go func() {
+					_ = wait.PollImmediate(10*time.Millisecond, 10*time.Second, func() (bool, error) {
+						p.mu.Lock()
+						defer p.mu.Unlock()
+						return strings.Count(out.String(), "\n") >= len(tt.wantLines), nil
+					})
+					done()
+				}()
+			}
+			if err := logsForPods(ctx, p, servingLabel+"=sample-serving", tt.follow); err != nil {
+				t.Fatal(err)
+			}
+
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			sort.Strings(lines)
+			if !reflect.DeepEqual(lines, tt.wantLines) {
+				t.Errorf("lines = %q, want %q", lines, tt.wantLines)
+			}
+
+			for _, action := range clientSet.Actions() {
+				if action.GetSubresource() != "log" {
+					continue
+				}
+				if opts := action.(k8stesting.GenericAction).GetValue().(*corev1.PodLogOptions); opts.Follow != tt.follow {
+					t.Errorf("follow = %v, want %v", opts.Follow, tt.follow)
+				}
+			}
+		})
+	}
+}
+
+func TestStreamBuildSteps(t *testing.T) {
+	exited := func(code int32, reason string) corev1.ContainerState {
+		return corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: code, Reason: reason}}
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample-buildrun-pod", Namespace: "default"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "step-source-default"}, {Name: "step-prepare"}, {Name: "step-build-and-push"}, {Name: "step-export"}},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodFailed,
+			ContainerStatuses: []corev1.ContainerStatus{
+				// The statuses aren't in the order of the steps.
+				{Name: "step-export", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{}}},
+				{Name: "step-build-and-push", State: exited(51, "Error")},
+				{Name: "step-prepare", State: exited(0, "Completed")},
+				{Name: "step-source-default", State: exited(0, "Completed")},
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		container string
+		follow    bool
+		want      string
+	}{
+		{
+			name: "all steps",
+			want: `==> Step 1/4: source-default
+fake logs
+==> Step 1/4: source-default exited with code 0
+==> Step 2/4: prepare
+fake logs
+==> Step 2/4: prepare exited with code 0
+==> Step 3/4: build-and-push
+fake logs
+==> Step 3/4: build-and-push exited with code 51 (Error)
+==> Step 4/4: export didn't run
+`,
+		},
+		{
+			name:      "one step followed",
+			container: "step-build-and-push",
+			follow:    true,
+			want: `==> Step 1/1: build-and-push
+fake logs
+==> Step 1/1: build-and-push exited with code 51 (Error)
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientSet := fakek8s.NewSimpleClientset(pod)
+			out := &bytes.Buffer{}
+			options := func(container string) *corev1.PodLogOptions {
+				return &corev1.PodLogOptions{Container: container, Follow: tt.follow}
+			}
+			p := newPodLogs(clientSet.CoreV1().Pods("default"), tt.container, waitForBuild, options, &logFormatter{output: logOutputRaw, minLevel: -1}, out, out)
+			if err := streamBuildSteps(context.Background(), p, pod); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("output = %q, want %q", out, tt.want)
+			}
+		})
+	}
+}
+
+func TestFollowBuild(t *testing.T) {
+	terminated := corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Reason: "Error"}}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample-buildrun-pod", Namespace: "default", Labels: map[string]string{buildRunLabel: "sample-buildrun"}},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "step-source-default"}, {Name: "step-build"}, {Name: "step-export"}},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodFailed,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "step-source-default", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}}},
+				{Name: "step-build", State: terminated},
+				{Name: "step-export", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{}}},
+			},
+		},
+	}
+	builder := func(buildRun string) *openfunction.Builder {
+		b := &openfunction.Builder{
+			ObjectMeta: metav1.ObjectMeta{Name: "sample-builder", Namespace: "default"},
+			Status:     openfunction.BuilderStatus{State: openfunction.Failed},
+		}
+		if buildRun != "" {
+			b.Status.ResourceRef = map[string]string{"shipwright.io/buildRun": buildRun}
+		}
+		return b
+	}
+
+	tests := []struct {
+		name     string
+		buildRun string
+		want     string
+	}{
+		{
+			name:     "steps",
+			buildRun: "sample-buildrun",
+			want: `==> Step 1/3: source-default
+fake logs
+==> Step 1/3: source-default exited with code 0
+==> Step 2/3: build
+fake logs
+==> Step 2/3: build exited with code 1 (Error)
+==> Step 3/3: export didn't run
+`,
+		},
+		{
+			name: "failed before the build run",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			b := &Build{
+				IOStreams:      genericclioptions.IOStreams{Out: out, ErrOut: out},
+				functionClient: fakeclient.NewSimpleClientset(builder(tt.buildRun)),
+				clientSet:      fakek8s.NewSimpleClientset(pod),
+			}
+
+			ctx, done := context.WithTimeout(context.Background(), 10*time.Second)
+			defer done()
+			if err := b.followBuild(ctx, "default", "sample-builder"); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("output = %q, want %q", out, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package subcommand
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/OpenFunction/cli/pkg/cmd/util"
+	"github.com/OpenFunction/cli/pkg/cmd/util/client"
+	"github.com/OpenFunction/cli/pkg/components/common"
+	"github.com/OpenFunction/cli/pkg/components/config"
+	"github.com/OpenFunction/cli/pkg/components/inventory"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	k8s "k8s.io/client-go/kubernetes"
+)
+
+const (
+	noVersion = "-"
+
+	driftMissing     = "missing"
+	driftRecord      = "differs from record"
+	driftRecommended = "differs from recommended"
+)
+
+// Status is the commandline for 'status' sub command
+type Status struct {
+	genericclioptions.IOStreams
+
+	OpenFunctionVersion string
+	RegionCN            bool
+	Timeout             time.Duration
+	ConfigFile          string
+
+	config *config.InstallConfig
+}
+
+// componentStatus is a row of the status table.
+type componentStatus struct {
+	name        string
+	installed   string
+	ready       bool
+	recorded    string
+	recommended string
+	drift       []string
+}
+
+// NewStatus returns an initialized Status instance
+func NewStatus(ioStreams genericclioptions.IOStreams) *Status {
+	return &Status{
+		IOStreams: ioStreams,
+	}
+}
+
+func NewCmdStatus(cf *genericclioptions.ConfigFlags, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	var cl k8s.Interface
+
+	s := NewStatus(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:                   "status [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Show the versions of OpenFunction and its dependencies running in the cluster.",
+		Long: `This command will detect the versions of OpenFunction and its dependencies actually running in the cluster,
+and compare them with the versions in the inventory record and the versions "ofn install" would install.`,
+		Example: `
+# Show the status of OpenFunction and its dependencies
+ofn status
+
+# Compare the installed components with the ones of a specific version of OpenFunction
+ofn status --version v0.6.0
+
+# Show the status of OpenFunction installed from a config file
+ofn status -c ofn-install.yaml
+`,
+		PreRunE: func(cmd *cobra.Command, args []string) (err error) {
+			_, cl, err = client.NewKubeConfigClient(cf)
+			if err != nil {
+				return err
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(s.loadConfig(cmd))
+			util.CheckErr(s.RunStatus(cf, cl))
+		},
+	}
+
+	cmd.Flags().StringVar(&s.OpenFunctionVersion, "version", "", "The version of OpenFunction to get the recommended versions from, defaults to the latest stable version.")
+	cmd.Flags().BoolVar(&s.RegionCN, "region-cn", false, "For users who have limited access to gcr.io or github.com.")
+	cmd.Flags().DurationVar(&s.Timeout, "timeout", time.Minute, "Set timeout time. Default is 1 minute.")
+	cmd.Flags().StringVarP(&s.ConfigFile, "config", "c", "", "The config file the installation has been made with, the flags which are set take precedence over it.")
+	return cmd
+}
+
+// loadConfig loads the config file the components have been installed with,
+// so that they are looked for in the namespaces they have been moved to.
+func (s *Status) loadConfig(cmd *cobra.Command) error {
+	if s.ConfigFile == "" {
+		return nil
+	}
+
+	c, err := config.Load(s.ConfigFile)
+	if err != nil {
+		return err
+	}
+	s.config = c
+
+	flags := cmd.Flags()
+	if !flags.Changed("version") && c.Version != "" {
+		s.OpenFunctionVersion = c.Version
+	}
+	if !flags.Changed("region-cn") {
+		s.RegionCN = c.RegionCN
+	}
+	return nil
+}
+
+func (s *Status) RunStatus(cf *genericclioptions.ConfigFlags, cl k8s.Interface) error {
+	ctx, done := context.WithTimeout(
+		context.Background(),
+		s.Timeout,
+	)
+	defer done()
+
+	if s.OpenFunctionVersion == "" {
+		if v, err := getLatestStableVersion(); err != nil {
+			fmt.Fprintln(s.ErrOut, util.YellowItalic(fmt.Sprintf(
+				"failed to fetch OpenFunction latest release, %s, the default version is recommended", err.Error())))
+		} else {
+			s.OpenFunctionVersion = v
+		}
+	}
+
+	operator := common.NewOperator(cf, runtime.GOOS, runtime.GOARCH, s.OpenFunctionVersion, s.Timeout, s.RegionCN, false)
+	if err := renameNamespaces(s.config, operator); err != nil {
+		return err
+	}
+
+	installed, err := operator.DetectComponents(ctx, cl)
+	if err != nil {
+		return errors.Wrap(err, "failed to detect components")
+	}
+
+	recorded, err := operator.GetInventoryRecord(ctx, true)
+	if err != nil {
+		return errors.Wrap(err, "failed to get inventory record")
+	}
+
+	inv, err := inventory.GetInventory(cl, s.RegionCN, true, true, true, true, true, true, s.OpenFunctionVersion)
+	if err != nil {
+		return errors.Wrap(err, "failed to get inventory")
+	}
+	if s.config != nil {
+		inv = s.config.Inventory(inv)
+	}
+	recommended := inventory.GetVersionMap(inv)
+
+	rows := getComponentStatus(installed, recorded, recommended)
+
+	t := table.NewWriter()
+	t.SetOutputMirror(s.Out)
+	t.AppendHeader(table.Row{"Component", "Installed", "Ready", "Recorded", "Recommended", "Drift"})
+	for _, r := range rows {
+		t.AppendRow(table.Row{r.name, r.installed, r.ready, orNoVersion(r.recorded), orNoVersion(r.recommended), strings.Join(r.drift, ", ")})
+	}
+	t.Render()
+	return nil
+}
+
+// getComponentStatus compares the installed, recorded and recommended versions of every component.
+// The components which are neither installed, recorded nor recommended are omitted.
+func getComponentStatus(
+	installed map[string]*common.ComponentStatus,
+	recorded map[string]string,
+	recommended map[string]string,
+) []*componentStatus {
+	var rows []*componentStatus
+	for name, st := range installed {
+		r := &componentStatus{
+			name:        name,
+			installed:   noVersion,
+			ready:       st.Ready,
+			recorded:    recorded[name],
+			recommended: recommended[name],
+		}
+
+		if st.Installed {
+			r.installed = st.Version
+			if r.installed == "" {
+				r.installed = "unknown"
+			}
+		} else if r.recorded == "" && r.recommended == "" {
+			continue
+		}
+
+		switch {
+		case !st.Installed && r.recorded != "":
+			r.drift = append(r.drift, driftMissing)
+		case st.Version != "" && r.recorded != "" && st.Version != common.NormalizeVersion(r.recorded):
+			r.drift = append(r.drift, driftRecord)
+		}
+
+		if st.Version != "" && common.NormalizeVersion(r.recommended) != "" &&
+			st.Version != common.NormalizeVersion(r.recommended) {
+			r.drift = append(r.drift, driftRecommended)
+		}
+		rows = append(rows, r)
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].name < rows[j].name
+	})
+	return rows
+}
+
+func orNoVersion(v string) string {
+	if v == "" {
+		return noVersion
+	}
+	return v
+}
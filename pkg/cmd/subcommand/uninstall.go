@@ -14,7 +14,9 @@ import (
 	"github.com/OpenFunction/cli/pkg/cmd/util"
 	"github.com/OpenFunction/cli/pkg/cmd/util/client"
 	"github.com/OpenFunction/cli/pkg/cmd/util/spinners"
+	"github.com/OpenFunction/cli/pkg/components/bundle"
 	"github.com/OpenFunction/cli/pkg/components/common"
+	"github.com/OpenFunction/cli/pkg/components/config"
 	"github.com/OpenFunction/cli/pkg/components/inventory"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -39,10 +41,14 @@ type Uninstall struct {
 	WithAll             bool
 	RegionCN            bool
 	OpenFunctionVersion string
+	FromBundle          string
 	DryRun              bool
 	Yes                 bool
 	WaitForCleared      bool
 	Timeout             time.Duration
+	ConfigFile          string
+
+	config *config.InstallConfig
 }
 
 // NewUninstall returns an initialized Init instance
@@ -53,7 +59,7 @@ func NewUninstall(ioStreams genericclioptions.IOStreams) *Uninstall {
 }
 
 func NewCmdUninstall(cf *genericclioptions.ConfigFlags, ioStreams genericclioptions.IOStreams) *cobra.Command {
-	var cl *k8s.Clientset
+	var cl k8s.Interface
 
 	i := NewUninstall(ioStreams)
 
@@ -78,6 +84,9 @@ ofn uninstall --all --wait
 # Uninstall a specific version of OpenFunction
 ofn uninstall --all --version v0.4.0
 
+# Uninstall OpenFunction installed from a config file
+ofn uninstall -c ofn-install.yaml
+
 # See more at: https://github.com/OpenFunction/cli/blob/main/docs/uninstall.md
 `,
 		PreRunE: func(cmd *cobra.Command, args []string) (err error) {
@@ -88,8 +97,9 @@ ofn uninstall --all --version v0.4.0
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(i.loadConfig(cmd))
 			util.CheckErr(i.ValidateArgs())
-			util.CheckErr(i.RunUninstall(cl, cmd))
+			util.CheckErr(i.RunUninstall(cf, cl, cmd))
 		},
 	}
 
@@ -106,7 +116,9 @@ ofn uninstall --all --version v0.4.0
 	cmd.Flags().BoolVar(&i.WaitForCleared, "wait", false, "Awaiting the results of the uninstallation.")
 	cmd.Flags().BoolVarP(&i.Yes, "yes", "y", false, "Automatic yes to prompts.")
 	cmd.Flags().StringVar(&i.OpenFunctionVersion, "version", "", "Used to specify the version of OpenFunction to be uninstalled.")
+	cmd.Flags().StringVar(&i.FromBundle, "from-bundle", "", "Use the manifests of the bundle created by 'ofn bundle create' instead of downloading them.")
 	cmd.Flags().DurationVar(&i.Timeout, "timeout", 10*time.Minute, "Set timeout time. Default is 10 minutes.")
+	cmd.Flags().StringVarP(&i.ConfigFile, "config", "c", "", "The config file the installation has been made with, the flags which are set take precedence over it.")
 	// In order to avoid too many options causing misunderstandings among users,
 	// we have hidden the following parameters,
 	// but you can still find their usage instructions in the documentation.
@@ -117,7 +129,48 @@ ofn uninstall --all --version v0.4.0
 	return cmd
 }
 
+// loadConfig loads the config file the components have been installed with,
+// so that they are uninstalled from the namespaces they have been moved to.
+func (i *Uninstall) loadConfig(cmd *cobra.Command) error {
+	if i.ConfigFile == "" {
+		return nil
+	}
+
+	c, err := config.Load(i.ConfigFile)
+	if err != nil {
+		return err
+	}
+	i.config = c
+
+	flags := cmd.Flags()
+	if !flags.Changed("version") && c.Version != "" {
+		i.OpenFunctionVersion = c.Version
+	}
+	if !flags.Changed("runtime") && len(c.Runtimes) != 0 {
+		i.Runtimes = c.Runtimes
+	}
+	if !flags.Changed("region-cn") {
+		i.RegionCN = c.RegionCN
+	}
+	if !flags.Changed("from-bundle") && c.FromBundle != "" {
+		i.FromBundle = c.FromBundle
+	}
+	if !flags.Changed("timeout") && c.Timeout != 0 {
+		i.Timeout = time.Duration(c.Timeout)
+	}
+	return nil
+}
+
 func (i *Uninstall) ValidateArgs() error {
+	// Use the version of OpenFunction the bundle has been created for.
+	if i.FromBundle != "" && i.OpenFunctionVersion == "" {
+		index, err := bundle.ReadIndex(i.FromBundle)
+		if err != nil {
+			return errors.Wrap(err, "failed to read bundle")
+		}
+		i.OpenFunctionVersion = index.OpenFunctionVersion
+	}
+
 	if i.OpenFunctionVersion == common.LatestVersion {
 		return nil
 	}
@@ -149,8 +202,13 @@ func (i *Uninstall) ValidateArgs() error {
 	return nil
 }
 
-func (i *Uninstall) RunUninstall(cl *k8s.Clientset, cmd *cobra.Command) error {
-	operator := common.NewOperator(runtime.GOOS, runtime.GOARCH, i.OpenFunctionVersion, i.Timeout, i.RegionCN, i.Verbose)
+func (i *Uninstall) RunUninstall(cf *genericclioptions.ConfigFlags, cl k8s.Interface, cmd *cobra.Command) error {
+	operator := common.NewOperator(cf, runtime.GOOS, runtime.GOARCH, i.OpenFunctionVersion, i.Timeout, i.RegionCN, i.Verbose)
+	return i.runUninstall(cl, operator)
+}
+
+// runUninstall uninstalls the components with the operator, which is made with a fake executor in the tests.
+func (i *Uninstall) runUninstall(cl k8s.Interface, operator *common.Operator) error {
 	continueFunc := func() bool {
 		reader := bufio.NewReader(os.Stdin)
 		util.BeforeTask("Please ensure that you understand the meaning of this command " +
@@ -197,6 +255,23 @@ func (i *Uninstall) RunUninstall(cl *k8s.Clientset, cmd *cobra.Command) error {
 	if err != nil {
 		return errors.Wrap(err, "failed to get pending inventory")
 	}
+	if i.FromBundle != "" {
+		b, err := bundle.Open(i.FromBundle)
+		if err != nil {
+			return errors.Wrap(err, "failed to open bundle")
+		}
+		defer b.Close()
+
+		if inventoryPending, err = b.Inventory(inventoryPending); err != nil {
+			return errors.Wrap(err, "failed to use bundle")
+		}
+	}
+	if i.config != nil {
+		inventoryPending = i.config.Inventory(inventoryPending)
+	}
+	if err := renameNamespaces(i.config, operator); err != nil {
+		return err
+	}
 	operator.Inventory = inventoryPending
 
 	util.BeforeTask("Start uninstalling OpenFunction and its dependencies.")
@@ -229,94 +304,11 @@ func (i *Uninstall) RunUninstall(cl *k8s.Clientset, cmd *cobra.Command) error {
 
 	start := time.Now()
 
-	group := spinners.NewSpinnerGroup()
-	count := 0
-
-	if i.WithDapr {
-		if operator.Records.Dapr != "" {
-			count += 1
-			group.AddSpinner()
-			go func(ctx context.Context, idx int) {
-				spinner := group.At(idx).WithName("Dapr")
-				uninstallDapr(ctx, spinner, cl, operator, i.WaitForCleared)
-			}(ctx, count-1)
-		}
-	}
-
-	if i.WithKeda {
-		if operator.Records.Keda != "" {
-			count += 1
-			group.AddSpinner()
-			go func(ctx context.Context, idx int) {
-				spinner := group.At(idx).WithName("Keda")
-				uninstallKeda(ctx, spinner, cl, operator, i.WaitForCleared)
-			}(ctx, count-1)
-		}
-	}
-
-	if i.WithKnative {
-		if operator.Records.KnativeServing != "" {
-			count += 1
-			group.AddSpinner()
-			go func(ctx context.Context, idx int) {
-				spinner := group.At(idx).WithName("Knative Serving")
-				uninstallKnativeServing(ctx, spinner, cl, operator, i.WaitForCleared)
-			}(ctx, count-1)
-		}
-	}
-
-	if i.WithShipWright {
-		if operator.Records.Shipwright != "" {
-			count += 1
-			group.AddSpinner()
-			go func(ctx context.Context, idx int) {
-				spinner := group.At(idx).WithName("Shipwright")
-				uninstallShipwright(ctx, spinner, cl, operator, i.WaitForCleared)
-			}(ctx, count-1)
-		}
-		if operator.Records.TektonPipelines != "" {
-			count += 1
-			group.AddSpinner()
-			go func(ctx context.Context, idx int) {
-				spinner := group.At(idx).WithName("Tekton Pipelines")
-				uninstallTektonPipelines(ctx, spinner, cl, operator, i.WaitForCleared)
-			}(ctx, count-1)
-		}
-	}
-
-	if i.WithCertManager {
-		if operator.Records.CertManager != "" {
-			count += 1
-			group.AddSpinner()
-			go func(ctx context.Context, idx int) {
-				spinner := group.At(idx).WithName("Cert Manager")
-				uninstallCertManager(ctx, spinner, cl, operator, i.WaitForCleared)
-			}(ctx, count-1)
-		}
-	}
-
-	if i.WithIngressNginx {
-		if operator.Records.Ingress != "" {
-			count += 1
-			group.AddSpinner()
-			go func(ctx context.Context, idx int) {
-				spinner := group.At(idx).WithName("Ingress")
-				uninstallIngress(ctx, spinner, cl, operator, i.WaitForCleared)
-			}(ctx, count-1)
-		}
-	}
-
-	if operator.Records.OpenFunction != "" {
-		count += 1
-		group.AddSpinner()
-		go func(ctx context.Context, idx int) {
-			spinner := group.At(idx).WithName("OpenFunction")
-			uninstallOpenFunction(ctx, spinner, cl, operator, i.WaitForCleared)
-		}(ctx, count-1)
+	sched, err := newUninstallScheduler(cl, operator, i.WaitForCleared)
+	if err != nil {
+		return errors.Wrap(err, "failed to schedule the uninstallation")
 	}
-
-	group.Start(ctx)
-	if err := group.Wait(); err != nil {
+	if err := sched.Run(ctx); err != nil {
 		return errors.New(util.TaskFail(err.Error()))
 	}
 
@@ -354,10 +346,28 @@ func (i *Uninstall) calculateConditions() error {
 		i.WithShipWright = true
 	}
 
+	// The components disabled by the config haven't been installed.
+	for name, enabled := range i.config.Enabled() {
+		switch name {
+		case inventory.DaprName:
+			i.WithDapr = enabled
+		case inventory.KedaName:
+			i.WithKeda = enabled
+		case inventory.KnativeServingName:
+			i.WithKnative = enabled
+		case inventory.ShipwrightName:
+			i.WithShipWright = enabled
+		case inventory.CertManagerName:
+			i.WithCertManager = enabled
+		case inventory.IngressName:
+			i.WithIngressNginx = enabled
+		}
+	}
+
 	return nil
 }
 
-func uninstallDapr(ctx context.Context, spinner *spinners.Spinner, cl *k8s.Clientset, operator *common.Operator, waitForCleared bool) {
+func uninstallDapr(ctx context.Context, spinner *spinners.Spinner, cl k8s.Interface, operator *common.Operator, waitForCleared bool) {
 	ctx, done := context.WithCancel(ctx)
 	defer done()
 
@@ -374,7 +384,7 @@ func uninstallDapr(ctx context.Context, spinner *spinners.Spinner, cl *k8s.Clien
 	spinner.Done()
 }
 
-func uninstallKeda(ctx context.Context, spinner *spinners.Spinner, cl *k8s.Clientset, operator *common.Operator, waitForCleared bool) {
+func uninstallKeda(ctx context.Context, spinner *spinners.Spinner, cl k8s.Interface, operator *common.Operator, waitForCleared bool) {
 	ctx, done := context.WithCancel(ctx)
 	defer done()
 
@@ -396,45 +406,55 @@ func uninstallKeda(ctx context.Context, spinner *spinners.Spinner, cl *k8s.Clien
 	spinner.Done()
 }
 
-func uninstallKnativeServing(ctx context.Context, spinner *spinners.Spinner, cl *k8s.Clientset, operator *common.Operator, waitForCleared bool) {
+func uninstallDefaultDomain(ctx context.Context, spinner *spinners.Spinner, cl k8s.Interface, operator *common.Operator, waitForCleared bool) {
 	ctx, done := context.WithCancel(ctx)
 	defer done()
 
-	if operator.Records.DefaultDomain != "" {
-		spinner.Update("Uninstalling Serving Default Domain...")
-		yamls, err := operator.Inventory[inventory.ServingDefaultDomainName].GetYamlFile(operator.Records.DefaultDomain)
-		if err != nil {
-			spinner.Error(errors.Wrap(err, "Failed to get yaml file"))
-			return
-		}
-
-		if err := operator.Uninstall(ctx, cl, yamls["MAIN"], common.KnativeServingNamespace, false, waitForCleared); err != nil {
-			spinner.Error(errors.Wrap(err, "Failed to uninstall Serving Default Domain"))
-			return
-		}
+	spinner.Update("Uninstalling...")
+	yamls, err := operator.Inventory[inventory.ServingDefaultDomainName].GetYamlFile(operator.Records.DefaultDomain)
+	if err != nil {
+		spinner.Error(errors.Wrap(err, "Failed to get yaml file"))
+		return
+	}
 
-		// Reset version to null
-		operator.Records.DefaultDomain = ""
+	if err := operator.Uninstall(ctx, cl, yamls["MAIN"], common.KnativeServingNamespace, false, waitForCleared); err != nil {
+		spinner.Error(errors.Wrap(err, "Failed to uninstall Serving Default Domain"))
+		return
 	}
 
-	if operator.Records.Kourier != "" {
-		spinner.Update("Uninstalling Kourier...")
-		yamls, err := operator.Inventory[inventory.KourierName].GetYamlFile(operator.Records.Kourier)
-		if err != nil {
-			spinner.Error(errors.Wrap(err, "Failed to get yaml file"))
-			return
-		}
+	// Reset version to null
+	operator.Records.DefaultDomain = ""
 
-		if err := operator.Uninstall(ctx, cl, yamls["MAIN"], common.KedaNamespace, true, waitForCleared); err != nil {
-			spinner.Error(errors.Wrap(err, "Failed to uninstall Kourier"))
-			return
-		}
+	spinner.Done()
+}
+
+func uninstallKourier(ctx context.Context, spinner *spinners.Spinner, cl k8s.Interface, operator *common.Operator, waitForCleared bool) {
+	ctx, done := context.WithCancel(ctx)
+	defer done()
+
+	spinner.Update("Uninstalling...")
+	yamls, err := operator.Inventory[inventory.KourierName].GetYamlFile(operator.Records.Kourier)
+	if err != nil {
+		spinner.Error(errors.Wrap(err, "Failed to get yaml file"))
+		return
+	}
 
-		// Reset version to null
-		operator.Records.Kourier = ""
+	if err := operator.Uninstall(ctx, cl, yamls["MAIN"], common.KourierNamespace, true, waitForCleared); err != nil {
+		spinner.Error(errors.Wrap(err, "Failed to uninstall Kourier"))
+		return
 	}
 
-	spinner.Update("Uninstalling Knative Serving...")
+	// Reset version to null
+	operator.Records.Kourier = ""
+
+	spinner.Done()
+}
+
+func uninstallKnativeServing(ctx context.Context, spinner *spinners.Spinner, cl k8s.Interface, operator *common.Operator, waitForCleared bool) {
+	ctx, done := context.WithCancel(ctx)
+	defer done()
+
+	spinner.Update("Uninstalling...")
 	yamls, err := operator.Inventory[inventory.KnativeServingName].GetYamlFile(operator.Records.KnativeServing)
 	if err != nil {
 		spinner.Error(errors.Wrap(err, "Failed to get yaml file"))
@@ -452,7 +472,7 @@ func uninstallKnativeServing(ctx context.Context, spinner *spinners.Spinner, cl
 	spinner.Done()
 }
 
-func uninstallShipwright(ctx context.Context, spinner *spinners.Spinner, cl *k8s.Clientset, operator *common.Operator, waitForCleared bool) {
+func uninstallShipwright(ctx context.Context, spinner *spinners.Spinner, cl k8s.Interface, operator *common.Operator, waitForCleared bool) {
 	ctx, done := context.WithCancel(ctx)
 	defer done()
 
@@ -474,7 +494,7 @@ func uninstallShipwright(ctx context.Context, spinner *spinners.Spinner, cl *k8s
 	spinner.Done()
 }
 
-func uninstallCertManager(ctx context.Context, spinner *spinners.Spinner, cl *k8s.Clientset, operator *common.Operator, waitForCleared bool) {
+func uninstallCertManager(ctx context.Context, spinner *spinners.Spinner, cl k8s.Interface, operator *common.Operator, waitForCleared bool) {
 	ctx, done := context.WithCancel(ctx)
 	defer done()
 
@@ -496,7 +516,7 @@ func uninstallCertManager(ctx context.Context, spinner *spinners.Spinner, cl *k8
 	spinner.Done()
 }
 
-func uninstallTektonPipelines(ctx context.Context, spinner *spinners.Spinner, cl *k8s.Clientset, operator *common.Operator, waitForCleared bool) {
+func uninstallTektonPipelines(ctx context.Context, spinner *spinners.Spinner, cl k8s.Interface, operator *common.Operator, waitForCleared bool) {
 	ctx, done := context.WithCancel(ctx)
 	defer done()
 
@@ -518,7 +538,7 @@ func uninstallTektonPipelines(ctx context.Context, spinner *spinners.Spinner, cl
 	spinner.Done()
 }
 
-func uninstallIngress(ctx context.Context, spinner *spinners.Spinner, cl *k8s.Clientset, operator *common.Operator, waitForCleared bool) {
+func uninstallIngress(ctx context.Context, spinner *spinners.Spinner, cl k8s.Interface, operator *common.Operator, waitForCleared bool) {
 	ctx, done := context.WithCancel(ctx)
 	defer done()
 
@@ -540,7 +560,7 @@ func uninstallIngress(ctx context.Context, spinner *spinners.Spinner, cl *k8s.Cl
 	spinner.Done()
 }
 
-func uninstallOpenFunction(ctx context.Context, spinner *spinners.Spinner, cl *k8s.Clientset, operator *common.Operator, waitForCleared bool) {
+func uninstallOpenFunction(ctx context.Context, spinner *spinners.Spinner, cl k8s.Interface, operator *common.Operator, waitForCleared bool) {
 	ctx, done := context.WithCancel(ctx)
 	defer done()
 
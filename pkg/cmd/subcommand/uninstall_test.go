@@ -3,7 +3,16 @@ package subcommand
 import (
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/OpenFunction/cli/pkg/components/common"
+	"github.com/OpenFunction/cli/pkg/components/config"
+	"github.com/OpenFunction/cli/pkg/components/fake"
+	"github.com/OpenFunction/cli/pkg/components/inventory"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 )
 
@@ -91,3 +100,159 @@ func TestUninstallCalculateConditions(t *testing.T) {
 		}
 	}
 }
+
+func TestRunUninstall(t *testing.T) {
+	allRecorded := &inventory.Record{
+		OpenFunction:    "0.6.0",
+		KnativeServing:  inventory.DefaultKnativeServingVersionOnK8Sv120,
+		Kourier:         inventory.DefaultKourierVersionOnK8Sv120,
+		DefaultDomain:   inventory.DefaultServingDefaultDomainVersionOnK8Sv120,
+		Keda:            inventory.DefaultKedaVersion,
+		Dapr:            inventory.DefaultDaprVersion,
+		TektonPipelines: inventory.DefaultTektonPipelinesVersionOnK8Sv120,
+		Shipwright:      inventory.DefaultShipwrightVersion,
+		CertManager:     inventory.DefaultCertManagerVersion,
+		Ingress:         inventory.DefaultIngressNginxVersion,
+	}
+	kedaSource := installSource(t, inventory.KedaName, "MAIN")
+
+	tests := []struct {
+		name           string
+		waitForCleared bool
+		timeout        time.Duration
+		objs           []runtime.Object
+		errors         map[string]error
+		config         *config.InstallConfig
+		// record is the inventory record before the uninstallation.
+		record *inventory.Record
+
+		wantUninstalled []string
+		wantKept        []string
+		wantCalls       []string
+		wantRecord      map[string]string
+		wantErr         string
+	}{
+		{
+			name:            "all recorded components",
+			record:          allRecorded,
+			wantUninstalled: []string{inventory.DaprName, inventory.KedaName, inventory.KnativeServingName, inventory.KourierName, inventory.ServingDefaultDomainName, inventory.TektonPipelinesName, inventory.ShipwrightName, inventory.CertManagerName, inventory.IngressName, inventory.OpenFunctionName},
+			wantRecord:      map[string]string{},
+		},
+		{
+			name:            "unrecorded components kept",
+			record:          &inventory.Record{OpenFunction: "0.6.0", Keda: inventory.DefaultKedaVersion},
+			wantUninstalled: []string{inventory.KedaName, inventory.OpenFunctionName},
+			wantKept:        []string{inventory.DaprName, inventory.KnativeServingName, inventory.ShipwrightName},
+			wantRecord:      map[string]string{},
+		},
+		{
+			name:            "failure recorded",
+			errors:          map[string]error{"delete " + kedaSource: errors.New("connection refused")},
+			record:          &inventory.Record{OpenFunction: "0.6.0", Keda: inventory.DefaultKedaVersion},
+			wantUninstalled: []string{inventory.OpenFunctionName},
+			wantRecord:      map[string]string{inventory.KedaName: inventory.DefaultKedaVersion},
+			wantErr:         "Failed to uninstall Keda: connection refused",
+		},
+		{
+			name:            "timeout waiting for the namespace to be cleared",
+			waitForCleared:  true,
+			timeout:         300 * time.Millisecond,
+			objs:            []runtime.Object{&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: common.KedaNamespace}}},
+			record:          &inventory.Record{Keda: inventory.DefaultKedaVersion},
+			wantUninstalled: []string{inventory.KedaName},
+			wantRecord:      map[string]string{inventory.KedaName: inventory.DefaultKedaVersion},
+			wantErr:         "Failed to uninstall Keda",
+		},
+		{
+			name:           "namespaces moved by the config",
+			waitForCleared: true,
+			timeout:        300 * time.Millisecond,
+			// The namespaces of the manifests are left alone, only the ones the components have been moved to are waited for.
+			objs: []runtime.Object{&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: common.KedaNamespace}}},
+			config: &config.InstallConfig{Components: map[string]*config.Component{
+				inventory.KedaRecordName: {Namespace: "autoscaling"},
+				inventory.DaprRecordName: {Namespace: "dapr"},
+			}},
+			record:          &inventory.Record{Keda: inventory.DefaultKedaVersion, Dapr: inventory.DefaultDaprVersion},
+			wantUninstalled: []string{inventory.KedaName},
+			wantCalls:       []string{"exec dapr uninstall -k --all --namespace dapr"},
+			wantRecord:      map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ioStreams, _, _, _ := genericclioptions.NewTestIOStreams()
+			u := NewUninstall(ioStreams)
+			u.Runtimes = []string{"knative", "async"}
+			u.WithAll = true
+			u.OpenFunctionVersion = "v0.6.0"
+			u.WaitForCleared = tt.waitForCleared
+			u.Yes = true
+			u.Timeout = time.Minute
+			u.config = tt.config
+			if tt.timeout != 0 {
+				u.Timeout = tt.timeout
+			}
+			if err := u.ValidateArgs(); err != nil {
+				t.Fatal(err)
+			}
+
+			executor := fake.NewExecutor()
+			executor.Record = tt.record
+			for call, err := range tt.errors {
+				executor.Errors[call] = err
+			}
+			operator := common.NewOperatorWithExecutor(executor, u.OpenFunctionVersion, u.Timeout, false, false)
+			operator.CheckInterval = 10 * time.Millisecond
+
+			err := u.runUninstall(newInstallClientset(tt.objs...), operator)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			calls := executor.Calls()
+			for _, call := range tt.wantCalls {
+				if !strings.Contains(strings.Join(calls, "\n"), call) {
+					t.Errorf("%q isn't called, calls: %q", call, calls)
+				}
+			}
+			positions := componentCalls(t, operator.Inventory, tt.record, calls)
+			for _, name := range tt.wantUninstalled {
+				if len(positions[name]) == 0 {
+					t.Errorf("%s isn't uninstalled, calls: %q", name, calls)
+				}
+			}
+			for _, name := range tt.wantKept {
+				if len(positions[name]) != 0 {
+					t.Errorf("%s is uninstalled, calls: %q", name, calls)
+				}
+			}
+			// A component is uninstalled after the components depending on it.
+			for name, iv := range operator.Inventory {
+				for _, dep := range iv.GetDependencies() {
+					if len(positions[name]) == 0 || len(positions[dep]) == 0 {
+						continue
+					}
+					if last := positions[name][len(positions[name])-1]; last > positions[dep][0] {
+						t.Errorf("%s is uninstalled before %s depending on it, calls: %q", dep, name, calls)
+					}
+				}
+			}
+
+			record := executor.Record.ToMap(true)
+			for name, v := range tt.wantRecord {
+				if record[name] != v {
+					t.Errorf("record of %s = %q, want %q", name, record[name], v)
+				}
+			}
+			if len(record) != len(tt.wantRecord) {
+				t.Errorf("record = %v, want %v", record, tt.wantRecord)
+			}
+		})
+	}
+}
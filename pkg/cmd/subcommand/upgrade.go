@@ -0,0 +1,525 @@
+package subcommand
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/OpenFunction/cli/pkg/cmd/util"
+	"github.com/OpenFunction/cli/pkg/cmd/util/client"
+	"github.com/OpenFunction/cli/pkg/cmd/util/spinners"
+	"github.com/OpenFunction/cli/pkg/components/common"
+	"github.com/OpenFunction/cli/pkg/components/inventory"
+	"github.com/OpenFunction/cli/pkg/components/scheduler"
+	"github.com/OpenFunction/cli/pkg/components/upgrade"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/dynamic"
+	k8s "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	stepNameTmpl = "%d/%d %s"
+
+	stepPending = "pending"
+	stepDone    = "done"
+)
+
+// Upgrade is the commandline for 'upgrade plan' and 'upgrade apply' sub commands
+type Upgrade struct {
+	genericclioptions.IOStreams
+
+	OpenFunctionVersion string
+	RegionCN            bool
+	Restart             bool
+	Verbose             bool
+	Yes                 bool
+	Timeout             time.Duration
+}
+
+// NewUpgrade returns an initialized Upgrade instance
+func NewUpgrade(ioStreams genericclioptions.IOStreams) *Upgrade {
+	return &Upgrade{
+		IOStreams: ioStreams,
+	}
+}
+
+func NewCmdUpgrade(cf *genericclioptions.ConfigFlags, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "upgrade",
+		DisableFlagsInUseLine: true,
+		Short:                 "Plan and apply the upgrade of OpenFunction and its dependencies.",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(newCmdUpgradePlan(cf, ioStreams))
+	cmd.AddCommand(newCmdUpgradeApply(cf, ioStreams))
+	return cmd
+}
+
+func newCmdUpgradePlan(cf *genericclioptions.ConfigFlags, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	var cl k8s.Interface
+
+	u := NewUpgrade(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:                   "plan [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Show the steps upgrading OpenFunction and its dependencies to a target version.",
+		Long: `This command will compare the versions running in the cluster and the versions in the inventory record
+with the versions of the target version of OpenFunction, and print the ordered steps of the upgrade.
+If an upgrade is in progress, its plan and the steps which have been applied are printed instead.`,
+		Example: `
+# Plan the upgrade to the latest stable version of OpenFunction
+ofn upgrade plan
+
+# Plan the upgrade to a specific version of OpenFunction
+ofn upgrade plan --version v0.6.0
+`,
+		PreRunE: func(cmd *cobra.Command, args []string) (err error) {
+			_, cl, err = client.NewKubeConfigClient(cf)
+			if err != nil {
+				return err
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(u.ValidateArgs())
+			util.CheckErr(u.RunPlan(cf, cl))
+		},
+	}
+
+	cmd.Flags().StringVar(&u.OpenFunctionVersion, "version", "", "The version of OpenFunction to upgrade to, defaults to the latest stable version.")
+	cmd.Flags().BoolVar(&u.RegionCN, "region-cn", false, "For users who have limited access to gcr.io or github.com.")
+	cmd.Flags().DurationVar(&u.Timeout, "timeout", time.Minute, "Set timeout time. Default is 1 minute.")
+	return cmd
+}
+
+func newCmdUpgradeApply(cf *genericclioptions.ConfigFlags, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	var config *rest.Config
+	var cl k8s.Interface
+
+	u := NewUpgrade(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:                   "apply [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Upgrade OpenFunction and its dependencies step by step.",
+		Long: `This command will apply the steps of the upgrade plan one after another.
+The progress is saved in the cluster after each step, so an upgrade which has failed or has been interrupted
+is resumed from the first step which hasn't been applied by running this command again.`,
+		Example: `
+# Upgrade to the latest stable version of OpenFunction
+ofn upgrade apply
+
+# Upgrade to a specific version of OpenFunction without prompts
+ofn upgrade apply --version v0.6.0 -y
+
+# Discard the upgrade in progress and plan again
+ofn upgrade apply --restart
+`,
+		PreRunE: func(cmd *cobra.Command, args []string) (err error) {
+			config, cl, err = client.NewKubeConfigClient(cf)
+			if err != nil {
+				return err
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(u.ValidateArgs())
+			util.CheckErr(u.RunApply(cf, config, cl))
+		},
+	}
+
+	cmd.Flags().StringVar(&u.OpenFunctionVersion, "version", "", "The version of OpenFunction to upgrade to, defaults to the latest stable version.")
+	cmd.Flags().BoolVar(&u.RegionCN, "region-cn", false, "For users who have limited access to gcr.io or github.com.")
+	cmd.Flags().BoolVar(&u.Restart, "restart", false, "Discard the upgrade in progress and plan again, the Functions it has backed up are kept.")
+	cmd.Flags().BoolVar(&u.Verbose, "verbose", false, "Show verbose information.")
+	cmd.Flags().BoolVarP(&u.Yes, "yes", "y", false, "Automatic yes to prompts.")
+	cmd.Flags().DurationVar(&u.Timeout, "timeout", 30*time.Minute, "Set timeout time. Default is 30 minutes.")
+	return cmd
+}
+
+func (u *Upgrade) ValidateArgs() error {
+	if u.OpenFunctionVersion == "" || u.OpenFunctionVersion == common.LatestVersion {
+		return nil
+	}
+
+	v, err := version.ParseGeneric(u.OpenFunctionVersion)
+	if err != nil {
+		return errors.Errorf("the specified version %s is not a valid version", u.OpenFunctionVersion)
+	}
+	if valid, err := common.IsVersionValid(v); err != nil {
+		return err
+	} else if !valid {
+		return errors.Errorf(
+			"the specified version %s is lower than the supported version %s",
+			u.OpenFunctionVersion,
+			common.BaseVersion,
+		)
+	}
+	return nil
+}
+
+func (u *Upgrade) RunPlan(cf *genericclioptions.ConfigFlags, cl k8s.Interface) error {
+	ctx, done := context.WithTimeout(
+		context.Background(),
+		u.Timeout,
+	)
+	defer done()
+
+	checkpoint, err := upgrade.LoadCheckpoint(ctx, cl)
+	if err != nil {
+		return errors.Wrap(err, "failed to load the upgrade checkpoint")
+	}
+	if checkpoint != nil {
+		util.BeforeTask(fmt.Sprintf("An upgrade to OpenFunction %s is in progress:", checkpoint.Plan.Target))
+		u.printPlan(checkpoint.Plan)
+		return nil
+	}
+
+	operator := common.NewOperator(cf, runtime.GOOS, runtime.GOARCH, u.OpenFunctionVersion, u.Timeout, u.RegionCN, false)
+	plan, err := u.newPlan(ctx, cl, operator)
+	if err != nil {
+		return err
+	}
+	if len(plan.Steps) == 0 {
+		util.BeforeTask(fmt.Sprintf("OpenFunction and its dependencies are up to date with OpenFunction %s.", plan.Target))
+		u.printWarnings(plan)
+		return nil
+	}
+
+	u.printPlan(plan)
+	return nil
+}
+
+func (u *Upgrade) RunApply(cf *genericclioptions.ConfigFlags, config *rest.Config, cl k8s.Interface) error {
+	ctx, done := context.WithTimeout(
+		context.Background(),
+		u.Timeout,
+	)
+	defer done()
+
+	dc, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	checkpoint, err := upgrade.LoadCheckpoint(ctx, cl)
+	if err != nil {
+		return errors.Wrap(err, "failed to load the upgrade checkpoint")
+	}
+	if checkpoint != nil && !u.Restart && u.OpenFunctionVersion != "" &&
+		!sameVersion(u.OpenFunctionVersion, checkpoint.Plan.Target) {
+		return errors.Errorf(
+			"an upgrade to OpenFunction %s is in progress, run 'ofn upgrade apply' to resume it or add '--restart' to discard it",
+			checkpoint.Plan.Target,
+		)
+	}
+
+	if checkpoint == nil || u.Restart {
+		if checkpoint == nil {
+			checkpoint = &upgrade.Checkpoint{}
+		}
+
+		operator := common.NewOperator(cf, runtime.GOOS, runtime.GOARCH, u.OpenFunctionVersion, u.Timeout, u.RegionCN, false)
+		plan, err := u.newPlan(ctx, cl, operator)
+		if err != nil {
+			return err
+		}
+		// The Functions backed up by the discarded plan are restored by the new one.
+		if len(checkpoint.Functions) != 0 {
+			if err := plan.RestoreBackups(); err != nil {
+				return errors.Wrap(err, "failed to restart the upgrade")
+			}
+		}
+		if len(plan.Steps) == 0 {
+			util.BeforeTask(fmt.Sprintf("OpenFunction and its dependencies are up to date with OpenFunction %s.", plan.Target))
+			u.printWarnings(plan)
+			return checkpoint.Delete(ctx, cl)
+		}
+
+		u.printPlan(plan)
+		if !u.Yes && !confirm("Enter 'y' to apply the plan and 'n' to abort:") {
+			return nil
+		}
+
+		checkpoint.Plan = plan
+		if err := checkpoint.Save(ctx, cl); err != nil {
+			return err
+		}
+	} else {
+		util.BeforeTask(fmt.Sprintf("Resuming the upgrade to OpenFunction %s:", checkpoint.Plan.Target))
+		u.printPlan(checkpoint.Plan)
+	}
+
+	plan := checkpoint.Plan
+	operator := common.NewOperator(cf, runtime.GOOS, runtime.GOARCH, plan.Target, u.Timeout, u.RegionCN, u.Verbose)
+	inv, err := inventory.GetInventory(cl, u.RegionCN, true, true, true, true, true, true, plan.Target)
+	if err != nil {
+		return errors.Wrap(err, "failed to get inventory")
+	}
+	operator.Inventory = inv
+	if _, err := operator.GetInventoryRecord(ctx, false); err != nil {
+		return errors.Wrap(err, "failed to get inventory record")
+	}
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-c
+		done()
+	}()
+
+	start := time.Now()
+
+	// The steps run one after another, each of them depends on the previous one.
+	sched := scheduler.NewScheduler()
+	prev := ""
+	for idx := plan.Completed; idx < len(plan.Steps); idx++ {
+		idx, step := idx, plan.Steps[idx]
+		name := stepName(idx, plan)
+
+		var deps []string
+		if prev != "" {
+			deps = []string{prev}
+		}
+		sched.Add(name, deps, func(ctx context.Context, spinner *spinners.Spinner) {
+			if !u.applyStep(ctx, spinner, cl, dc, operator, checkpoint, step) {
+				return
+			}
+
+			plan.Completed = idx + 1
+			if err := checkpoint.Save(ctx, cl); err != nil {
+				spinner.Error(err)
+				return
+			}
+			spinner.Done()
+		})
+		prev = name
+	}
+	if err := sched.Run(ctx); err != nil {
+		return errors.New(util.TaskFail(fmt.Sprintf("%s\nrun 'ofn upgrade apply' to resume the upgrade", err)))
+	}
+
+	if err := checkpoint.Delete(ctx, cl); err != nil {
+		return errors.Wrap(err, "failed to delete the upgrade checkpoint")
+	}
+
+	end := time.Since(start)
+	util.AllDone(end)
+	return nil
+}
+
+// newPlan returns the plan upgrading the components running in the cluster to the version of OpenFunction.
+func (u *Upgrade) newPlan(ctx context.Context, cl k8s.Interface, operator *common.Operator) (*upgrade.Plan, error) {
+	if u.OpenFunctionVersion == "" {
+		if v, err := getLatestStableVersion(); err != nil {
+			return nil, errors.Errorf("failed to fetch OpenFunction latest release, %s, use '--version' to specify the version of OpenFunction", err.Error())
+		} else {
+			u.OpenFunctionVersion = v
+		}
+	}
+
+	installed, err := operator.DetectComponents(ctx, cl)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to detect components")
+	}
+	recorded, err := operator.GetInventoryRecord(ctx, true)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get inventory record")
+	}
+	current := upgrade.CurrentVersions(installed, recorded)
+
+	inv, err := inventory.GetInventory(cl, u.RegionCN, true, true, true, true, true, true, u.OpenFunctionVersion)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get inventory")
+	}
+	target := selectUpgradeTarget(inv, current)
+
+	plan, err := upgrade.NewPlan(current, recorded, target)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to plan the upgrade")
+	}
+	return plan, nil
+}
+
+// selectUpgradeTarget returns the components of inv which are upgraded, they are the installed components,
+// OpenFunction and the components OpenFunction requires, i.e. Cert Manager since v0.4.0.
+func selectUpgradeTarget(inv map[string]inventory.Interface, current map[string]string) map[string]inventory.Interface {
+	target := map[string]inventory.Interface{}
+	for name, iv := range inv {
+		if _, ok := current[name]; ok || name == inventory.OpenFunctionName {
+			target[name] = iv
+		}
+	}
+
+	if of, ok := inv[inventory.OpenFunctionName]; ok {
+		for _, dep := range of.GetDependencies() {
+			if dep == inventory.CertManagerName && inv[dep] != nil {
+				target[dep] = inv[dep]
+			}
+		}
+	}
+	return target
+}
+
+// applyStep applies a step of the plan and returns true if it succeeds, the failure is reported by the spinner.
+func (u *Upgrade) applyStep(
+	ctx context.Context,
+	spinner *spinners.Spinner,
+	cl k8s.Interface,
+	dc dynamic.Interface,
+	operator *common.Operator,
+	checkpoint *upgrade.Checkpoint,
+	step *upgrade.Step,
+) bool {
+	spinner.Update(step.Description + "...")
+
+	var err error
+	switch step.Type {
+	case upgrade.CRDMigration:
+		var fns []*unstructured.Unstructured
+		if fns, err = upgrade.BackupFunctions(ctx, dc, step.From); err != nil {
+			break
+		}
+		// The Functions must be kept before the CRDs are deleted.
+		checkpoint.Functions = append(checkpoint.Functions, fns...)
+		if err = checkpoint.Save(ctx, cl); err != nil {
+			break
+		}
+		err = upgrade.DeleteCRDs(ctx, dc)
+	case upgrade.ComponentUpgrade:
+		c, ok := registry[step.Component]
+		if !ok {
+			err = errors.Errorf("unknown component: %s", step.Component)
+			break
+		}
+		switch {
+		case step.Component == inventory.OpenFunctionName:
+			err = deployOpenFunction(ctx, spinner, cl, operator, operator.UpgradeOpenFunction)
+		case step.Component == inventory.DaprName && step.From != "":
+			err = upgradeDapr(ctx, spinner, operator)
+		default:
+			err = c.install(ctx, spinner, cl, operator)
+		}
+		if err != nil {
+			spinner.Error(err)
+			return false
+		}
+		err = operator.RecordInventory(ctx)
+	case upgrade.KnativeURLScheme:
+		err = upgrade.MigrateKnativeNetwork(ctx, cl, operator.Namespace(common.KnativeServingNamespace))
+	case upgrade.FunctionConversion:
+		if step.From == upgrade.FunctionV1alpha1 {
+			if err = upgrade.RestoreFunctions(ctx, dc, checkpoint.Functions, step.To); err == nil {
+				checkpoint.Functions = nil
+			}
+		} else {
+			err = upgrade.MigrateStorage(ctx, dc, step.To)
+		}
+	default:
+		err = errors.Errorf("unknown step: %s", step.Type)
+	}
+
+	if err != nil {
+		spinner.Error(errors.Wrapf(err, "Failed to %s", lowerFirst(step.Description)))
+		return false
+	}
+	return true
+}
+
+func upgradeDapr(ctx context.Context, spinner *spinners.Spinner, operator *common.Operator) error {
+	v := operator.Inventory[inventory.DaprName].GetVersion()
+
+	spinner.Update("Downloading Dapr CLI...")
+	if err := operator.DownloadDaprClient(ctx, v); err != nil {
+		return errors.Wrap(err, "Failed to download Dapr CLI")
+	}
+
+	spinner.Update("Upgrading Dapr with Kubernetes mode...")
+	if err := operator.UpgradeDapr(ctx, v); err != nil {
+		return errors.Wrap(err, "Failed to upgrade Dapr")
+	}
+
+	// Record the version of Dapr
+	operator.Records.Dapr = v
+
+	return nil
+}
+
+func (u *Upgrade) printPlan(plan *upgrade.Plan) {
+	t := table.NewWriter()
+	t.SetOutputMirror(u.Out)
+	t.AppendHeader(table.Row{"#", "Type", "Step", "Status"})
+	for idx, step := range plan.Steps {
+		status := stepPending
+		if idx < plan.Completed {
+			status = stepDone
+		}
+		t.AppendRow(table.Row{idx + 1, step.Type, step.Description, status})
+	}
+	t.Render()
+	u.printWarnings(plan)
+}
+
+func (u *Upgrade) printWarnings(plan *upgrade.Plan) {
+	for _, w := range plan.Warnings {
+		fmt.Fprintln(u.ErrOut, util.YellowItalic(w))
+	}
+}
+
+func stepName(idx int, plan *upgrade.Plan) string {
+	step := plan.Steps[idx]
+	subject := step.Component
+	if step.Type != upgrade.ComponentUpgrade {
+		subject = string(step.Type)
+	}
+	return fmt.Sprintf(stepNameTmpl, idx+1, len(plan.Steps), subject)
+}
+
+// sameVersion returns true if the versions are the same regardless of their forms, e.g. v0.6.0 and 0.6.0.
+func sameVersion(a string, b string) bool {
+	if a == b {
+		return true
+	}
+	na, nb := common.NormalizeVersion(a), common.NormalizeVersion(b)
+	return na != "" && na == nb
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+func confirm(prompt string) bool {
+	reader := bufio.NewReader(os.Stdin)
+	util.BeforeTask(prompt)
+	for {
+		fmt.Print(util.YellowItalic("-> "))
+		text, _ := reader.ReadString('\n')
+		text = strings.TrimSpace(text)
+
+		if text == "y" {
+			return true
+		}
+		if text == "n" {
+			return false
+		}
+	}
+}
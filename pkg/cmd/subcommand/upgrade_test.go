@@ -0,0 +1,89 @@
+package subcommand
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OpenFunction/cli/pkg/cmd/util/spinners"
+	"github.com/OpenFunction/cli/pkg/components/common"
+	"github.com/OpenFunction/cli/pkg/components/fake"
+	"github.com/OpenFunction/cli/pkg/components/inventory"
+	"github.com/OpenFunction/cli/pkg/components/upgrade"
+	"github.com/pkg/errors"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func TestApplyComponentUpgrade(t *testing.T) {
+	inv, err := inventory.GetInventoryWithServerVersion("v1.21.0", false, false, true, false, false, false, false, "v0.6.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inv = map[string]inventory.Interface{inventory.KedaName: inv[inventory.KedaName]}
+	yamls, err := inv[inventory.KedaName].GetYamlFile(inv[inventory.KedaName].GetVersion())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		errors  map[string]error
+		want    bool
+		wantErr string
+	}{
+		{
+			name: "upgraded",
+			want: true,
+		},
+		{
+			name:    "install failed",
+			errors:  map[string]error{"apply " + yamls["MAIN"]: errors.New("denied")},
+			wantErr: "Failed to install Keda: denied",
+		},
+		{
+			name:    "record failed",
+			errors:  map[string]error{"record-inventory": errors.New("conflict")},
+			wantErr: "Failed to upgrade Keda from 2.4.0 to 2.5.0: conflict",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := fake.NewExecutor()
+			for call, err := range tt.errors {
+				executor.Errors[call] = err
+			}
+			operator := common.NewOperatorWithExecutor(executor, "v0.6.0", time.Minute, false, false)
+			operator.CheckInterval = 10 * time.Millisecond
+			operator.Inventory = inv
+			operator.Records = &inventory.Record{Keda: "2.4.0"}
+
+			spinner := spinners.NewSpinnerGroupWithSize(1).At(0)
+			step := &upgrade.Step{
+				Type:        upgrade.ComponentUpgrade,
+				Component:   inventory.KedaName,
+				From:        "2.4.0",
+				To:          "2.5.0",
+				Description: "Upgrade Keda from 2.4.0 to 2.5.0",
+			}
+			ioStreams, _, _, _ := genericclioptions.NewTestIOStreams()
+			cl := newInstallClientset(newInstallDeployment(common.KedaNamespace, "keda-operator", true))
+
+			got := NewUpgrade(ioStreams).applyStep(context.Background(), spinner, cl, nil, operator, &upgrade.Checkpoint{}, step)
+			if got != tt.want {
+				t.Fatalf("want %v, got %v", tt.want, got)
+			}
+			if tt.want {
+				// The spinner is completed by the caller once the checkpoint has been saved.
+				if !spinner.IsActive() {
+					t.Error("want the spinner to be left running")
+				}
+				return
+			}
+			if !spinner.Failed() || spinner.Err() == nil || !strings.HasPrefix(spinner.Err().Error(), tt.wantErr) {
+				t.Errorf("want the spinner failed with %q, got %v", tt.wantErr, spinner.Err())
+			}
+		})
+	}
+}
@@ -0,0 +1,347 @@
+package subcommand
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/OpenFunction/cli/pkg/cmd/util"
+	cc "github.com/OpenFunction/cli/pkg/cmd/util/client"
+	openfunction "github.com/openfunction/apis/core/v1beta1"
+	client "github.com/openfunction/pkg/client/clientset/versioned"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+)
+
+const (
+	waitForBuild   = "build"
+	waitForServing = "serving"
+)
+
+// waitCondition is a state of the build or the serving of a function to wait for.
+type waitCondition struct {
+	Phase string
+	State string
+}
+
+func (c waitCondition) String() string {
+	return fmt.Sprintf("%s=%s", c.Phase, c.State)
+}
+
+// Wait is the commandline for 'wait' sub command
+type Wait struct {
+	genericclioptions.IOStreams
+
+	Name    string
+	For     []string
+	Timeout time.Duration
+
+	namespace  string
+	conditions []waitCondition
+}
+
+const (
+	waitExample = `
+# Wait for the function sample to be built and running
+ofn wait function sample
+
+# Wait for the build of the function sample to succeed for at most 10 minutes
+ofn wait function sample --for=build=Succeeded --timeout 10m
+
+# Create a function and wait for it to be running
+ofn create -f function.yaml && ofn wait fn sample --for=serving=Running
+`
+)
+
+var (
+	defaultWaitConditions = []string{
+		waitCondition{Phase: waitForBuild, State: openfunction.Succeeded}.String(),
+		waitCondition{Phase: waitForServing, State: openfunction.Running}.String(),
+	}
+
+	// The states in which the build or the serving won't reach another state by itself.
+	buildFailedStates   = []string{openfunction.Failed, openfunction.Timeout, openfunction.Canceled, openfunction.BuilderStateCancelled}
+	servingFailedStates = []string{openfunction.Failed, openfunction.UnknownRuntime}
+)
+
+// NewWait returns an initialized Wait instance
+func NewWait(ioStreams genericclioptions.IOStreams) *Wait {
+	return &Wait{
+		IOStreams: ioStreams,
+	}
+}
+
+func NewCmdWait(cf *genericclioptions.ConfigFlags, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "wait",
+		DisableFlagsInUseLine: true,
+		Short:                 "Wait for a function to reach a state",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(newCmdWaitFunction(cf, ioStreams))
+	return cmd
+}
+
+func newCmdWaitFunction(cf *genericclioptions.ConfigFlags, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	var fc client.Interface
+
+	w := NewWait(ioStreams)
+	cmd := &cobra.Command{
+		Use:                   "function NAME [--for=build|serving=STATE]... [--timeout DURATION]",
+		Aliases:               []string{"functions", "fn"},
+		DisableFlagsInUseLine: true,
+		Short:                 "Wait for the build or the serving of a function to reach a state",
+		Long: `
+Wait for the build or the serving of a function to reach a state, the function doesn't need to exist yet.
+The state transitions are printed as they happen.
+
+A build which is skipped satisfies build=Succeeded.
+The command fails with the reason of the failure once the build or the serving has failed,
+unless the failed state is waited for.
+`,
+		Example: waitExample,
+		Args:    cobra.ExactArgs(1),
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			config, err := cf.ToRESTConfig()
+			if err != nil {
+				return err
+			}
+			cc.SetConfigDefaults(config)
+			fc, err = client.NewForConfig(config)
+			if err != nil {
+				return err
+			}
+
+			w.namespace, _, err = cf.ToRawKubeConfigLoader().Namespace()
+			return err
+		},
+
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(w.Complete(cmd, args))
+			util.CheckErr(w.Run(fc))
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&w.For, "for", defaultWaitConditions, "The state to wait for, in the form of build=STATE or serving=STATE, e.g. build=Succeeded, serving=Running. Can be repeated.")
+	cmd.Flags().DurationVar(&w.Timeout, "timeout", 5*time.Minute, "The length of time to wait before giving up.")
+	return cmd
+}
+
+func (w *Wait) Complete(cmd *cobra.Command, args []string) error {
+	w.Name = args[0]
+
+	conditions, err := parseWaitConditions(w.For)
+	if err != nil {
+		return util.UsageErrorf(cmd, err.Error())
+	}
+	w.conditions = conditions
+
+	if w.Timeout <= 0 {
+		return util.UsageErrorf(cmd, "--timeout must be greater than 0")
+	}
+	return nil
+}
+
+func (w *Wait) Run(fc client.Interface) error {
+	ctx, done := context.WithTimeout(context.Background(), w.Timeout)
+	defer done()
+
+	if _, err := waitForFunction(ctx, fc, w.namespace, w.Name, w.conditions, w.Out); err != nil {
+		return err
+	}
+	fmt.Fprintf(w.Out, "function.%s/%s condition met\n", openfunction.GroupVersion.Group, w.Name)
+	return nil
+}
+
+func parseWaitConditions(values []string) ([]waitCondition, error) {
+	if len(values) == 0 {
+		return nil, errors.New("--for is required")
+	}
+
+	var conditions []waitCondition
+	for _, v := range values {
+		kv := strings.SplitN(v, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return nil, errors.Errorf("invalid condition %s, must be in the form of build=STATE or serving=STATE", v)
+		}
+		phase := strings.ToLower(kv[0])
+		if phase != waitForBuild && phase != waitForServing {
+			return nil, errors.Errorf("invalid condition %s, only build and serving can be waited for", v)
+		}
+		conditions = append(conditions, waitCondition{Phase: phase, State: kv[1]})
+	}
+	return conditions, nil
+}
+
+// waitForFunction watches the function until all the conditions are met and returns it,
+// the state transitions are printed to out if it isn't nil.
+// An error is returned once the build or the serving has failed to a state which isn't waited for, or ctx is done.
+func waitForFunction(
+	ctx context.Context,
+	fc client.Interface,
+	namespace string,
+	name string,
+	conditions []waitCondition,
+	out io.Writer,
+) (*openfunction.Function, error) {
+	states := map[string]string{}
+	last, err := untilFunction(ctx, fc, namespace, name, func(fn *openfunction.Function) (bool, error) {
+		for _, phase := range []string{waitForBuild, waitForServing} {
+			cond := fn.Status.Build
+			if phase == waitForServing {
+				cond = fn.Status.Serving
+			}
+			if cond == nil || cond.State == "" || states[phase] == cond.State {
+				continue
+			}
+			states[phase] = cond.State
+			if out != nil {
+				fmt.Fprintf(out, "function.%s/%s %s: %s\n", openfunction.GroupVersion.Group, name, phase, cond.State)
+			}
+		}
+
+		// A failed state is only an error if it isn't waited for.
+		if cond := fn.Status.Build; cond != nil && containsState(buildFailedStates, cond.State) && !waitsForState(conditions, waitForBuild, cond.State) {
+			return false, errors.Errorf("the build of function %s/%s is %s: %s", namespace, name, cond.State, buildFailureReason(ctx, fc, fn))
+		}
+		if cond := fn.Status.Serving; cond != nil && containsState(servingFailedStates, cond.State) && !waitsForState(conditions, waitForServing, cond.State) {
+			return false, errors.Errorf("the serving of function %s/%s is %s, run 'ofn describe function %s' for details", namespace, name, cond.State, name)
+		}
+		return waitConditionsMet(fn, conditions)
+	})
+	if err == nil {
+		return last, nil
+	}
+
+	if ctx.Err() != nil {
+		var pending []string
+		for _, c := range conditions {
+			if met, _ := waitConditionsMet(last, []waitCondition{c}); !met {
+				pending = append(pending, c.String())
+			}
+		}
+		if last == nil {
+			return nil, errors.Errorf("timed out waiting for function %s/%s to be created", namespace, name)
+		}
+		return nil, errors.Errorf("timed out waiting for function %s/%s to meet %s, build: %s, serving: %s",
+			namespace, name, strings.Join(pending, ", "), stateOf(last.Status.Build), stateOf(last.Status.Serving))
+	}
+	return nil, err
+}
+
+// waitsForState returns true if one of the conditions is the state of the phase.
+func waitsForState(conditions []waitCondition, phase string, state string) bool {
+	for _, c := range conditions {
+		if c.Phase == phase && strings.EqualFold(c.State, state) {
+			return true
+		}
+	}
+	return false
+}
+
+// untilFunction watches the function until cond returns true or an error, or ctx is done.
+// The last version of the function seen is returned along with the error.
+func untilFunction(
+	ctx context.Context,
+	fc client.Interface,
+	namespace string,
+	name string,
+	cond func(fn *openfunction.Function) (bool, error),
+) (*openfunction.Function, error) {
+	selector := fields.OneTermEqualSelector("metadata.name", name).String()
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = selector
+			return fc.CoreV1beta1().Functions(namespace).List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = selector
+			return fc.CoreV1beta1().Functions(namespace).Watch(ctx, options)
+		},
+	}
+
+	var last *openfunction.Function
+	_, err := watchtools.UntilWithSync(ctx, lw, &openfunction.Function{}, nil, func(e watch.Event) (bool, error) {
+		fn, ok := e.Object.(*openfunction.Function)
+		if !ok || fn.Name != name {
+			return false, nil
+		}
+		if e.Type == watch.Deleted {
+			return false, errors.Errorf("function %s/%s has been deleted", namespace, name)
+		}
+		last = fn
+		return cond(fn)
+	})
+	return last, err
+}
+
+// waitConditionsMet returns true if fn meets all the conditions,
+// an error is returned if a condition can't be met because the serving is skipped.
+func waitConditionsMet(fn *openfunction.Function, conditions []waitCondition) (bool, error) {
+	if fn == nil {
+		return false, nil
+	}
+
+	for _, c := range conditions {
+		cond := fn.Status.Build
+		if c.Phase == waitForServing {
+			cond = fn.Status.Serving
+		}
+		if cond == nil {
+			return false, nil
+		}
+
+		switch {
+		case strings.EqualFold(cond.State, c.State):
+		case c.Phase == waitForBuild && cond.State == openfunction.Skipped && strings.EqualFold(c.State, openfunction.Succeeded):
+		case cond.State == openfunction.Skipped:
+			return false, errors.Errorf("the %s of function %s/%s is skipped, %s can't be met", c.Phase, fn.Namespace, fn.Name, c)
+		default:
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// buildFailureReason returns the reason of the failure of the builder of fn.
+func buildFailureReason(ctx context.Context, fc client.Interface, fn *openfunction.Function) string {
+	hint := fmt.Sprintf("run 'ofn logs %s' for details", fn.Name)
+	if fn.Status.Build.ResourceRef == "" {
+		return hint
+	}
+
+	builder, err := fc.CoreV1beta1().Builders(fn.Namespace).Get(ctx, fn.Status.Build.ResourceRef, metav1.GetOptions{})
+	if err != nil || builder.Status.Reason == "" {
+		return hint
+	}
+	return fmt.Sprintf("%s, %s", builder.Status.Reason, hint)
+}
+
+func containsState(states []string, state string) bool {
+	for _, s := range states {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+func stateOf(cond *openfunction.Condition) string {
+	if cond == nil || cond.State == "" {
+		return "<none>"
+	}
+	return cond.State
+}
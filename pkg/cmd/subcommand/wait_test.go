@@ -0,0 +1,134 @@
+package subcommand
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	openfunction "github.com/openfunction/apis/core/v1beta1"
+	fakeclient "github.com/openfunction/pkg/client/clientset/versioned/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestWaitForFunction(t *testing.T) {
+	newFunction := func(build string, serving string) *openfunction.Function {
+		fn := &openfunction.Function{ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default"}}
+		if build != "" {
+			fn.Status.Build = &openfunction.Condition{State: build, ResourceRef: "sample-builder"}
+		}
+		if serving != "" {
+			fn.Status.Serving = &openfunction.Condition{State: serving}
+		}
+		return fn
+	}
+	defaults, err := parseWaitConditions(defaultWaitConditions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		objs       []runtime.Object
+		update     *openfunction.Function
+		conditions []waitCondition
+		wantErr    string
+		wantOut    string
+	}{
+		{
+			name:       "met",
+			objs:       []runtime.Object{newFunction(openfunction.Succeeded, openfunction.Running)},
+			conditions: defaults,
+			wantOut:    "function.core.openfunction.io/sample build: Succeeded\nfunction.core.openfunction.io/sample serving: Running\n",
+		},
+		{
+			name:       "build skipped",
+			objs:       []runtime.Object{newFunction(openfunction.Skipped, openfunction.Running)},
+			conditions: defaults,
+		},
+		{
+			name:       "transition",
+			objs:       []runtime.Object{newFunction(openfunction.Building, "")},
+			update:     newFunction(openfunction.Succeeded, openfunction.Starting),
+			conditions: []waitCondition{{Phase: waitForBuild, State: "succeeded"}},
+			wantOut:    "sample build: Building\nfunction.core.openfunction.io/sample build: Succeeded\nfunction.core.openfunction.io/sample serving: Starting\n",
+		},
+		{
+			name: "build failed",
+			objs: []runtime.Object{
+				newFunction(openfunction.Failed, ""),
+				&openfunction.Builder{
+					ObjectMeta: metav1.ObjectMeta{Name: "sample-builder", Namespace: "default"},
+					Status:     openfunction.BuilderStatus{State: openfunction.Failed, Reason: "BuildRunTimeout"},
+				},
+			},
+			conditions: defaults,
+			wantErr:    "the build of function default/sample is Failed: BuildRunTimeout",
+		},
+		{
+			name:       "build failed waited for",
+			objs:       []runtime.Object{newFunction(openfunction.Timeout, "")},
+			conditions: []waitCondition{{Phase: waitForBuild, State: "timeout"}},
+			wantOut:    "function.core.openfunction.io/sample build: Timeout\n",
+		},
+		{
+			name:       "serving failed waited for",
+			objs:       []runtime.Object{newFunction(openfunction.Succeeded, openfunction.Failed)},
+			conditions: []waitCondition{{Phase: waitForServing, State: openfunction.Failed}},
+		},
+		{
+			name:       "serving skipped",
+			objs:       []runtime.Object{newFunction(openfunction.Succeeded, openfunction.Skipped)},
+			conditions: defaults,
+			wantErr:    "the serving of function default/sample is skipped",
+		},
+		{
+			name:       "timeout",
+			objs:       []runtime.Object{newFunction(openfunction.Building, "")},
+			conditions: defaults,
+			wantErr:    "timed out waiting for function default/sample to meet build=Succeeded, serving=Running, build: Building, serving: <none>",
+		},
+		{
+			name:       "not found",
+			conditions: defaults,
+			wantErr:    "timed out waiting for function default/sample to be created",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, done := context.WithTimeout(context.Background(), time.Second)
+			defer done()
+
+			fc := fakeclient.NewSimpleClientset(tt.objs...)
+			if tt.update != nil {
+				go func() {
+					time.Sleep(100 * time.Millisecond)
+					fc.CoreV1beta1().Functions("default").Update(ctx, tt.update, metav1.UpdateOptions{})
+				}()
+			}
+
+			out := &bytes.Buffer{}
+			_, err := waitForFunction(ctx, fc, "default", "sample", tt.conditions, out)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+			if !strings.HasSuffix(out.String(), tt.wantOut) {
+				t.Errorf("output = %q, want %q", out, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestParseWaitConditions(t *testing.T) {
+	for _, v := range [][]string{nil, {"build"}, {"build="}, {"deploy=Running"}} {
+		if _, err := parseWaitConditions(v); err == nil {
+			t.Errorf("parseWaitConditions(%q) succeeded, want an error", v)
+		}
+	}
+}
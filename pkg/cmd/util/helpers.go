@@ -30,6 +30,18 @@ func CheckErr(err error) {
 	}
 }
 
+// CheckErrWithCode prints a user friendly error to STDERR and exits with code
+func CheckErrWithCode(err error, code int) {
+	if err != nil {
+		fatalErrHandler(err.Error(), code)
+	}
+}
+
+// Exit exits with code without printing anything
+func Exit(code int) {
+	fatalErrHandler("", code)
+}
+
 func UsageErrorf(cmd *cobra.Command, format string, args ...interface{}) error {
 	msg := fmt.Sprintf(format, args...)
 	return fmt.Errorf("%s\nSee '%s -h' for help and examples", msg, cmd.CommandPath())
@@ -3,6 +3,7 @@ package util
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/fatih/color"
@@ -31,6 +32,7 @@ var (
 	White        = color.New(color.FgWhite).SprintFunc()
 	WhiteBold    = color.New(color.FgWhite, color.Bold).SprintFunc()
 	forceDetail  = "yaml"
+	wideOutput   = "wide"
 )
 
 type MessageLevel string
@@ -40,6 +42,8 @@ type Printer struct {
 	PrinterFunc PrinterFunc
 
 	ForceDetail bool
+	ShowLabels  bool
+	SortBy      string
 }
 
 func NewPrinter(operation string, scheme *runtime.Scheme) *Printer {
@@ -64,6 +68,16 @@ func (p *Printer) AddFlags(cmd *cobra.Command) {
 	p.PrintFlags.AddFlags(cmd)
 }
 
+// AddTableFlags adds the flags of the tables, which print the additional columns with -o wide.
+func (p *Printer) AddTableFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&p.ShowLabels, "show-labels", p.ShowLabels, "When printing, show all labels as the last column (default hide labels column)")
+	cmd.Flags().StringVar(&p.SortBy, "sort-by", p.SortBy, "If non-empty, sort list types using this field specification. The field specification is expressed as a JSONPath expression (e.g. '{.metadata.name}')")
+	if flag := cmd.Flags().Lookup("output"); flag != nil {
+		formats := append(p.PrintFlags.AllowedFormats(), wideOutput)
+		flag.Usage = fmt.Sprintf("Output format. One of: %s.", strings.Join(formats, "|"))
+	}
+}
+
 func (p *Printer) ShouldPrintObject() bool {
 	shouldPrint := false
 	output := *p.PrintFlags.OutputFormat
@@ -73,11 +87,11 @@ func (p *Printer) ShouldPrintObject() bool {
 	return shouldPrint
 }
 
-func (p *Printer) ToPrinterWitchColumn(columnLabels []string) (printers.ResourcePrinter, error) {
+func (p *Printer) ToPrinterWithTable(options printers.PrintOptions) (printers.ResourcePrinter, error) {
 	if IsToTable(p) {
-		p.SetPrinterFunc(WithTablePrinter(printers.PrintOptions{
-			ColumnLabels: columnLabels,
-		}))
+		options.Wide = options.Wide || IsWide(p)
+		options.ShowLabels = options.ShowLabels || p.ShowLabels
+		p.SetPrinterFunc(WithTablePrinter(options))
 	} else {
 		p.SetPrinterFunc(WithDefaultPrinter(""))
 	}
@@ -107,7 +121,7 @@ func WithTablePrinter(options printers.PrintOptions) PrinterFunc {
 // IsToTable if printer output format want prilnt object detail,return false
 func IsToTable(printer *Printer) bool {
 	if printer.PrintFlags.OutputFormat != nil &&
-		(*printer.PrintFlags.OutputFormat == "") &&
+		(*printer.PrintFlags.OutputFormat == "" || *printer.PrintFlags.OutputFormat == wideOutput) &&
 		!printer.ForceDetail {
 		return true
 	}
@@ -115,17 +129,39 @@ func IsToTable(printer *Printer) bool {
 	return false
 }
 
-// ToTable runtime.Object convert metav1.Table to facilitate printing
-func ToTable(tableRow TableRow, objs ...runtime.Object) (*metav1.Table, error) {
+// Columns returns the definitions of the columns printed by default
+func Columns(names ...string) []metav1.TableColumnDefinition {
+	columns := make([]metav1.TableColumnDefinition, 0, len(names))
+	for _, name := range names {
+		columns = append(columns, metav1.TableColumnDefinition{Name: name, Type: "string"})
+	}
+	return columns
+}
+
+// WideColumns returns the definitions of the columns printed only with -o wide
+func WideColumns(names ...string) []metav1.TableColumnDefinition {
+	columns := Columns(names...)
+	for i := range columns {
+		columns[i].Priority = 1
+	}
+	return columns
+}
+
+// IsWide returns true if the additional columns of the tables are printed
+func IsWide(printer *Printer) bool {
+	return IsToTable(printer) && *printer.PrintFlags.OutputFormat == wideOutput
+}
+
+// ToTable runtime.Object convert metav1.Table with the columns to facilitate printing
+func ToTable(columns []metav1.TableColumnDefinition, tableRow TableRow, objs ...runtime.Object) (*metav1.Table, error) {
 	tbRows, err := tableRows(tableRow, objs...)
 	if err != nil {
 		return nil, err
 	}
-	tb := &metav1.Table{
-		ColumnDefinitions: []metav1.TableColumnDefinition{},
+	return &metav1.Table{
+		ColumnDefinitions: columns,
 		Rows:              tbRows,
-	}
-	return tb, nil
+	}, nil
 }
 
 func tableRows(tableRow TableRow, objs ...runtime.Object) ([]metav1.TableRow, error) {
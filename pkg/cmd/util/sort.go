@@ -0,0 +1,105 @@
+package util
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// SortObjects sorts objs by the value of the field, a JSONPath expression like '{.metadata.name}' or '.metadata.name'.
+// The objects without the field come first.
+func SortObjects(objs []runtime.Object, field string) error {
+	if field == "" || len(objs) == 0 {
+		return nil
+	}
+
+	parser := jsonpath.New("sort-by").AllowMissingKeys(true)
+	if err := parser.Parse(relaxedJSONPath(field)); err != nil {
+		return errors.Wrapf(err, "invalid --sort-by %q", field)
+	}
+
+	values := make([]interface{}, len(objs))
+	found := false
+	for i, obj := range objs {
+		data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return err
+		}
+		results, err := parser.FindResults(data)
+		if err != nil {
+			return err
+		}
+		if len(results) != 0 && len(results[0]) != 0 && results[0][0].IsValid() && results[0][0].CanInterface() {
+			values[i] = results[0][0].Interface()
+			found = true
+		}
+	}
+	if !found {
+		return errors.Errorf("couldn't find any field with path %q in the list of objects", field)
+	}
+
+	indexes := make([]int, len(objs))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return lessValue(values[indexes[i]], values[indexes[j]])
+	})
+
+	sorted := make([]runtime.Object, len(objs))
+	for i, index := range indexes {
+		sorted[i] = objs[index]
+	}
+	copy(objs, sorted)
+	return nil
+}
+
+// relaxedJSONPath accepts the fields without the braces or the leading dot as kubectl does.
+func relaxedJSONPath(field string) string {
+	if strings.HasPrefix(field, "{") {
+		return field
+	}
+	if !strings.HasPrefix(field, ".") {
+		field = "." + field
+	}
+	return fmt.Sprintf("{%s}", field)
+}
+
+func lessValue(a interface{}, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b != nil
+	}
+
+	// The numbers are int64 or float64 in the unstructured objects.
+	if x, ok := toFloat(a); ok {
+		if y, ok := toFloat(b); ok {
+			return x < y
+		}
+	}
+	switch x := a.(type) {
+	case string:
+		if y, ok := b.(string); ok {
+			return x < y
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			return !x && y
+		}
+	}
+	return fmt.Sprint(a) < fmt.Sprint(b)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := reflect.ValueOf(v); n.Kind() {
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return float64(n.Int()), true
+	case reflect.Float32, reflect.Float64:
+		return n.Float(), true
+	}
+	return 0, false
+}
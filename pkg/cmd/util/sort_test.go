@@ -0,0 +1,54 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestSortObjects(t *testing.T) {
+	pod := func(name string, restarts int32) *corev1.Pod {
+		p := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if restarts >= 0 {
+			p.Status.ContainerStatuses = []corev1.ContainerStatus{{RestartCount: restarts}}
+		}
+		return p
+	}
+
+	tests := []struct {
+		name      string
+		field     string
+		wantNames []string
+		wantErr   bool
+	}{
+		{name: "string", field: "{.metadata.name}", wantNames: []string{"a", "b", "c", "d"}},
+		{name: "relaxed", field: "metadata.name", wantNames: []string{"a", "b", "c", "d"}},
+		{name: "number and missing", field: ".status.containerStatuses[0].restartCount", wantNames: []string{"d", "c", "a", "b"}},
+		{name: "not found", field: ".spec.unknown", wantErr: true},
+		{name: "invalid", field: "{.metadata.name", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objs := []runtime.Object{pod("c", 2), pod("a", 10), pod("d", -1), pod("b", 10)}
+			err := SortObjects(objs, tt.field)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			var names []string
+			for _, obj := range objs {
+				names = append(names, obj.(*corev1.Pod).Name)
+			}
+			if !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("sorted = %v, want %v", names, tt.wantNames)
+			}
+		})
+	}
+}
@@ -30,6 +30,7 @@ type Spinner struct {
 	group   *SpinnerGroup
 	name    *string
 	IsDead  bool
+	err     error
 }
 
 func (s *Spinner) WithName(name string) *Spinner {
@@ -65,10 +66,19 @@ func (s *Spinner) Error(err error) {
 
 // ErrorWithMessage marks spinner as error and update message
 func (s *Spinner) ErrorWithMessage(message string, err error) {
+	// Set the error before the status so that it's seen by those who find the spinner failed.
+	if err != nil {
+		s.err = err
+	}
 	s.Update(message)
 	s.stop(errorStatus)
 	if err != nil {
-		s.group.errC <- err
+		// Only the first error is needed to stop the group,
+		// don't block the callers once it has been reported.
+		select {
+		case s.group.errC <- err:
+		default:
+		}
 	}
 }
 
@@ -106,3 +116,13 @@ func (s *Spinner) IsActive() bool {
 	}
 	return false
 }
+
+// Failed reports whether the spinner has been marked as error
+func (s *Spinner) Failed() bool {
+	return s.status.GetValue() == errorStatus
+}
+
+// Err returns the error the spinner has been marked with
+func (s *Spinner) Err() error {
+	return s.err
+}
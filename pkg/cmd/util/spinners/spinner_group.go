@@ -62,7 +62,7 @@ func (g *SpinnerGroup) Start(ctx context.Context) {
 
 	go func() {
 		ticker := time.NewTicker(100 * time.Millisecond)
-		for g.running {
+		for g.isRunning() {
 			select {
 			case <-ticker.C:
 				g.redraw()
@@ -70,7 +70,9 @@ func (g *SpinnerGroup) Start(ctx context.Context) {
 			case <-ctx.Done():
 				g.stop(ctx.Err().Error())
 			case err := <-g.errC:
+				g.Lock()
 				g.err = err
+				g.Unlock()
 				g.stop(stopped)
 			}
 		}
@@ -92,10 +94,18 @@ func (g *SpinnerGroup) Stop() {
 	g.running = false
 }
 
+func (g *SpinnerGroup) isRunning() bool {
+	g.Lock()
+	defer g.Unlock()
+	return g.running
+}
+
 // Wait for all spinners to finish
 func (g *SpinnerGroup) Wait() error {
 	g.WaitGroup.Wait()
 	g.Stop()
+	g.Lock()
+	defer g.Unlock()
 	return g.err
 }
 
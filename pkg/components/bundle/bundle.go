@@ -0,0 +1,337 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/OpenFunction/cli/pkg/components/inventory"
+	"github.com/OpenFunction/cli/pkg/components/manifest"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	IndexFileName = "index.yaml"
+	manifestsDir  = "manifests"
+)
+
+// Index describes the content of a bundle.
+type Index struct {
+	OpenFunctionVersion string                `yaml:"openFunctionVersion"`
+	KubernetesVersion   string                `yaml:"kubernetesVersion"`
+	RegionCN            bool                  `yaml:"regionCN,omitempty"`
+	Components          map[string]*Component `yaml:"components"`
+}
+
+// Component holds the manifests of a component, keyed like the result of inventory.Interface.GetYamlFile.
+type Component struct {
+	Version   string               `yaml:"version"`
+	Manifests map[string]*Manifest `yaml:"manifests"`
+}
+
+// Manifest is a file of the bundle and its checksum.
+type Manifest struct {
+	File   string `yaml:"file"`
+	SHA256 string `yaml:"sha256"`
+}
+
+// Create downloads the manifests of every component of the inventory
+// and writes them to w as a gzipped tarball along with a checksummed index.
+// The components without manifests, such as Dapr which is installed by its own CLI, are left out.
+func Create(ctx context.Context, w io.Writer, inv map[string]inventory.Interface, index *Index) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	if index.Components == nil {
+		index.Components = map[string]*Component{}
+	}
+
+	for _, name := range sortedNames(inv) {
+		v := inv[name].GetVersion()
+		yamls, err := inv[name].GetYamlFile(v)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get the yaml files of %s", name)
+		}
+		if len(yamls) == 0 {
+			continue
+		}
+
+		c := &Component{
+			Version:   v,
+			Manifests: map[string]*Manifest{},
+		}
+		for key, source := range yamls {
+			data, err := manifest.Fetch(ctx, source)
+			if err != nil {
+				return errors.Wrapf(err, "failed to download the manifest of %s", name)
+			}
+
+			file := path.Join(manifestsDir, slug(name), fmt.Sprintf("%s.yaml", strings.ToLower(key)))
+			if err := writeFile(tw, file, data); err != nil {
+				return err
+			}
+
+			sum := sha256.Sum256(data)
+			c.Manifests[key] = &Manifest{
+				File:   file,
+				SHA256: hex.EncodeToString(sum[:]),
+			}
+		}
+		index.Components[name] = c
+	}
+
+	data, err := yaml.Marshal(index)
+	if err != nil {
+		return err
+	}
+	if err := writeFile(tw, IndexFileName, data); err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// Bundle is a bundle extracted to a temporary directory.
+type Bundle struct {
+	Index *Index
+	dir   string
+}
+
+// ReadIndex returns the index of the bundle located at file without extracting it.
+func ReadIndex(file string) (*Index, error) {
+	var index *Index
+	err := walk(file, func(name string, r io.Reader) error {
+		if name != IndexFileName {
+			return nil
+		}
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		index, err = decodeIndex(data)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if index == nil {
+		return nil, errors.Errorf("%s is not a valid bundle, %s not found", file, IndexFileName)
+	}
+	return index, nil
+}
+
+// Open extracts the bundle located at file and verifies the checksums of its manifests.
+// The caller is responsible for calling Close once the bundle is no longer needed.
+func Open(file string) (*Bundle, error) {
+	dir, err := ioutil.TempDir("", "ofn-bundle-")
+	if err != nil {
+		return nil, err
+	}
+	b := &Bundle{dir: dir}
+
+	if err := walk(file, func(name string, r io.Reader) error {
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		f, err := os.Create(target)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(f, r)
+		return err
+	}); err != nil {
+		b.Close()
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, IndexFileName))
+	if err != nil {
+		b.Close()
+		return nil, errors.Errorf("%s is not a valid bundle, %s not found", file, IndexFileName)
+	}
+	if b.Index, err = decodeIndex(data); err != nil {
+		b.Close()
+		return nil, err
+	}
+
+	if err := b.verify(); err != nil {
+		b.Close()
+		return nil, err
+	}
+	return b, nil
+}
+
+// Close removes the extracted files.
+func (b *Bundle) Close() error {
+	return os.RemoveAll(b.dir)
+}
+
+func (b *Bundle) verify() error {
+	for name, c := range b.Index.Components {
+		for _, m := range c.Manifests {
+			if f := path.Clean(m.File); path.IsAbs(f) || strings.HasPrefix(f, "../") {
+				return errors.Errorf("invalid file name in bundle index: %s", m.File)
+			}
+			data, err := ioutil.ReadFile(b.path(m.File))
+			if err != nil {
+				return errors.Wrapf(err, "the manifest of %s is missing", name)
+			}
+			sum := sha256.Sum256(data)
+			if hex.EncodeToString(sum[:]) != m.SHA256 {
+				return errors.Errorf("checksum mismatch for %s", m.File)
+			}
+		}
+	}
+	return nil
+}
+
+func (b *Bundle) path(file string) string {
+	return filepath.Join(b.dir, filepath.FromSlash(file))
+}
+
+// Inventory returns a copy of inv whose components get their manifests from the bundle.
+// The components without manifests, such as Dapr, are left as they are.
+// It fails if a component is missing in the bundle or if it would be installed with another version.
+func (b *Bundle) Inventory(inv map[string]inventory.Interface) (map[string]inventory.Interface, error) {
+	res := map[string]inventory.Interface{}
+	for name, iv := range inv {
+		c, ok := b.Index.Components[name]
+		if !ok {
+			if yamls, err := iv.GetYamlFile(iv.GetVersion()); err == nil && len(yamls) == 0 {
+				res[name] = iv
+				continue
+			}
+			return nil, errors.Errorf("%s is not in the bundle", name)
+		}
+
+		if v := iv.GetVersion(); v != c.Version {
+			return nil, errors.Errorf(
+				"the bundle contains %s %s but %s is required, "+
+					"create the bundle with the version of OpenFunction and Kubernetes of the target cluster",
+				name, c.Version, v,
+			)
+		}
+
+		res[name] = &bundled{
+			Interface: iv,
+			name:      name,
+			component: c,
+			bundle:    b,
+		}
+	}
+	return res, nil
+}
+
+// bundled is an inventory.Interface reading the manifests from a bundle.
+type bundled struct {
+	inventory.Interface
+	name      string
+	component *Component
+	bundle    *Bundle
+}
+
+func (i *bundled) GetVersion() string {
+	return i.component.Version
+}
+
+// GetYamlFile returns the manifests of the bundle, the other versions,
+// such as the one recorded for a component to be uninstalled, aren't in the bundle and are downloaded.
+func (i *bundled) GetYamlFile(ver string) (map[string]string, error) {
+	if ver != i.component.Version {
+		return i.Interface.GetYamlFile(ver)
+	}
+
+	yamls := map[string]string{}
+	for key, m := range i.component.Manifests {
+		yamls[key] = i.bundle.path(m.File)
+	}
+	return yamls, nil
+}
+
+func decodeIndex(data []byte) (*Index, error) {
+	index := &Index{}
+	if err := yaml.Unmarshal(data, index); err != nil {
+		return nil, errors.Wrap(err, "failed to decode the bundle index")
+	}
+	return index, nil
+}
+
+// walk calls fn for every regular file of the gzipped tarball located at file.
+func walk(file string, fn func(name string, r io.Reader) error) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return errors.Wrapf(err, "%s is not a valid bundle", file)
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "%s is not a valid bundle", file)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := path.Clean(hdr.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return errors.Errorf("invalid file name in bundle: %s", hdr.Name)
+		}
+		if err := fn(name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+func writeFile(tw *tar.Writer, name string, data []byte) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:     name,
+		Mode:     0644,
+		Size:     int64(len(data)),
+		Typeflag: tar.TypeReg,
+	}); err != nil {
+		return err
+	}
+	_, err := io.Copy(tw, bytes.NewReader(data))
+	return err
+}
+
+func sortedNames(inv map[string]inventory.Interface) []string {
+	names := make([]string, 0, len(inv))
+	for name := range inv {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func slug(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, " ", "-"))
+}
@@ -0,0 +1,313 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/OpenFunction/cli/pkg/components/inventory"
+	"gopkg.in/yaml.v2"
+)
+
+type fakeComponent struct {
+	version string
+	yamls   map[string]string
+}
+
+func (c *fakeComponent) GetVersion() string {
+	return c.version
+}
+
+func (c *fakeComponent) GetYamlFile(ver string) (map[string]string, error) {
+	if ver != c.version {
+		return map[string]string{"MAIN": "https://example.com/" + ver + ".yaml"}, nil
+	}
+	return c.yamls, nil
+}
+
+func (c *fakeComponent) GetDependencies() []string {
+	return nil
+}
+
+func writeManifest(t *testing.T, dir string, name string, content string) string {
+	t.Helper()
+	file := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+// writeTarball writes the files to a gzipped tarball in dir, in the given order.
+func writeTarball(t *testing.T, dir string, files ...[2]string) string {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+	for _, f := range files {
+		if err := writeFile(tw, f[0], []byte(f[1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return writeManifest(t, dir, "bundle.tar.gz", buf.String())
+}
+
+func indexOf(t *testing.T, index *Index) string {
+	t.Helper()
+	data, err := yaml.Marshal(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func sha(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestCreateAndOpen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ofn-bundle-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	inv := map[string]inventory.Interface{
+		inventory.KedaName: &fakeComponent{version: "2.4.0", yamls: map[string]string{
+			"MAIN": writeManifest(t, dir, "keda.yaml", "kind: Namespace\n"),
+		}},
+		inventory.KnativeServingName: &fakeComponent{version: "1.0.1", yamls: map[string]string{
+			"CRD":  writeManifest(t, dir, "serving-crds.yaml", "kind: CustomResourceDefinition\n"),
+			"CORE": "file://" + writeManifest(t, dir, "serving-core.yaml", "kind: Deployment\n"),
+		}},
+		inventory.DaprName: &fakeComponent{version: "1.5.1"},
+	}
+
+	file := filepath.Join(dir, "ofn-bundle.tar.gz")
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	index := &Index{OpenFunctionVersion: "v0.6.0", KubernetesVersion: "v1.22.0"}
+	if err := Create(context.Background(), f, inv, index); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	if _, ok := index.Components[inventory.DaprName]; ok {
+		t.Errorf("want %s left out of the bundle", inventory.DaprName)
+	}
+
+	read, err := ReadIndex(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.OpenFunctionVersion != "v0.6.0" || read.KubernetesVersion != "v1.22.0" || len(read.Components) != 2 {
+		t.Errorf("unexpected index: %s", indexOf(t, read))
+	}
+
+	b, err := Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	bundled, err := b.Inventory(inv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bundled[inventory.DaprName] != inv[inventory.DaprName] {
+		t.Errorf("want %s to be left as it is", inventory.DaprName)
+	}
+
+	for name, want := range map[string]map[string]string{
+		inventory.KedaName:           {"MAIN": "kind: Namespace\n"},
+		inventory.KnativeServingName: {"CRD": "kind: CustomResourceDefinition\n", "CORE": "kind: Deployment\n"},
+	} {
+		iv := bundled[name]
+		yamls, err := iv.GetYamlFile(iv.GetVersion())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(yamls) != len(want) {
+			t.Errorf("%s: want %d manifests, got %v", name, len(want), yamls)
+		}
+		for key, content := range want {
+			if !strings.HasPrefix(yamls[key], b.dir) {
+				t.Errorf("%s: want %s read from the bundle, got %s", name, key, yamls[key])
+			}
+			data, err := ioutil.ReadFile(yamls[key])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != content {
+				t.Errorf("%s: want %s to be %q, got %q", name, key, content, data)
+			}
+		}
+	}
+
+	// The other versions, such as the one recorded for an uninstallation, aren't bundled.
+	yamls, err := bundled[inventory.KedaName].GetYamlFile("2.3.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if yamls["MAIN"] != "https://example.com/2.3.0.yaml" {
+		t.Errorf("want the manifest of another version to be downloaded, got %s", yamls["MAIN"])
+	}
+
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(b.dir); !os.IsNotExist(err) {
+		t.Errorf("want %s removed once the bundle is closed, got %v", b.dir, err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	const content = "kind: Namespace\n"
+	index := func(file string, sum string) string {
+		return indexOf(t, &Index{Components: map[string]*Component{
+			inventory.KedaName: {Version: "2.4.0", Manifests: map[string]*Manifest{"MAIN": {File: file, SHA256: sum}}},
+		}})
+	}
+
+	tests := []struct {
+		name    string
+		files   [][2]string
+		wantErr string
+	}{
+		{
+			name:  "valid",
+			files: [][2]string{{"manifests/keda/main.yaml", content}, {IndexFileName, index("manifests/keda/main.yaml", sha(content))}},
+		},
+		{
+			name:    "checksum mismatch",
+			files:   [][2]string{{"manifests/keda/main.yaml", "kind: Secret\n"}, {IndexFileName, index("manifests/keda/main.yaml", sha(content))}},
+			wantErr: "checksum mismatch for manifests/keda/main.yaml",
+		},
+		{
+			name:    "missing manifest",
+			files:   [][2]string{{IndexFileName, index("manifests/keda/main.yaml", sha(content))}},
+			wantErr: "the manifest of Keda is missing",
+		},
+		{
+			name:    "missing index",
+			files:   [][2]string{{"manifests/keda/main.yaml", content}},
+			wantErr: "not a valid bundle, index.yaml not found",
+		},
+		{
+			name:    "path traversal in tarball",
+			files:   [][2]string{{"manifests/../../evil.yaml", content}, {IndexFileName, index("manifests/keda/main.yaml", sha(content))}},
+			wantErr: "invalid file name in bundle: manifests/../../evil.yaml",
+		},
+		{
+			name:    "absolute path in tarball",
+			files:   [][2]string{{"/tmp/evil.yaml", content}},
+			wantErr: "invalid file name in bundle: /tmp/evil.yaml",
+		},
+		{
+			name:    "path traversal in index",
+			files:   [][2]string{{IndexFileName, index("../evil.yaml", sha(content))}},
+			wantErr: "invalid file name in bundle index: ../evil.yaml",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "ofn-bundle-test-")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			b, err := Open(writeTarball(t, dir, tt.files...))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("want error %q, got %v", tt.wantErr, err)
+				}
+				if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "evil.yaml")); !os.IsNotExist(err) {
+					t.Errorf("want no file written outside of the bundle, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			b.Close()
+		})
+	}
+}
+
+func TestInventory(t *testing.T) {
+	b := &Bundle{Index: &Index{Components: map[string]*Component{
+		inventory.KedaName: {Version: "2.4.0", Manifests: map[string]*Manifest{"MAIN": {File: "manifests/keda/main.yaml"}}},
+	}}, dir: "/bundle"}
+
+	tests := []struct {
+		name    string
+		inv     map[string]inventory.Interface
+		wantErr string
+	}{
+		{
+			name: "bundled",
+			inv: map[string]inventory.Interface{
+				inventory.KedaName: &fakeComponent{version: "2.4.0", yamls: map[string]string{"MAIN": "https://example.com/keda.yaml"}},
+				inventory.DaprName: &fakeComponent{version: "1.5.1"},
+			},
+		},
+		{
+			name: "version mismatch",
+			inv: map[string]inventory.Interface{
+				inventory.KedaName: &fakeComponent{version: "2.5.0", yamls: map[string]string{"MAIN": "https://example.com/keda.yaml"}},
+			},
+			wantErr: "the bundle contains Keda 2.4.0 but 2.5.0 is required",
+		},
+		{
+			name: "missing component",
+			inv: map[string]inventory.Interface{
+				inventory.ShipwrightName: &fakeComponent{version: "0.6.1", yamls: map[string]string{"MAIN": "https://example.com/shipwright.yaml"}},
+			},
+			wantErr: "Shipwright is not in the bundle",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv, err := b.Inventory(tt.inv)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("want error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(inv) != len(tt.inv) {
+				t.Errorf("want %d components, got %d", len(tt.inv), len(inv))
+			}
+			yamls, err := inv[inventory.KedaName].GetYamlFile("2.4.0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := filepath.Join("/bundle", "manifests", "keda", "main.yaml"); yamls["MAIN"] != want {
+				t.Errorf("want %s, got %s", want, yamls["MAIN"])
+			}
+		})
+	}
+}
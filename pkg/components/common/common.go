@@ -6,16 +6,19 @@ import (
 	"fmt"
 	ospkg "os"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/OpenFunction/cli/pkg/cmd/util"
 	"github.com/OpenFunction/cli/pkg/components"
 	"github.com/OpenFunction/cli/pkg/components/inventory"
 	"github.com/OpenFunction/cli/pkg/components/linux"
+	"github.com/OpenFunction/cli/pkg/components/manifest"
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/version"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
@@ -38,17 +41,45 @@ const (
 
 	BaseVersion   = "v0.3.1"
 	LatestVersion = "latest"
+
+	daprImageRegistry = "docker.io/daprio"
+	daprImageTmpl     = "%s/dapr:%s"
+
+	defaultCheckInterval = 5 * time.Second
 )
 
 type Operator struct {
-	os         string
-	version    string
-	inRegionCN bool
-	verbose    bool
-	executor   components.OperatorExecutor
-	timeout    time.Duration
-	Inventory  map[string]inventory.Interface
-	Records    *inventory.Record
+	os            string
+	version       string
+	inRegionCN    bool
+	verbose       bool
+	imageRegistry string
+	namespaces    map[string]string
+	executor      components.OperatorExecutor
+	timeout       time.Duration
+	Inventory     map[string]inventory.Interface
+	Records       *inventory.Record
+	// CheckInterval is the interval between the checks of the readiness and the removal of the components.
+	CheckInterval time.Duration
+
+	// The changes which aren't made by the executor and have to be reverted by Rollback,
+	// nil if there is no transaction.
+	lock sync.Mutex
+	undo []func(ctx context.Context) error
+}
+
+// componentNamespaces are the namespaces the components are installed in by their manifests.
+var componentNamespaces = map[string]string{
+	inventory.DaprName:                 DaprNamespace,
+	inventory.KedaName:                 KedaNamespace,
+	inventory.KnativeServingName:       KnativeServingNamespace,
+	inventory.KourierName:              KourierNamespace,
+	inventory.ServingDefaultDomainName: KnativeServingNamespace,
+	inventory.TektonPipelinesName:      TektonPipelineNamespace,
+	inventory.ShipwrightName:           ShipwrightNamespace,
+	inventory.CertManagerName:          CertManagerNamespace,
+	inventory.IngressName:              IngressNginxNamespace,
+	inventory.OpenFunctionName:         OpenFunctionNamespace,
 }
 
 type PatchExternalIP struct {
@@ -59,30 +90,107 @@ type Spec struct {
 	ExternalIPs []string `json:"externalIPs"`
 }
 
-func NewOperator(os, arch, version string, timeout time.Duration, inRegionCN bool, verbose bool) *Operator {
-	op := &Operator{
-		os:         os,
-		version:    version,
-		inRegionCN: inRegionCN,
-		verbose:    verbose,
-		timeout:    timeout,
-	}
-
+func NewOperator(cf genericclioptions.RESTClientGetter, os, arch, version string, timeout time.Duration, inRegionCN bool, verbose bool) *Operator {
 	if arch != "amd64" {
 		fmt.Fprint(ospkg.Stderr, "unsupported arch: ", arch)
 		ospkg.Exit(1)
 	}
 
+	var executor components.OperatorExecutor
 	switch os {
 	case "linux", "darwin":
-		op.executor = linux.NewExecutor(verbose)
+		executor = linux.NewExecutor(cf, verbose)
 	default:
 		fmt.Fprint(ospkg.Stderr, "unsupported os: ", os)
 		ospkg.Exit(1)
 	}
+
+	op := NewOperatorWithExecutor(executor, version, timeout, inRegionCN, verbose)
+	op.os = os
 	return op
 }
 
+// NewOperatorWithExecutor returns an Operator making the changes to the cluster with executor,
+// e.g. the fake executor of the tests.
+func NewOperatorWithExecutor(executor components.OperatorExecutor, version string, timeout time.Duration, inRegionCN bool, verbose bool) *Operator {
+	return &Operator{
+		version:       version,
+		inRegionCN:    inRegionCN,
+		verbose:       verbose,
+		timeout:       timeout,
+		executor:      executor,
+		CheckInterval: defaultCheckInterval,
+	}
+}
+
+// RelocateImages makes the components pull their images from registry instead of the upstream registries.
+func (o *Operator) RelocateImages(registry string) {
+	o.imageRegistry = registry
+	o.executor.AddTransform(manifest.Relocate(registry))
+}
+
+// RenameNamespaces makes the components be installed in other namespaces than the ones of their manifests,
+// namespaces maps the namespaces of the manifests to the namespaces to be used.
+func (o *Operator) RenameNamespaces(namespaces map[string]string) {
+	o.namespaces = namespaces
+	o.executor.AddTransform(manifest.RenameNamespaces(namespaces))
+}
+
+// Namespace returns the namespace used instead of the namespace ns of the manifests.
+func (o *Operator) Namespace(ns string) string {
+	if to, ok := o.namespaces[ns]; ok {
+		return to
+	}
+	return ns
+}
+
+// Patch patches the object with the apiVersion, kind, namespace and name of obj.
+func (o *Operator) Patch(ctx context.Context, obj *unstructured.Unstructured, pt types.PatchType, data []byte) error {
+	return o.executor.Patch(ctx, obj, pt, data)
+}
+
+// BeginTransaction makes the changes made to the cluster from now on be reverted by Rollback.
+func (o *Operator) BeginTransaction() {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	o.undo = []func(ctx context.Context) error{}
+	o.executor.BeginTransaction()
+}
+
+// Rollback deletes the objects created since BeginTransaction and restores the ones which have been changed.
+func (o *Operator) Rollback(ctx context.Context) error {
+	o.lock.Lock()
+	undo := o.undo
+	o.undo = nil
+	o.lock.Unlock()
+
+	msgs := []string{}
+	for i := len(undo) - 1; i >= 0; i-- {
+		if err := undo[i](ctx); err != nil {
+			msgs = append(msgs, err.Error())
+		}
+	}
+	if err := o.executor.Rollback(ctx); err != nil {
+		msgs = append(msgs, err.Error())
+	}
+
+	if len(msgs) != 0 {
+		return errors.New(strings.Join(msgs, "\n"))
+	}
+	return nil
+}
+
+// addUndo registers a change to be reverted by Rollback, it's a no-op if there is no transaction.
+func (o *Operator) addUndo(undo func(ctx context.Context) error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if o.undo != nil {
+		o.undo = append(o.undo, undo)
+	}
+}
+
 func (o *Operator) RecordInventory(ctx context.Context) error {
 	if o.Records == nil {
 		return errors.New("the inventory record is nil")
@@ -103,64 +211,61 @@ func (o *Operator) DownloadDaprClient(ctx context.Context, daprVersion string) e
 	return o.executor.DownloadDaprClient(daprVersion, o.inRegionCN)
 }
 
-func (o *Operator) InitDapr(ctx context.Context, daprVersion string) error {
+func (o *Operator) InitDapr(ctx context.Context, cl k8s.Interface, daprVersion string) error {
+	// Dapr is left alone by 'dapr init' if it's already installed.
+	_, err := cl.CoreV1().Namespaces().Get(ctx, o.Namespace(DaprNamespace), metav1.GetOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+	installed := err == nil
+
 	cmd := fmt.Sprintf("dapr init -k --log-as-json --runtime-version %s", daprVersion)
+	if ns := o.Namespace(DaprNamespace); ns != DaprNamespace {
+		cmd = fmt.Sprintf("%s --namespace %s", cmd, ns)
+	}
+	if o.imageRegistry != "" {
+		cmd = fmt.Sprintf("%s --set global.registry=%s", cmd, manifest.RelocateImage(daprImageRegistry, o.imageRegistry))
+	}
+	if !installed {
+		o.addUndo(func(ctx context.Context) error {
+			return o.UninstallDapr(ctx, cl, false)
+		})
+	}
 	if _, _, err := o.executor.Exec(cmd); err != nil && !strings.Contains(err.Error(), "still in use") {
 		return err
 	}
 	return nil
 }
 
+// UpgradeDapr upgrades the control plane of Dapr, which is left alone by 'dapr init' once it's installed.
+func (o *Operator) UpgradeDapr(ctx context.Context, daprVersion string) error {
+	cmd := fmt.Sprintf("dapr upgrade -k --runtime-version %s", daprVersion)
+	if o.imageRegistry != "" {
+		cmd = fmt.Sprintf("%s --set global.registry=%s", cmd, manifest.RelocateImage(daprImageRegistry, o.imageRegistry))
+	}
+	_, _, err := o.executor.Exec(cmd)
+	return err
+}
+
 func (o *Operator) InstallKeda(ctx context.Context, yamlFile string) error {
-	cmd := fmt.Sprintf("apply -f %s", yamlFile)
-	return o.executor.KubectlExec(ctx, cmd, false)
+	return o.executor.Apply(ctx, yamlFile)
 }
 
-func (o *Operator) CheckKedaIsReady(ctx context.Context, cl *k8s.Clientset) error {
-	return checkDeploymentIsReady(ctx, cl, KedaNamespace)
+func (o *Operator) CheckKedaIsReady(ctx context.Context, cl k8s.Interface) error {
+	return checkDeploymentIsReady(ctx, cl, o.Namespace(KedaNamespace), o.CheckInterval)
 }
 
 func (o *Operator) InstallKnativeServing(ctx context.Context, crdYamlFile string, coreYamlFile string) error {
-	// Ensure that the CRDs are ready before installing the CORE file
+	// The executor waits for the CRDs to be established,
+	// so they are ready before installing the CORE file.
 	// See more at: https://github.com/knative/serving/issues/6571
-	applyCore := func(ctx context.Context) error {
-		ctx, done := context.WithCancel(ctx)
-		defer done()
-
-		t := time.NewTicker(5 * time.Second)
-		defer t.Stop()
-
-		cmd := fmt.Sprintf("apply -f %s", coreYamlFile)
-
-		for {
-			select {
-			case <-t.C:
-				if err := o.executor.KubectlExec(ctx, cmd, false); err != nil {
-					if strings.Contains(err.Error(), "no matches for kind") {
-						t.Reset(5 * time.Second)
-						continue
-					}
-					return err
-				}
-				return nil
-			case <-ctx.Done():
-				return errors.Wrap(
-					ctx.Err(),
-					"context marked done. stopping check loop",
-				)
-			}
-		}
-	}
-
-	cmd := fmt.Sprintf("apply -f %s", crdYamlFile)
-	if err := o.executor.KubectlExec(ctx, cmd, true); err != nil {
+	if err := o.executor.Apply(ctx, crdYamlFile); err != nil {
 		return err
 	}
-
-	return applyCore(ctx)
+	return o.executor.Apply(ctx, coreYamlFile)
 }
 
-func (o *Operator) InstallKourier(ctx context.Context, cl *k8s.Clientset, yamlFile string) error {
+func (o *Operator) InstallKourier(ctx context.Context, cl k8s.Interface, yamlFile string) error {
 	patchData := map[string]map[string]string{
 		"data": {
 			"ingress.class": "kourier.ingress.networking.knative.dev",
@@ -171,68 +276,61 @@ func (o *Operator) InstallKourier(ctx context.Context, cl *k8s.Clientset, yamlFi
 		return err
 	}
 
-	cmd := fmt.Sprintf("apply -f %s", yamlFile)
-	if err := o.executor.KubectlExec(ctx, cmd, false); err != nil {
+	if err := o.executor.Apply(ctx, yamlFile); err != nil {
 		return err
 	}
 
-	if _, err := cl.CoreV1().ConfigMaps(KnativeServingNamespace).Patch(
-		ctx,
-		"config-network",
-		types.MergePatchType,
-		patchDataBytes,
-		metav1.PatchOptions{},
-	); err != nil {
-		return err
-	}
-	return nil
+	// The config map is patched by the executor so that the change is reverted by Rollback.
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetNamespace(o.Namespace(KnativeServingNamespace))
+	obj.SetName("config-network")
+	return o.executor.Patch(ctx, obj, types.MergePatchType, patchDataBytes)
 }
 
 func (o *Operator) ConfigKnativeServingDefaultDomain(ctx context.Context, yamlFile string) error {
-	cmd := fmt.Sprintf("apply -f %s", yamlFile)
-	return o.executor.KubectlExec(ctx, cmd, false)
+	return o.executor.Apply(ctx, yamlFile)
 }
 
-func (o *Operator) CheckKnativeServingIsReady(ctx context.Context, cl *k8s.Clientset) error {
-	return checkDeploymentIsReady(ctx, cl, KnativeServingNamespace)
+func (o *Operator) CheckKnativeServingIsReady(ctx context.Context, cl k8s.Interface) error {
+	return checkDeploymentIsReady(ctx, cl, o.Namespace(KnativeServingNamespace), o.CheckInterval)
 }
 
-func (o *Operator) CheckKourierIsReady(ctx context.Context, cl *k8s.Clientset) error {
-	return checkDeploymentIsReady(ctx, cl, KourierNamespace)
+func (o *Operator) CheckKourierIsReady(ctx context.Context, cl k8s.Interface) error {
+	return checkDeploymentIsReady(ctx, cl, o.Namespace(KourierNamespace), o.CheckInterval)
 }
 
 func (o *Operator) InstallTektonPipelines(ctx context.Context, yamlFile string) error {
-	cmd := fmt.Sprintf("apply -f %s", yamlFile)
-	return o.executor.KubectlExec(ctx, cmd, true)
+	return o.executor.Apply(ctx, yamlFile)
 }
 
 func (o *Operator) InstallShipwright(ctx context.Context, yamlFile string) error {
-	cmd := fmt.Sprintf("apply -f %s", yamlFile)
-	return o.executor.KubectlExec(ctx, cmd, false)
+	return o.executor.Apply(ctx, yamlFile)
 }
 
-func (o *Operator) CheckShipwrightIsReady(ctx context.Context, cl *k8s.Clientset) error {
-	return checkDeploymentIsReady(ctx, cl, ShipwrightNamespace)
+func (o *Operator) CheckShipwrightIsReady(ctx context.Context, cl k8s.Interface) error {
+	return checkDeploymentIsReady(ctx, cl, o.Namespace(ShipwrightNamespace), o.CheckInterval)
 }
 
-func (o *Operator) CheckTektonPipelinesIsReady(ctx context.Context, cl *k8s.Clientset) error {
-	return checkDeploymentIsReady(ctx, cl, TektonPipelineNamespace)
+func (o *Operator) CheckTektonPipelinesIsReady(ctx context.Context, cl k8s.Interface) error {
+	return checkDeploymentIsReady(ctx, cl, o.Namespace(TektonPipelineNamespace), o.CheckInterval)
 }
 
 func (o *Operator) InstallCertManager(ctx context.Context, yamlFile string) error {
-	cmd := fmt.Sprintf("apply -f %s", yamlFile)
-	return o.executor.KubectlExec(ctx, cmd, false)
+	return o.executor.Apply(ctx, yamlFile)
 }
 
-func (o *Operator) CheckCertManagerIsReady(ctx context.Context, cl *k8s.Clientset) error {
-	if err := checkDeploymentIsReady(ctx, cl, CertManagerNamespace); err != nil {
+func (o *Operator) CheckCertManagerIsReady(ctx context.Context, cl k8s.Interface) error {
+	if err := checkDeploymentIsReady(ctx, cl, o.Namespace(CertManagerNamespace), o.CheckInterval); err != nil {
 		return err
 	} else {
 		if err := checkPodIsReady(
 			ctx,
 			cl,
-			CertManagerNamespace,
+			o.Namespace(CertManagerNamespace),
 			fmt.Sprintf("%s=%s", k8sNameLabel, "webhook"),
+			o.CheckInterval,
 		); err != nil {
 			return err
 		}
@@ -241,95 +339,86 @@ func (o *Operator) CheckCertManagerIsReady(ctx context.Context, cl *k8s.Clientse
 }
 
 func (o *Operator) InstallIngressNginx(ctx context.Context, yamlFile string) error {
-	cmd := fmt.Sprintf("apply -f %s", yamlFile)
-	return o.executor.KubectlExec(ctx, cmd, false)
+	return o.executor.Apply(ctx, yamlFile)
 }
 
-func (o *Operator) CheckIngressNginxIsReady(ctx context.Context, cl *k8s.Clientset) error {
-	return checkDeploymentIsReady(ctx, cl, IngressNginxNamespace)
+func (o *Operator) CheckIngressNginxIsReady(ctx context.Context, cl k8s.Interface) error {
+	return checkDeploymentIsReady(ctx, cl, o.Namespace(IngressNginxNamespace), o.CheckInterval)
 }
 
 func (o *Operator) InstallOpenFunction(ctx context.Context, yamlFile string) error {
-	var cmd string
 	if o.version == "v0.3.1" {
-		cmd = fmt.Sprintf("apply -f %s", yamlFile)
-		if err := o.executor.KubectlExec(ctx, cmd, false); err != nil {
-			return err
-		}
-	} else {
-		cmd = fmt.Sprintf("create -f %s", yamlFile)
-		if err := o.executor.KubectlExec(
-			ctx,
-			cmd,
-			false,
-		); err != nil && !strings.Contains(err.Error(), "already exists") {
-			return err
-		}
+		return o.executor.Apply(ctx, yamlFile)
 	}
-	return nil
+	return components.IgnoreAlreadyExists(o.executor.Create(ctx, yamlFile))
+}
+
+// UpgradeOpenFunction applies the manifests of OpenFunction, the existing objects are updated.
+// Unlike 'kubectl apply', the server-side apply doesn't store the last applied manifest in an annotation,
+// so the size of the CRDs doesn't matter.
+func (o *Operator) UpgradeOpenFunction(ctx context.Context, yamlFile string) error {
+	return o.executor.Apply(ctx, yamlFile)
 }
 
-func (o *Operator) CheckOpenFunctionIsReady(ctx context.Context, cl *k8s.Clientset) error {
-	return checkDeploymentIsReady(ctx, cl, OpenFunctionNamespace)
+func (o *Operator) CheckOpenFunctionIsReady(ctx context.Context, cl k8s.Interface) error {
+	return checkDeploymentIsReady(ctx, cl, o.Namespace(OpenFunctionNamespace), o.CheckInterval)
 }
 
-func (o *Operator) UninstallDapr(ctx context.Context, cl *k8s.Clientset, waitForCleared bool) error {
+func (o *Operator) UninstallDapr(ctx context.Context, cl k8s.Interface, waitForCleared bool) error {
 	var cmd string
 
 	cmd = "dapr uninstall -k --all"
+	if ns := o.Namespace(DaprNamespace); ns != DaprNamespace {
+		cmd = fmt.Sprintf("%s --namespace %s", cmd, ns)
+	}
 	if _, _, err := o.executor.Exec(cmd); err != nil {
 		return err
 	}
 
-	cmd = fmt.Sprintf("delete namespace %s", DaprNamespace)
-	if err := o.executor.KubectlExec(ctx, cmd, false); util.IgnoreNotFoundErr(err) != nil {
+	if err := cl.CoreV1().Namespaces().Delete(ctx, o.Namespace(DaprNamespace), metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
 		return err
 	}
 
 	if waitForCleared {
-		return checkNamespaceIsCleared(ctx, cl, DaprNamespace)
+		return checkNamespaceIsCleared(ctx, cl, o.Namespace(DaprNamespace), o.CheckInterval)
 	}
 	return nil
 }
 
 func (o *Operator) UninstallKnativeServing(
 	ctx context.Context,
-	cl *k8s.Clientset,
+	cl k8s.Interface,
 	crdYamlFile string,
 	coreYamlFile string,
 	waitForCleared bool,
 ) error {
-	var cmd string
-	cmd = fmt.Sprintf("delete -f %s", coreYamlFile)
-	if err := o.executor.KubectlExec(ctx, cmd, true); util.IgnoreNotFoundErr(err) != nil {
+	if err := o.executor.Delete(ctx, coreYamlFile, true); err != nil {
 		return err
 	}
-	cmd = fmt.Sprintf("delete -f %s", crdYamlFile)
-	if err := o.executor.KubectlExec(ctx, cmd, true); util.IgnoreNotFoundErr(err) != nil {
+	if err := o.executor.Delete(ctx, crdYamlFile, true); err != nil {
 		return err
 	}
 
 	if waitForCleared {
-		return checkNamespaceIsCleared(ctx, cl, KnativeServingNamespace)
+		return checkNamespaceIsCleared(ctx, cl, o.Namespace(KnativeServingNamespace), o.CheckInterval)
 	}
 	return nil
 }
 
 func (o *Operator) Uninstall(
 	ctx context.Context,
-	cl *k8s.Clientset,
+	cl k8s.Interface,
 	yamlFile string,
 	namespace string,
 	waitForDelete bool,
 	waitForCleared bool,
 ) error {
-	cmd := fmt.Sprintf("delete -f %s", yamlFile)
-	if err := o.executor.KubectlExec(ctx, cmd, waitForDelete); util.IgnoreNotFoundErr(err) != nil {
+	if err := o.executor.Delete(ctx, yamlFile, waitForDelete); err != nil {
 		return err
 	}
 
 	if waitForCleared {
-		return checkNamespaceIsCleared(ctx, cl, namespace)
+		return checkNamespaceIsCleared(ctx, cl, o.Namespace(namespace), o.CheckInterval)
 	}
 	return nil
 }
@@ -355,15 +444,14 @@ func (o *Operator) DeleteKind(ctx context.Context) error {
 }
 
 func (o *Operator) RunOpenFunction(ctx context.Context, demoYamlFile string) error {
-	cmd := fmt.Sprintf("apply -f %s", demoYamlFile)
-	return o.executor.KubectlExec(ctx, cmd, false)
+	return o.executor.Apply(ctx, demoYamlFile)
 }
 
 func (o *Operator) GetNodeIP(ctx context.Context) (string, error) {
 	return o.executor.GetNodeIP(ctx)
 }
 
-func (o *Operator) PatchExternalIP(ctx context.Context, cl *k8s.Clientset, ip string) error {
+func (o *Operator) PatchExternalIP(ctx context.Context, cl k8s.Interface, ip string) error {
 
 	patchData := PatchExternalIP{
 		Spec: Spec{
@@ -377,7 +465,7 @@ func (o *Operator) PatchExternalIP(ctx context.Context, cl *k8s.Clientset, ip st
 		return err
 	}
 
-	if _, err := cl.CoreV1().Services(KourierNamespace).Patch(
+	if _, err := cl.CoreV1().Services(o.Namespace(KourierNamespace)).Patch(
 		ctx,
 		"kourier",
 		types.MergePatchType,
@@ -390,7 +478,7 @@ func (o *Operator) PatchExternalIP(ctx context.Context, cl *k8s.Clientset, ip st
 	return nil
 }
 
-func (o *Operator) PatchMagicDNS(ctx context.Context, cl *k8s.Clientset, ip string) error {
+func (o *Operator) PatchMagicDNS(ctx context.Context, cl k8s.Interface, ip string) error {
 
 	patchData := map[string]map[string]string{
 		"data": {fmt.Sprintf("%s.sslip.io", ip): ""},
@@ -400,7 +488,7 @@ func (o *Operator) PatchMagicDNS(ctx context.Context, cl *k8s.Clientset, ip stri
 		return err
 	}
 
-	if _, err := cl.CoreV1().ConfigMaps(KnativeServingNamespace).Patch(
+	if _, err := cl.CoreV1().ConfigMaps(o.Namespace(KnativeServingNamespace)).Patch(
 		ctx,
 		"config-domain",
 		types.MergePatchType,
@@ -412,41 +500,16 @@ func (o *Operator) PatchMagicDNS(ctx context.Context, cl *k8s.Clientset, ip stri
 	return nil
 }
 
-func (o *Operator) PrintEndpoint(ctx context.Context) (string, error) {
-	statusCMD := "kubectl get ksvc -l openfunction.io/serving=$(kubectl get functions function-sample-serving-only -o jsonpath='{.status.serving.resourceRef}') -o jsonpath='{.items[0].status.conditions[2].status}'"
-	for {
-		status, _, err := o.executor.Exec(statusCMD)
-		if err != nil {
-			return "", err
-		}
-		if status == "True" {
-			break
-		} else {
-			time.Sleep(2 * time.Second)
-		}
-
-	}
-	endpointCMD := "kubectl get ksvc -l openfunction.io/serving=$(kubectl get functions function-sample-serving-only -o jsonpath='{.status.serving.resourceRef}') -o jsonpath='{.items[0].status.url}'"
-	endpoint, _, err := o.executor.Exec(endpointCMD)
-	if err != nil {
-		return "", err
-	}
-	return endpoint, nil
-}
-
-func (o *Operator) CurlOpenFunction(ctx context.Context, endPoint string) (string, error) {
-	return o.executor.CurlOpenFunction(ctx, endPoint)
-}
-
 func checkDeploymentIsReady(
 	ctx context.Context,
-	cl *k8s.Clientset,
+	cl k8s.Interface,
 	ns string,
+	interval time.Duration,
 ) error {
 	ctx, done := context.WithCancel(ctx)
 	defer done()
 
-	t := time.NewTicker(5 * time.Second)
+	t := time.NewTicker(interval)
 	defer t.Stop()
 
 	for {
@@ -463,7 +526,7 @@ func checkDeploymentIsReady(
 					}
 				}
 				if len(dpls.Items) != ready {
-					t.Reset(5 * time.Second)
+					t.Reset(interval)
 				} else {
 					return nil
 				}
@@ -491,13 +554,14 @@ func getDeploymentStatusByType(
 
 func checkNamespaceIsCleared(
 	ctx context.Context,
-	cl *k8s.Clientset,
+	cl k8s.Interface,
 	ns string,
+	interval time.Duration,
 ) error {
 	ctx, done := context.WithCancel(ctx)
 	defer done()
 
-	t := time.NewTicker(5 * time.Second)
+	t := time.NewTicker(interval)
 	defer t.Stop()
 
 	for {
@@ -506,7 +570,7 @@ func checkNamespaceIsCleared(
 			if _, err := cl.CoreV1().Namespaces().Get(ctx, ns, metav1.GetOptions{}); err != nil {
 				return nil
 			}
-			t.Reset(5 * time.Second)
+			t.Reset(interval)
 		case <-ctx.Done():
 			return errors.Wrap(
 				ctx.Err(),
@@ -518,14 +582,15 @@ func checkNamespaceIsCleared(
 
 func checkPodIsReady(
 	ctx context.Context,
-	cl *k8s.Clientset,
+	cl k8s.Interface,
 	ns string,
 	label string,
+	interval time.Duration,
 ) error {
 	ctx, done := context.WithCancel(ctx)
 	defer done()
 
-	t := time.NewTicker(5 * time.Second)
+	t := time.NewTicker(interval)
 	defer t.Stop()
 
 	for {
@@ -541,7 +606,7 @@ func checkPodIsReady(
 					}
 				}
 			}
-			t.Reset(5 * time.Second)
+			t.Reset(interval)
 		case <-ctx.Done():
 			return errors.Wrap(
 				ctx.Err(),
@@ -563,30 +628,9 @@ func getPodStatusByType(
 	return nil
 }
 
-func IsComponentExist(ctx context.Context, cl *k8s.Clientset, ns string, resourceName string) bool {
-	// For the serving-default-domain component,
-	// we determine whether the component exists
-	// based on the status.active number of its Jobs resource.
-	if resourceName == "default-domain" {
-		if job, err := cl.BatchV1().Jobs(ns).Get(ctx, resourceName, metav1.GetOptions{}); err != nil {
-			return false
-		} else {
-			active := job.Status.Active
-			return active >= 1
-		}
-	} else {
-		if deploy, err := cl.AppsV1().Deployments(ns).Get(ctx, resourceName, metav1.GetOptions{}); err != nil {
-			return false
-		} else {
-			if status := getDeploymentStatusByType(
-				deploy.Status.Conditions,
-				appsv1.DeploymentAvailable,
-			); status != nil && *status == corev1.ConditionTrue {
-				return true
-			}
-			return false
-		}
-	}
+// GetComponentNamespace returns the namespace the component is installed in by its manifests.
+func GetComponentNamespace(name string) string {
+	return componentNamespaces[name]
 }
 
 func IsVersionValid(ofVersion *version.Version) (bool, error) {
@@ -600,3 +644,33 @@ func IsVersionValid(ofVersion *version.Version) (bool, error) {
 		return true, nil
 	}
 }
+
+// GetImages returns the images used by each component of the inventory.
+func GetImages(ctx context.Context, inv map[string]inventory.Interface) (map[string][]string, error) {
+	images := map[string][]string{}
+	for name, iv := range inv {
+		v := iv.GetVersion()
+
+		// Dapr is installed by its CLI, all its services share the same image.
+		if name == inventory.DaprName {
+			images[name] = []string{fmt.Sprintf(daprImageTmpl, daprImageRegistry, v)}
+			continue
+		}
+
+		yamls, err := iv.GetYamlFile(v)
+		if err != nil {
+			return nil, err
+		}
+
+		var objs []*unstructured.Unstructured
+		for _, source := range yamls {
+			o, err := manifest.Load(ctx, source)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to load the manifest of %s", name)
+			}
+			objs = append(objs, o...)
+		}
+		images[name] = manifest.Images(objs)
+	}
+	return images, nil
+}
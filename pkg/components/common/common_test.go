@@ -0,0 +1,128 @@
+package common
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OpenFunction/cli/pkg/components/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8s "k8s.io/client-go/kubernetes"
+	fakek8s "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestOperator(t *testing.T) {
+	daprNamespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: DaprNamespace}}
+
+	tests := []struct {
+		name    string
+		version string
+		objs    []runtime.Object
+		run     func(ctx context.Context, o *Operator, cl k8s.Interface) error
+		// rollback rolls back the changes made by run in a transaction.
+		rollback  bool
+		wantCalls []string
+		wantErr   string
+	}{
+		{
+			name:     "init dapr rolled back",
+			rollback: true,
+			run: func(ctx context.Context, o *Operator, cl k8s.Interface) error {
+				return o.InitDapr(ctx, cl, "1.5.1")
+			},
+			wantCalls: []string{"begin-transaction", "exec dapr init -k --log-as-json --runtime-version 1.5.1", "exec dapr uninstall -k --all", "rollback"},
+		},
+		{
+			name:     "existing dapr left alone by rollback",
+			objs:     []runtime.Object{daprNamespace},
+			rollback: true,
+			run: func(ctx context.Context, o *Operator, cl k8s.Interface) error {
+				return o.InitDapr(ctx, cl, "1.5.1")
+			},
+			wantCalls: []string{"begin-transaction", "exec dapr init -k --log-as-json --runtime-version 1.5.1", "rollback"},
+		},
+		{
+			name: "install kourier",
+			run: func(ctx context.Context, o *Operator, cl k8s.Interface) error {
+				return o.InstallKourier(ctx, cl, "kourier.yaml")
+			},
+			wantCalls: []string{"apply kourier.yaml", "patch ConfigMap knative-serving/config-network"},
+		},
+		{
+			name:    "install openfunction",
+			version: "v0.6.0",
+			run: func(ctx context.Context, o *Operator, cl k8s.Interface) error {
+				return o.InstallOpenFunction(ctx, "openfunction.yaml")
+			},
+			wantCalls: []string{"create openfunction.yaml"},
+		},
+		{
+			name:    "install openfunction v0.3.1",
+			version: "v0.3.1",
+			run: func(ctx context.Context, o *Operator, cl k8s.Interface) error {
+				return o.InstallOpenFunction(ctx, "openfunction.yaml")
+			},
+			wantCalls: []string{"apply openfunction.yaml"},
+		},
+		{
+			name: "uninstall knative serving",
+			run: func(ctx context.Context, o *Operator, cl k8s.Interface) error {
+				return o.UninstallKnativeServing(ctx, cl, "crd.yaml", "core.yaml", true)
+			},
+			wantCalls: []string{"delete core.yaml", "delete crd.yaml"},
+		},
+		{
+			name: "readiness timeout",
+			objs: []runtime.Object{&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: CertManagerNamespace, Name: "cert-manager-webhook", Labels: map[string]string{k8sNameLabel: "webhook"}}}},
+			run: func(ctx context.Context, o *Operator, cl k8s.Interface) error {
+				return o.CheckCertManagerIsReady(ctx, cl)
+			},
+			wantErr: "context deadline exceeded",
+		},
+		{
+			name: "namespace cleared timeout",
+			// The namespace isn't removed by the fake executor.
+			objs: []runtime.Object{&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: KedaNamespace}}},
+			run: func(ctx context.Context, o *Operator, cl k8s.Interface) error {
+				return o.Uninstall(ctx, cl, "keda.yaml", KedaNamespace, false, true)
+			},
+			wantCalls: []string{"delete keda.yaml"},
+			wantErr:   "context deadline exceeded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, done := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer done()
+
+			executor := fake.NewExecutor()
+			o := NewOperatorWithExecutor(executor, tt.version, time.Second, false, false)
+			o.CheckInterval = 10 * time.Millisecond
+			cl := fakek8s.NewSimpleClientset(tt.objs...)
+
+			if tt.rollback {
+				o.BeginTransaction()
+			}
+			err := tt.run(ctx, o, cl)
+			if tt.rollback && err == nil {
+				err = o.Rollback(ctx)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if calls := executor.Calls(); !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("calls = %q, want %q", calls, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/OpenFunction/cli/pkg/components/inventory"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+	k8s "k8s.io/client-go/kubernetes"
+)
+
+const (
+	kindDeployment = "Deployment"
+	kindJob        = "Job"
+
+	crdPathTmpl = "/apis/apiextensions.k8s.io/v1/customresourcedefinitions/%s"
+)
+
+// versionKeys are the labels and annotations carrying the version of a component, by order of preference.
+var versionKeys = []string{
+	k8sVersionLabel,
+	"serving.knative.dev/release",
+	"pipeline.tekton.dev/release",
+}
+
+// workload is a resource whose existence tells that a component is installed.
+type workload struct {
+	kind      string
+	namespace string
+	name      string
+}
+
+// probe describes how to find a component in the cluster.
+type probe struct {
+	// workload is used to tell whether the component is installed and ready.
+	workload workload
+	// versionFrom are the workloads carrying the version of the component, defaults to workload.
+	versionFrom []workload
+	// crd is a CustomResourceDefinition of the component which may carry its version.
+	crd string
+}
+
+var probes = map[string]*probe{
+	inventory.DaprName: {
+		workload: workload{kindDeployment, DaprNamespace, "dapr-operator"},
+		crd:      "components.dapr.io",
+	},
+	inventory.KedaName: {
+		workload: workload{kindDeployment, KedaNamespace, "keda-operator"},
+		crd:      "scaledobjects.keda.sh",
+	},
+	inventory.KnativeServingName: {
+		workload: workload{kindDeployment, KnativeServingNamespace, "controller"},
+		crd:      "services.serving.knative.dev",
+	},
+	inventory.KourierName: {
+		workload: workload{kindDeployment, KourierNamespace, "3scale-kourier-gateway"},
+		// The gateway runs Envoy, the version of Kourier is carried by its controller.
+		versionFrom: []workload{
+			{kindDeployment, KnativeServingNamespace, "net-kourier-controller"},
+			{kindDeployment, KnativeServingNamespace, "3scale-kourier-control"},
+		},
+	},
+	inventory.ServingDefaultDomainName: {
+		workload: workload{kindJob, KnativeServingNamespace, "default-domain"},
+	},
+	inventory.TektonPipelinesName: {
+		workload: workload{kindDeployment, TektonPipelineNamespace, "tekton-pipelines-controller"},
+		crd:      "pipelines.tekton.dev",
+	},
+	inventory.ShipwrightName: {
+		workload: workload{kindDeployment, ShipwrightNamespace, "shipwright-build-controller"},
+		crd:      "builds.shipwright.io",
+	},
+	inventory.CertManagerName: {
+		workload: workload{kindDeployment, CertManagerNamespace, "cert-manager"},
+		crd:      "certificates.cert-manager.io",
+	},
+	inventory.IngressName: {
+		workload: workload{kindDeployment, IngressNginxNamespace, "ingress-nginx-controller"},
+	},
+	inventory.OpenFunctionName: {
+		workload: workload{kindDeployment, OpenFunctionNamespace, "openfunction-controller-manager"},
+		crd:      "functions.core.openfunction.io",
+	},
+}
+
+// ComponentStatus is the state of a component found in the cluster.
+type ComponentStatus struct {
+	// Installed is true if the workload of the component exists.
+	Installed bool
+	// Ready is true if the workload of the component is available,
+	// or has completed for the components which are installed by a Job.
+	Ready bool
+	// Version is the version of the component found in the cluster, in the x.y.z form.
+	// It's empty if the component isn't installed or its version can't be determined.
+	Version string
+}
+
+// GetComponentNames returns the names of the components which can be detected.
+func GetComponentNames() []string {
+	names := make([]string, 0, len(probes))
+	for name := range probes {
+		names = append(names, name)
+	}
+	return names
+}
+
+// DetectComponent finds the component in the cluster and the version which is actually running,
+// from the version labels of its workloads, the tags of their images or the labels of its CRD.
+// The workloads are looked for in the namespaces the components have been moved to, see RenameNamespaces.
+func (o *Operator) DetectComponent(ctx context.Context, cl k8s.Interface, name string) (*ComponentStatus, error) {
+	p, ok := probes[name]
+	if !ok {
+		return nil, errors.Errorf("unknown component %s", name)
+	}
+
+	status := &ComponentStatus{}
+	meta, pod, ready, err := o.getWorkload(ctx, cl, p.workload)
+	if k8serrors.IsNotFound(err) {
+		return status, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	status.Installed = true
+	status.Ready = ready
+
+	if len(p.versionFrom) == 0 {
+		status.Version = versionOf(meta, pod)
+	}
+	for _, w := range p.versionFrom {
+		meta, pod, _, err := o.getWorkload(ctx, cl, w)
+		if k8serrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if status.Version = versionOf(meta, pod); status.Version != "" {
+			break
+		}
+	}
+
+	if status.Version == "" && p.crd != "" {
+		crd, err := getCRD(ctx, cl, p.crd)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return nil, err
+		}
+		if crd != nil {
+			status.Version = versionOf(&crd.ObjectMeta, nil)
+		}
+	}
+	return status, nil
+}
+
+// DetectComponents detects every known component, see DetectComponent.
+func (o *Operator) DetectComponents(ctx context.Context, cl k8s.Interface) (map[string]*ComponentStatus, error) {
+	m := map[string]*ComponentStatus{}
+	for name := range probes {
+		status, err := o.DetectComponent(ctx, cl, name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to detect %s", name)
+		}
+		m[name] = status
+	}
+	return m, nil
+}
+
+// NormalizeVersion returns v in the x.y.z form, or an empty string if v isn't a version.
+func NormalizeVersion(v string) string {
+	ver, err := version.ParseGeneric(v)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%d.%d.%d", ver.Major(), ver.Minor(), ver.Patch())
+}
+
+// getWorkload returns the metadata and the pod template of the workload,
+// along with whether it's available.
+func (o *Operator) getWorkload(ctx context.Context, cl k8s.Interface, w workload) (*metav1.ObjectMeta, *corev1.PodTemplateSpec, bool, error) {
+	ns := o.Namespace(w.namespace)
+	switch w.kind {
+	case kindJob:
+		job, err := cl.BatchV1().Jobs(ns).Get(ctx, w.name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, false, err
+		}
+		return &job.ObjectMeta, &job.Spec.Template, job.Status.Succeeded >= 1 || job.Status.Active >= 1, nil
+	default:
+		deploy, err := cl.AppsV1().Deployments(ns).Get(ctx, w.name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, false, err
+		}
+		status := getDeploymentStatusByType(deploy.Status.Conditions, appsv1.DeploymentAvailable)
+		return &deploy.ObjectMeta, &deploy.Spec.Template, status != nil && *status == corev1.ConditionTrue, nil
+	}
+}
+
+// getCRD returns the metadata of the CustomResourceDefinition,
+// the API is called directly since the apiextensions clientset isn't needed otherwise.
+func getCRD(ctx context.Context, cl k8s.Interface, name string) (*metav1.PartialObjectMetadata, error) {
+	rc := cl.Discovery().RESTClient()
+	if rc == nil {
+		return nil, nil
+	}
+
+	data, err := rc.Get().AbsPath(fmt.Sprintf(crdPathTmpl, name)).DoRaw(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	crd := &metav1.PartialObjectMetadata{}
+	if err := json.Unmarshal(data, crd); err != nil {
+		return nil, err
+	}
+	return crd, nil
+}
+
+// versionOf looks for a version in the labels and the annotations of the object,
+// then in the labels of its pod template and the tags of its images.
+func versionOf(meta *metav1.ObjectMeta, pod *corev1.PodTemplateSpec) string {
+	sources := []map[string]string{meta.Labels, meta.Annotations}
+	if pod != nil {
+		sources = append(sources, pod.Labels)
+	}
+	for _, m := range sources {
+		for _, key := range versionKeys {
+			if v := NormalizeVersion(m[key]); v != "" {
+				return v
+			}
+		}
+	}
+
+	if pod == nil {
+		return ""
+	}
+	for _, c := range append(pod.Spec.Containers, pod.Spec.InitContainers...) {
+		if v := NormalizeVersion(imageTag(c.Image)); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// imageTag returns the tag of the image reference, or an empty string if it has none.
+func imageTag(image string) string {
+	image = strings.SplitN(image, "@", 2)[0]
+	name := path.Base(image)
+	if i := strings.LastIndex(name, ":"); i != -1 {
+		return name[i+1:]
+	}
+	return ""
+}
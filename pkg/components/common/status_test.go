@@ -0,0 +1,98 @@
+package common
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/OpenFunction/cli/pkg/components/inventory"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newDeployment(ns, name string, labels map[string]string, image string, available bool) *appsv1.Deployment {
+	status := corev1.ConditionFalse
+	if available {
+		status = corev1.ConditionTrue
+	}
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name, Labels: labels},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: name, Image: image}}},
+			},
+		},
+		Status: appsv1.DeploymentStatus{
+			Conditions: []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: status}},
+		},
+	}
+}
+
+func TestDetectComponent(t *testing.T) {
+	objs := []runtime.Object{
+		newDeployment(KedaNamespace, "keda-operator", map[string]string{k8sVersionLabel: "2.4.0"}, "ghcr.io/kedacore/keda:2.5.0", true),
+		newDeployment(CertManagerNamespace, "cert-manager", nil, "quay.io/jetstack/cert-manager-controller:v1.5.4", false),
+		newDeployment(KourierNamespace, "3scale-kourier-gateway", nil, "docker.io/envoyproxy/envoy:v1.18.3", true),
+		newDeployment(KnativeServingNamespace, "net-kourier-controller", map[string]string{"serving.knative.dev/release": "v1.0.1"}, "gcr.io/knative-releases/knative.dev/net-kourier/cmd/kourier@sha256:aaaa", true),
+		newDeployment(IngressNginxNamespace, "ingress-nginx-controller", nil, "k8s.gcr.io/ingress-nginx/controller@sha256:bbbb", true),
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Namespace: KnativeServingNamespace, Name: "default-domain", Labels: map[string]string{k8sVersionLabel: "1.0.1"}},
+			Status:     batchv1.JobStatus{Succeeded: 1},
+		},
+	}
+	cl := fake.NewSimpleClientset(objs...)
+
+	tests := []struct {
+		name string
+		want *ComponentStatus
+	}{
+		{inventory.KedaName, &ComponentStatus{Installed: true, Ready: true, Version: "2.4.0"}},
+		{inventory.CertManagerName, &ComponentStatus{Installed: true, Ready: false, Version: "1.5.4"}},
+		{inventory.KourierName, &ComponentStatus{Installed: true, Ready: true, Version: "1.0.1"}},
+		{inventory.IngressName, &ComponentStatus{Installed: true, Ready: true}},
+		{inventory.ServingDefaultDomainName, &ComponentStatus{Installed: true, Ready: true, Version: "1.0.1"}},
+		{inventory.DaprName, &ComponentStatus{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&Operator{}).DetectComponent(context.Background(), cl, tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDetectComponentInRenamedNamespace(t *testing.T) {
+	cl := fake.NewSimpleClientset(
+		newDeployment("autoscaling", "keda-operator", map[string]string{k8sVersionLabel: "2.4.0"}, "ghcr.io/kedacore/keda:2.4.0", true),
+		newDeployment(DaprNamespace, "dapr-operator", nil, "docker.io/daprio/dapr:1.5.1", true),
+	)
+	o := &Operator{namespaces: map[string]string{KedaNamespace: "autoscaling", DaprNamespace: "dapr"}}
+
+	tests := []struct {
+		name string
+		want *ComponentStatus
+	}{
+		{inventory.KedaName, &ComponentStatus{Installed: true, Ready: true, Version: "2.4.0"}},
+		{inventory.DaprName, &ComponentStatus{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := o.DetectComponent(context.Background(), cl, tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
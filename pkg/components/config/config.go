@@ -0,0 +1,326 @@
+package config
+
+import (
+	"io/ioutil"
+	"sort"
+	"time"
+
+	"github.com/OpenFunction/cli/pkg/components/inventory"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/version"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const (
+	APIVersion        = "cli.openfunction.io/v1alpha1"
+	InstallConfigKind = "InstallConfig"
+
+	PatchTypeMerge     = "merge"
+	PatchTypeJSON      = "json"
+	PatchTypeStrategic = "strategic"
+)
+
+// componentNames maps the names of the components in the config,
+// which are the same as in the inventory record, to the names of the inventory.
+var componentNames = map[string]string{
+	inventory.DaprRecordName:                 inventory.DaprName,
+	inventory.KedaRecordName:                 inventory.KedaName,
+	inventory.KnativeServingRecordName:       inventory.KnativeServingName,
+	inventory.KourierRecordName:              inventory.KourierName,
+	inventory.ServingDefaultDomainRecordName: inventory.ServingDefaultDomainName,
+	inventory.TektonPipelinesRecordName:      inventory.TektonPipelinesName,
+	inventory.ShipwrightRecordName:           inventory.ShipwrightName,
+	inventory.CertManagerRecordName:          inventory.CertManagerName,
+	inventory.IngressRecordName:              inventory.IngressName,
+	inventory.OpenFunctionRecordName:         inventory.OpenFunctionName,
+}
+
+// Toggleable are the components which can be enabled or disabled on their own,
+// the others are installed along with the components they belong to.
+var Toggleable = map[string]bool{
+	inventory.DaprName:           true,
+	inventory.KedaName:           true,
+	inventory.KnativeServingName: true,
+	inventory.ShipwrightName:     true,
+	inventory.CertManagerName:    true,
+	inventory.IngressName:        true,
+}
+
+// InstallConfig describes an installation of OpenFunction and its dependencies,
+// the flags of 'ofn install' which are set take precedence over it.
+type InstallConfig struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+
+	// Version is the version of OpenFunction.
+	Version       string   `yaml:"version,omitempty"`
+	Runtimes      []string `yaml:"runtimes,omitempty"`
+	Ingress       string   `yaml:"ingress,omitempty"`
+	WithoutCI     bool     `yaml:"withoutCI,omitempty"`
+	RegionCN      bool     `yaml:"regionCN,omitempty"`
+	FromBundle    string   `yaml:"fromBundle,omitempty"`
+	ImageRegistry string   `yaml:"imageRegistry,omitempty"`
+	Timeout       Duration `yaml:"timeout,omitempty"`
+
+	// Components are keyed by the names of the inventory record, e.g. knativeServing.
+	Components map[string]*Component `yaml:"components,omitempty"`
+}
+
+// Component overrides the defaults of a component.
+type Component struct {
+	// Enabled installs or skips the component regardless of the runtimes,
+	// it's only supported by the components in Toggleable.
+	Enabled *bool `yaml:"enabled,omitempty"`
+	// Version replaces the default version of the component.
+	Version string `yaml:"version,omitempty"`
+	// Manifests replace the manifests of the component, they are keyed like inventory.Interface.GetYamlFile.
+	Manifests map[string]string `yaml:"manifests,omitempty"`
+	// Namespace replaces the namespace of the manifests of the component.
+	Namespace string `yaml:"namespace,omitempty"`
+	// Timeout limits the time the component takes to be installed and ready.
+	Timeout Duration `yaml:"timeout,omitempty"`
+	// Patches are applied once the component is installed.
+	Patches []*Patch `yaml:"patches,omitempty"`
+}
+
+// Patch is applied to an object once its component is installed.
+type Patch struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Namespace  string `yaml:"namespace,omitempty"`
+	Name       string `yaml:"name"`
+	// Type is one of merge (default), json and strategic.
+	Type string `yaml:"type,omitempty"`
+	// Patch is written in either JSON or YAML.
+	Patch string `yaml:"patch"`
+}
+
+// Duration is a time.Duration written like "10m" in the config.
+type Duration time.Duration
+
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return time.Duration(d).String(), nil
+}
+
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// NewInstallConfig returns an empty InstallConfig.
+func NewInstallConfig() *InstallConfig {
+	return &InstallConfig{
+		APIVersion: APIVersion,
+		Kind:       InstallConfigKind,
+		Components: map[string]*Component{},
+	}
+}
+
+// Load reads and validates the config of the file.
+func Load(file string) (*InstallConfig, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &InstallConfig{}
+	if err := yaml.UnmarshalStrict(data, c); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode %s", file)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, errors.Wrapf(err, "invalid config %s", file)
+	}
+	return c, nil
+}
+
+// Marshal returns the config in YAML.
+func (c *InstallConfig) Marshal() ([]byte, error) {
+	return yaml.Marshal(c)
+}
+
+func (c *InstallConfig) Validate() error {
+	if c.APIVersion != APIVersion || c.Kind != InstallConfigKind {
+		return errors.Errorf("unsupported config %s/%s, %s/%s is expected", c.APIVersion, c.Kind, APIVersion, InstallConfigKind)
+	}
+
+	for key, comp := range c.Components {
+		name, ok := componentNames[key]
+		if !ok {
+			return errors.Errorf("unknown component %s", key)
+		}
+		if comp == nil {
+			continue
+		}
+
+		if comp.Enabled != nil && !Toggleable[name] {
+			return errors.Errorf("%s can't be enabled or disabled on its own", key)
+		}
+		if comp.Version != "" {
+			if _, err := version.ParseGeneric(comp.Version); err != nil {
+				return errors.Errorf("the version %s of %s is not a valid version", comp.Version, key)
+			}
+		}
+		if len(comp.Manifests) != 0 && name == inventory.DaprName {
+			return errors.Errorf("%s is installed by its CLI and has no manifest", key)
+		}
+		if comp.Namespace != "" {
+			// The default domain is configured in the namespace of Knative Serving.
+			if name == inventory.ServingDefaultDomainName {
+				return errors.Errorf("the namespace of %s is the one of %s", key, inventory.KnativeServingRecordName)
+			}
+			if errs := validation.IsDNS1123Label(comp.Namespace); len(errs) != 0 {
+				return errors.Errorf("invalid namespace %s of %s: %v", comp.Namespace, key, errs)
+			}
+		}
+		for _, p := range comp.Patches {
+			if p.APIVersion == "" || p.Kind == "" || p.Name == "" || p.Patch == "" {
+				return errors.Errorf("apiVersion, kind, name and patch are required by the patches of %s", key)
+			}
+			if _, err := p.PatchType(); err != nil {
+				return errors.Wrapf(err, "invalid patch of %s", key)
+			}
+			if _, err := p.Data(); err != nil {
+				return errors.Wrapf(err, "invalid patch of %s", key)
+			}
+		}
+	}
+	return nil
+}
+
+// Component returns the config of the component with the given inventory name, or nil if there is none.
+func (c *InstallConfig) Component(name string) *Component {
+	if c == nil {
+		return nil
+	}
+	for key, n := range componentNames {
+		if n == name {
+			return c.Components[key]
+		}
+	}
+	return nil
+}
+
+// SetComponent sets the config of the component with the given inventory name.
+func (c *InstallConfig) SetComponent(name string, comp *Component) {
+	for key, n := range componentNames {
+		if n == name {
+			c.Components[key] = comp
+		}
+	}
+}
+
+// Enabled returns the components which are explicitly enabled or disabled, keyed by their inventory names.
+func (c *InstallConfig) Enabled() map[string]bool {
+	m := map[string]bool{}
+	if c == nil {
+		return m
+	}
+	for key, comp := range c.Components {
+		if comp != nil && comp.Enabled != nil {
+			m[componentNames[key]] = *comp.Enabled
+		}
+	}
+	return m
+}
+
+// Namespaces maps the namespaces of the manifests to the namespaces configured for the components,
+// defaultNamespace returns the namespace of the manifests of a component.
+func (c *InstallConfig) Namespaces(defaultNamespace func(name string) string) (map[string]string, error) {
+	m := map[string]string{}
+	for _, key := range c.sortedKeys() {
+		comp := c.Components[key]
+		if comp == nil || comp.Namespace == "" {
+			continue
+		}
+
+		from := defaultNamespace(componentNames[key])
+		if to, ok := m[from]; ok && to != comp.Namespace {
+			return nil, errors.Errorf("the components installed in %s can't be moved to both %s and %s", from, to, comp.Namespace)
+		}
+		if from != comp.Namespace {
+			m[from] = comp.Namespace
+		}
+	}
+	return m, nil
+}
+
+// Inventory returns the inventory with the versions and the manifests of the components replaced by the config.
+func (c *InstallConfig) Inventory(inv map[string]inventory.Interface) map[string]inventory.Interface {
+	res := map[string]inventory.Interface{}
+	for name, iv := range inv {
+		comp := c.Component(name)
+		if comp == nil || (comp.Version == "" && len(comp.Manifests) == 0) {
+			res[name] = iv
+			continue
+		}
+		res[name] = &configured{
+			Interface: iv,
+			component: comp,
+		}
+	}
+	return res
+}
+
+// PatchType returns the type of the patch in the API.
+func (p *Patch) PatchType() (types.PatchType, error) {
+	switch p.Type {
+	case "", PatchTypeMerge:
+		return types.MergePatchType, nil
+	case PatchTypeJSON:
+		return types.JSONPatchType, nil
+	case PatchTypeStrategic:
+		return types.StrategicMergePatchType, nil
+	default:
+		return "", errors.Errorf("unknown patch type %s", p.Type)
+	}
+}
+
+// Data returns the patch in JSON.
+func (p *Patch) Data() ([]byte, error) {
+	return utilyaml.ToJSON([]byte(p.Patch))
+}
+
+func (c *InstallConfig) sortedKeys() []string {
+	keys := make([]string, 0, len(c.Components))
+	for key := range c.Components {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// configured is an inventory.Interface with the version and the manifests of the config.
+type configured struct {
+	inventory.Interface
+	component *Component
+}
+
+func (i *configured) GetVersion() string {
+	if i.component.Version != "" {
+		return i.component.Version
+	}
+	return i.Interface.GetVersion()
+}
+
+func (i *configured) GetYamlFile(ver string) (map[string]string, error) {
+	if len(i.component.Manifests) == 0 {
+		return i.Interface.GetYamlFile(ver)
+	}
+
+	yamls := map[string]string{}
+	for key, source := range i.component.Manifests {
+		yamls[key] = source
+	}
+	return yamls, nil
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OpenFunction/cli/pkg/components/inventory"
+)
+
+const installConfig = `
+apiVersion: cli.openfunction.io/v1alpha1
+kind: InstallConfig
+version: v0.6.0
+runtimes: [knative, async]
+timeout: 15m
+components:
+  knativeServing:
+    version: 1.0.1
+    namespace: serving
+    timeout: 5m
+    patches:
+    - apiVersion: v1
+      kind: ConfigMap
+      namespace: serving
+      name: config-autoscaler
+      patch: |
+        data:
+          enable-scale-to-zero: "false"
+  kourier:
+    manifests:
+      MAIN: https://example.com/kourier.yaml
+  dapr:
+    enabled: false
+`
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{"valid", installConfig, ""},
+		{"unknown field", installConfig + "unknown: true\n", "field unknown not found"},
+		{"unknown component", strings.Replace(installConfig, "dapr:", "istio:", 1), "unknown component istio"},
+		{"not toggleable", strings.Replace(installConfig, "dapr:", "tektonPipelines:", 1), "can't be enabled or disabled"},
+		{"wrong kind", strings.Replace(installConfig, "kind: InstallConfig", "kind: Config", 1), "unsupported config"},
+		{"invalid patch type", strings.Replace(installConfig, "patch: |", "type: apply\n      patch: |", 1), "unknown patch type apply"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "ofn-install.yaml")
+			if err := ioutil.WriteFile(file, []byte(tt.config), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := Load(file)
+			if tt.wantErr == "" && err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("want error %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestInstallConfig(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "ofn-install.yaml")
+	if err := ioutil.WriteFile(file, []byte(installConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := Load(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if time.Duration(c.Timeout) != 15*time.Minute {
+		t.Errorf("want timeout 15m, got %s", time.Duration(c.Timeout))
+	}
+	if want := map[string]bool{inventory.DaprName: false}; !reflect.DeepEqual(c.Enabled(), want) {
+		t.Errorf("want %v, got %v", want, c.Enabled())
+	}
+
+	namespaces, err := c.Namespaces(func(name string) string { return "knative-serving" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"knative-serving": "serving"}; !reflect.DeepEqual(namespaces, want) {
+		t.Errorf("want %v, got %v", want, namespaces)
+	}
+
+	data, err := c.Component(inventory.KnativeServingName).Patches[0].Data()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"data":{"enable-scale-to-zero":"false"}}`; string(data) != want {
+		t.Errorf("want %s, got %s", want, data)
+	}
+
+	// The config must be read back as it is written by --print-config.
+	out, err := c.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "timeout: 15m0s") {
+		t.Errorf("want the timeout in the duration format, got\n%s", out)
+	}
+}
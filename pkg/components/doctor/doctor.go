@@ -0,0 +1,288 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/OpenFunction/cli/pkg/components/common"
+	"github.com/OpenFunction/cli/pkg/components/inventory"
+	appsv1 "k8s.io/api/apps/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+	k8s "k8s.io/client-go/kubernetes"
+)
+
+const (
+	// MinKubernetesVersion is the lowest version of Kubernetes covered by the compatibility matrix.
+	MinKubernetesVersion = "v1.17.0"
+
+	certManagerWebhook         = "cert-manager-webhook"
+	kourierService             = "kourier"
+	defaultClassAnnotation     = "storageclass.kubernetes.io/is-default-class"
+	betaDefaultClassAnnotation = "storageclass.beta.kubernetes.io/is-default-class"
+)
+
+// Status is the outcome of a check.
+type Status string
+
+const (
+	Pass Status = "pass"
+	Warn Status = "warn"
+	Fail Status = "fail"
+)
+
+// Result is the result of a check.
+type Result struct {
+	Check   string `json:"check"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+}
+
+// requiredBinaries are the binaries used by the sub commands, with the reason why they are needed.
+var requiredBinaries = []struct {
+	name   string
+	reason string
+}{
+	{"dapr", "it will be downloaded by 'ofn install' when Dapr is installed"},
+	{"kind", "it will be downloaded by 'ofn demo'"},
+	{"docker", "required by 'ofn demo' to run a kind cluster"},
+}
+
+// conflictingIngresses are the namespaces of the ingresses which conflict with Kourier.
+var conflictingIngresses = map[string]string{
+	"istio-system":   "Istio",
+	"projectcontour": "Contour",
+}
+
+// lookPath is replaced in the tests.
+var lookPath = exec.LookPath
+
+// Doctor runs the preflight and health checks against a cluster.
+type Doctor struct {
+	cl                  k8s.Interface
+	openFunctionVersion string
+}
+
+// NewDoctor returns a Doctor checking the cluster for the given version of OpenFunction,
+// the default version is used if it's empty.
+func NewDoctor(cl k8s.Interface, openFunctionVersion string) *Doctor {
+	return &Doctor{
+		cl:                  cl,
+		openFunctionVersion: openFunctionVersion,
+	}
+}
+
+// Run runs every check and returns their results.
+func (d *Doctor) Run(ctx context.Context) []*Result {
+	var results []*Result
+	for _, check := range []func(context.Context) []*Result{
+		d.checkKubernetesVersion,
+		d.checkBinaries,
+		d.checkPermissions,
+		d.checkCertManagerWebhook,
+		d.checkDefaultStorageClass,
+		d.checkKourierExternalIP,
+		d.checkConflictingIngress,
+	} {
+		results = append(results, check(ctx)...)
+	}
+	return results
+}
+
+// Failed returns the number of failed checks.
+func Failed(results []*Result) int {
+	n := 0
+	for _, r := range results {
+		if r.Status == Fail {
+			n++
+		}
+	}
+	return n
+}
+
+func (d *Doctor) checkKubernetesVersion(ctx context.Context) []*Result {
+	const check = "Kubernetes version"
+
+	sv, err := d.cl.Discovery().ServerVersion()
+	if err != nil {
+		return []*Result{{check, Fail, fmt.Sprintf("failed to get the version of the cluster: %s", err)}}
+	}
+
+	v, err := version.ParseGeneric(sv.String())
+	if err != nil {
+		return []*Result{{check, Fail, fmt.Sprintf("invalid version %s: %s", sv.String(), err)}}
+	}
+	if v.LessThan(version.MustParseGeneric(MinKubernetesVersion)) {
+		return []*Result{{check, Fail, fmt.Sprintf("%s is lower than the supported version %s", sv.String(), MinKubernetesVersion)}}
+	}
+
+	inv, err := inventory.GetInventoryWithServerVersion(sv.String(), false, true, true, true, true, true, true, d.openFunctionVersion)
+	if err != nil {
+		return []*Result{{check, Fail, fmt.Sprintf("failed to get inventory: %s", err)}}
+	}
+
+	var unsupported []string
+	for name, iv := range inv {
+		if !inventory.IsSupported(iv) {
+			unsupported = append(unsupported, fmt.Sprintf("%s %s", name, iv.GetVersion()))
+		}
+	}
+	if len(unsupported) > 0 {
+		sort.Strings(unsupported)
+		return []*Result{{check, Warn, fmt.Sprintf(
+			"%s isn't covered by the compatibility matrix of %s, which may not work",
+			sv.String(), strings.Join(unsupported, ", "))}}
+	}
+	return []*Result{{check, Pass, fmt.Sprintf("%s is supported", sv.String())}}
+}
+
+func (d *Doctor) checkBinaries(ctx context.Context) []*Result {
+	var results []*Result
+	for _, b := range requiredBinaries {
+		check := fmt.Sprintf("Binary %s", b.name)
+		if p, err := lookPath(b.name); err != nil {
+			results = append(results, &Result{check, Warn, fmt.Sprintf("not found in PATH, %s", b.reason)})
+		} else {
+			results = append(results, &Result{check, Pass, p})
+		}
+	}
+	return results
+}
+
+func (d *Doctor) checkPermissions(ctx context.Context) []*Result {
+	var results []*Result
+	for _, attr := range []*authorizationv1.ResourceAttributes{
+		{Verb: "create", Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions"},
+		{Verb: "create", Resource: "namespaces"},
+	} {
+		check := fmt.Sprintf("Permission to %s %s", attr.Verb, attr.Resource)
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: attr},
+		}
+		review, err := d.cl.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+		switch {
+		case err != nil:
+			results = append(results, &Result{check, Fail, fmt.Sprintf("failed to review access: %s", err)})
+		case !review.Status.Allowed:
+			msg := "denied"
+			if review.Status.Reason != "" {
+				msg = fmt.Sprintf("denied: %s", review.Status.Reason)
+			}
+			results = append(results, &Result{check, Fail, msg})
+		default:
+			results = append(results, &Result{check, Pass, "allowed"})
+		}
+	}
+	return results
+}
+
+func (d *Doctor) checkCertManagerWebhook(ctx context.Context) []*Result {
+	const check = "cert-manager webhook"
+
+	deploy, err := d.cl.AppsV1().Deployments(common.CertManagerNamespace).Get(ctx, certManagerWebhook, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return []*Result{{check, Pass, "cert-manager isn't installed yet"}}
+	}
+	if err != nil {
+		return []*Result{{check, Fail, err.Error()}}
+	}
+	if !isDeploymentAvailable(deploy) {
+		return []*Result{{check, Fail, fmt.Sprintf("deployment %s/%s isn't available", deploy.Namespace, deploy.Name)}}
+	}
+
+	ep, err := d.cl.CoreV1().Endpoints(common.CertManagerNamespace).Get(ctx, certManagerWebhook, metav1.GetOptions{})
+	if err != nil {
+		return []*Result{{check, Fail, err.Error()}}
+	}
+	for _, s := range ep.Subsets {
+		if len(s.Addresses) > 0 {
+			return []*Result{{check, Pass, "ready"}}
+		}
+	}
+	return []*Result{{check, Fail, fmt.Sprintf("service %s/%s has no ready endpoint", ep.Namespace, ep.Name)}}
+}
+
+func (d *Doctor) checkDefaultStorageClass(ctx context.Context) []*Result {
+	const check = "Default StorageClass"
+
+	classes, err := d.cl.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return []*Result{{check, Fail, err.Error()}}
+	}
+	for _, sc := range classes.Items {
+		if sc.Annotations[defaultClassAnnotation] == "true" || sc.Annotations[betaDefaultClassAnnotation] == "true" {
+			return []*Result{{check, Pass, sc.Name}}
+		}
+	}
+	return []*Result{{check, Warn, "no default StorageClass, the PersistentVolumeClaims without storageClassName won't be bound"}}
+}
+
+func (d *Doctor) checkKourierExternalIP(ctx context.Context) []*Result {
+	const check = "Kourier external IP"
+
+	svc, err := d.cl.CoreV1().Services(common.KourierNamespace).Get(ctx, kourierService, metav1.GetOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return []*Result{{check, Fail, err.Error()}}
+	}
+	if err == nil {
+		if len(svc.Spec.ExternalIPs) > 0 {
+			return []*Result{{check, Pass, strings.Join(svc.Spec.ExternalIPs, ", ")}}
+		}
+		for _, ing := range svc.Status.LoadBalancer.Ingress {
+			if ing.IP != "" || ing.Hostname != "" {
+				return []*Result{{check, Pass, ing.IP + ing.Hostname}}
+			}
+		}
+		return []*Result{{check, Warn, fmt.Sprintf("service %s/%s has neither a LoadBalancer address nor an external IP", svc.Namespace, svc.Name)}}
+	}
+
+	// Kourier isn't installed yet, the nodes may be used as the external IPs.
+	nodes, err := d.cl.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return []*Result{{check, Fail, err.Error()}}
+	}
+	for _, n := range nodes.Items {
+		for _, addr := range n.Status.Addresses {
+			if addr.Type == corev1.NodeExternalIP {
+				return []*Result{{check, Pass, fmt.Sprintf("Kourier isn't installed yet, node %s has the external IP %s", n.Name, addr.Address)}}
+			}
+		}
+	}
+	return []*Result{{check, Warn, "Kourier isn't installed yet and no node has an external IP, make sure LoadBalancer services are supported"}}
+}
+
+func (d *Doctor) checkConflictingIngress(ctx context.Context) []*Result {
+	const check = "Conflicting ingress"
+
+	var found []string
+	for ns, name := range conflictingIngresses {
+		_, err := d.cl.CoreV1().Namespaces().Get(ctx, ns, metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return []*Result{{check, Fail, err.Error()}}
+		}
+		found = append(found, fmt.Sprintf("%s (namespace %s)", name, ns))
+	}
+	if len(found) > 0 {
+		sort.Strings(found)
+		return []*Result{{check, Warn, fmt.Sprintf("%s found, make sure it doesn't conflict with Kourier", strings.Join(found, ", "))}}
+	}
+	return []*Result{{check, Pass, "neither Istio nor Contour found"}}
+}
+
+func isDeploymentAvailable(deploy *appsv1.Deployment) bool {
+	for _, c := range deploy.Status.Conditions {
+		if c.Type == appsv1.DeploymentAvailable {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
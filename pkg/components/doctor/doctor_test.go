@@ -0,0 +1,63 @@
+package doctor
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCheckKubernetesVersion(t *testing.T) {
+	tests := []struct {
+		serverVersion string
+		want          Status
+	}{
+		{"v1.16.3", Fail},
+		{"v1.17.0", Pass},
+		{"v1.20.7", Pass},
+		{"v1.22.1", Warn},
+	}
+	for _, tt := range tests {
+		t.Run(tt.serverVersion, func(t *testing.T) {
+			cl := fake.NewSimpleClientset()
+			cl.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: tt.serverVersion}
+
+			results := NewDoctor(cl, "").checkKubernetesVersion(context.Background())
+			if len(results) != 1 || results[0].Status != tt.want {
+				t.Errorf("want %s, got %+v", tt.want, results)
+			}
+		})
+	}
+}
+
+func TestCheckBinaries(t *testing.T) {
+	defer func(orig func(string) (string, error)) { lookPath = orig }(lookPath)
+	lookPath = func(name string) (string, error) {
+		if name == "docker" {
+			return "/usr/local/bin/" + name, nil
+		}
+		return "", exec.ErrNotFound
+	}
+
+	want := map[string]Status{
+		"Binary dapr":   Warn,
+		"Binary kind":   Warn,
+		"Binary docker": Pass,
+	}
+	results := NewDoctor(fake.NewSimpleClientset(), "").checkBinaries(context.Background())
+	if len(results) != len(want) {
+		t.Fatalf("want %d results, got %+v", len(want), results)
+	}
+	for _, r := range results {
+		if r.Status != want[r.Check] {
+			t.Errorf("%s: want %s, got %+v", r.Check, want[r.Check], r)
+		}
+		if r.Status == Pass && r.Message != "/usr/local/bin/"+strings.TrimPrefix(r.Check, "Binary ") {
+			t.Errorf("%s: want the path of the binary, got %q", r.Check, r.Message)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ObjectError describes the failure of an operation on a single object of a manifest.
+type ObjectError struct {
+	GroupVersionKind schema.GroupVersionKind
+	Namespace        string
+	Name             string
+	Err              error
+}
+
+func (e *ObjectError) Error() string {
+	kind := strings.ToLower(e.GroupVersionKind.Kind)
+	if e.GroupVersionKind.Group != "" {
+		kind = fmt.Sprintf("%s.%s", kind, e.GroupVersionKind.Group)
+	}
+	if e.Namespace != "" {
+		return fmt.Sprintf("%s %s/%s: %s", kind, e.Namespace, e.Name, e.Err)
+	}
+	return fmt.Sprintf("%s %s: %s", kind, e.Name, e.Err)
+}
+
+func (e *ObjectError) Unwrap() error {
+	return e.Err
+}
+
+// ManifestError aggregates the object errors that occurred while processing a manifest.
+type ManifestError struct {
+	Source string
+	Errors []*ObjectError
+}
+
+func (e *ManifestError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("failed to process %s:\n%s", e.Source, strings.Join(msgs, "\n"))
+}
+
+// IgnoreAlreadyExists drops the object errors caused by already existing objects
+// and returns nil if no other error is left.
+func IgnoreAlreadyExists(err error) error {
+	me, ok := err.(*ManifestError)
+	if !ok {
+		return err
+	}
+
+	remaining := []*ObjectError{}
+	for _, e := range me.Errors {
+		if !k8serrors.IsAlreadyExists(e.Err) {
+			remaining = append(remaining, e)
+		}
+	}
+	if len(remaining) == 0 {
+		return nil
+	}
+	return &ManifestError{Source: me.Source, Errors: remaining}
+}
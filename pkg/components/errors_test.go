@@ -0,0 +1,80 @@
+package components
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestObjectError(t *testing.T) {
+	notFound := k8serrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, "config")
+	tests := []struct {
+		name string
+		err  *ObjectError
+		want string
+	}{
+		{
+			name: "namespaced",
+			err:  &ObjectError{schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, "keda", "config", notFound},
+			want: `configmap keda/config: configmaps "config" not found`,
+		},
+		{
+			name: "cluster scoped with group",
+			err:  &ObjectError{schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}, "", "functions.core.openfunction.io", errors.New("denied")},
+			want: "customresourcedefinition.apiextensions.k8s.io functions.core.openfunction.io: denied",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.want {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	var err error = &ManifestError{Source: "keda.yaml", Errors: []*ObjectError{tests[0].err}}
+	if want := "failed to process keda.yaml:\n" + tests[0].want; err.Error() != want {
+		t.Errorf("want %q, got %q", want, err.Error())
+	}
+	if !k8serrors.IsNotFound(tests[0].err) {
+		t.Error("want the object error to unwrap to the API error")
+	}
+}
+
+func TestIgnoreAlreadyExists(t *testing.T) {
+	gvk := schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+	exists := &ObjectError{gvk, "keda", "exists", k8serrors.NewAlreadyExists(schema.GroupResource{Resource: "configmaps"}, "exists")}
+	denied := &ObjectError{gvk, "keda", "denied", k8serrors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, "denied", errors.New("denied"))}
+	other := errors.New("failed to fetch keda.yaml")
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "nil", err: nil, want: nil},
+		{name: "not a manifest error", err: other, want: other},
+		{name: "already exists only", err: &ManifestError{Source: "keda.yaml", Errors: []*ObjectError{exists}}, want: nil},
+		{
+			name: "other object errors",
+			err:  &ManifestError{Source: "keda.yaml", Errors: []*ObjectError{exists, denied}},
+			want: &ManifestError{Source: "keda.yaml", Errors: []*ObjectError{denied}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := IgnoreAlreadyExists(tt.err)
+			if tt.want == nil || got == nil {
+				if got != tt.want {
+					t.Errorf("want %v, got %v", tt.want, got)
+				}
+				return
+			}
+			if got.Error() != tt.want.Error() {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/OpenFunction/cli/pkg/components"
+	"github.com/OpenFunction/cli/pkg/components/inventory"
+	"github.com/OpenFunction/cli/pkg/components/manifest"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+var _ components.OperatorExecutor = &Executor{}
+
+// Executor is an OperatorExecutor recording the operations instead of running them,
+// so that the installation and the uninstallation can be tested without a cluster.
+// Each operation is recorded as a call, e.g. "apply keda.yaml" or "exec dapr uninstall -k --all".
+type Executor struct {
+	// Errors are returned by the operations whose call is the key.
+	Errors map[string]error
+	// Record is the inventory record returned by GetInventoryRecord and saved by RecordInventory.
+	Record *inventory.Record
+	// NodeIP is returned by GetNodeIP.
+	NodeIP string
+
+	lock       sync.Mutex
+	calls      []string
+	transforms []manifest.Transform
+}
+
+func NewExecutor() *Executor {
+	return &Executor{
+		Errors: map[string]error{},
+		Record: &inventory.Record{},
+	}
+}
+
+// Calls returns the operations which have been called, in order.
+func (e *Executor) Calls() []string {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return append([]string(nil), e.calls...)
+}
+
+// Transforms returns the transforms added to the executor.
+func (e *Executor) Transforms() []manifest.Transform {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return append([]manifest.Transform(nil), e.transforms...)
+}
+
+func (e *Executor) call(format string, args ...interface{}) error {
+	c := fmt.Sprintf(format, args...)
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.calls = append(e.calls, c)
+	return e.Errors[c]
+}
+
+func (e *Executor) Exec(cmd string) (string, string, error) {
+	return "", "", e.call("exec %s", cmd)
+}
+
+func (e *Executor) DownloadDaprClient(version string, inRegionCN bool) error {
+	return e.call("download-dapr %s", version)
+}
+
+func (e *Executor) Apply(ctx context.Context, source string) error {
+	return e.call("apply %s", source)
+}
+
+func (e *Executor) Create(ctx context.Context, source string) error {
+	return e.call("create %s", source)
+}
+
+func (e *Executor) Delete(ctx context.Context, source string, wait bool) error {
+	return e.call("delete %s", source)
+}
+
+func (e *Executor) Patch(ctx context.Context, obj *unstructured.Unstructured, pt types.PatchType, data []byte) error {
+	return e.call("patch %s %s/%s", obj.GetKind(), obj.GetNamespace(), obj.GetName())
+}
+
+func (e *Executor) AddTransform(transform manifest.Transform) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.transforms = append(e.transforms, transform)
+}
+
+func (e *Executor) BeginTransaction() {
+	_ = e.call("begin-transaction")
+}
+
+func (e *Executor) Rollback(ctx context.Context) error {
+	return e.call("rollback")
+}
+
+func (e *Executor) RecordInventory(ctx context.Context, inventoryMap map[string]string) error {
+	if err := e.call("record-inventory"); err != nil {
+		return err
+	}
+	record, err := inventory.NewRecord(inventoryMap)
+	if err != nil {
+		return err
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.Record = record
+	return nil
+}
+
+func (e *Executor) GetInventoryRecord(ctx context.Context) (*inventory.Record, error) {
+	if err := e.call("get-inventory-record"); err != nil {
+		return nil, err
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	r := *e.Record
+	return &r, nil
+}
+
+func (e *Executor) DownloadKind(ctx context.Context, cf *genericclioptions.ConfigFlags) error {
+	return e.call("download-kind")
+}
+
+func (e *Executor) GetNodeIP(ctx context.Context) (string, error) {
+	return e.NodeIP, e.call("get-node-ip")
+}
@@ -4,12 +4,21 @@ import (
 	"context"
 
 	"github.com/OpenFunction/cli/pkg/components/inventory"
+	"github.com/OpenFunction/cli/pkg/components/manifest"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 )
 
 const (
 	OpenFunctionDir    = ".ofn"
 	RecordFileNameTmpl = "%s-inventory.yaml"
+
+	// The inventory record is kept in the cluster so that it is shared by all the users.
+	// It isn't stored in the namespace of OpenFunction, which is removed with OpenFunction.
+	RecordNamespace     = "kube-system"
+	RecordConfigMapName = "ofn-inventory"
+	RecordConfigMapKey  = "inventory.yaml"
 )
 
 // OperatorExecutor is an executor abstraction
@@ -18,10 +27,15 @@ const (
 type OperatorExecutor interface {
 	Exec(cmd string) (string, string, error)
 	DownloadDaprClient(version string, inRegionCN bool) error
-	KubectlExec(ctx context.Context, cmd string, wait bool) error
+	Apply(ctx context.Context, source string) error
+	Create(ctx context.Context, source string) error
+	Delete(ctx context.Context, source string, wait bool) error
+	Patch(ctx context.Context, obj *unstructured.Unstructured, pt types.PatchType, data []byte) error
+	AddTransform(transform manifest.Transform)
+	BeginTransaction()
+	Rollback(ctx context.Context) error
 	RecordInventory(ctx context.Context, inventoryMap map[string]string) error
 	GetInventoryRecord(ctx context.Context) (*inventory.Record, error)
 	DownloadKind(ctx context.Context, cf *genericclioptions.ConfigFlags) error
 	GetNodeIP(ctx context.Context) (string, error)
-	CurlOpenFunction(ctx context.Context, endPoint string) (string, error)
 }
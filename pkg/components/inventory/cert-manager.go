@@ -44,6 +44,10 @@ func (i *certManager) GetVersion() string {
 	return i.getDefaultVersion()
 }
 
+func (i *certManager) GetDependencies() []string {
+	return nil
+}
+
 func (i *certManager) GetYamlFile(ver string) (map[string]string, error) {
 	yamls := map[string]string{}
 	if f, ok := os.LookupEnv(CertManagerYamlEnv); ok {
@@ -43,6 +43,10 @@ func (i *dapr) GetVersion() string {
 	return i.getDefaultVersion()
 }
 
+func (i *dapr) GetDependencies() []string {
+	return nil
+}
+
 func (i *dapr) GetYamlFile(ver string) (map[string]string, error) {
 	return nil, nil
 }
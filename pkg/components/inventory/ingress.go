@@ -44,6 +44,10 @@ func (i *ingress) GetVersion() string {
 	return i.getDefaultVersion()
 }
 
+func (i *ingress) GetDependencies() []string {
+	return nil
+}
+
 func (i *ingress) GetYamlFile(ver string) (map[string]string, error) {
 	yamls := map[string]string{}
 	if f, ok := os.LookupEnv(IngressYamlEnv); ok {
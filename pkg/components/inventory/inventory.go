@@ -48,10 +48,13 @@ type Record struct {
 type Interface interface {
 	GetVersion() string
 	GetYamlFile(version string) (map[string]string, error)
+	// GetDependencies returns the names of the components
+	// that must be installed before this one.
+	GetDependencies() []string
 }
 
-func getKubernetesServerVersion(cl *k8s.Clientset) (string, error) {
-	if sv, err := cl.ServerVersion(); err != nil {
+func getKubernetesServerVersion(cl k8s.Interface) (string, error) {
+	if sv, err := cl.Discovery().ServerVersion(); err != nil {
 		return "", err
 	} else {
 		return sv.String(), nil
@@ -59,7 +62,7 @@ func getKubernetesServerVersion(cl *k8s.Clientset) (string, error) {
 }
 
 func GetInventory(
-	cl *k8s.Clientset,
+	cl k8s.Interface,
 	regionCN bool,
 	withKnative bool,
 	withKeda bool,
@@ -74,6 +77,33 @@ func GetInventory(
 		return nil, err
 	}
 
+	return GetInventoryWithServerVersion(
+		serverVersion,
+		regionCN,
+		withKnative,
+		withKeda,
+		withDapr,
+		withShipwright,
+		withCertManager,
+		withIngress,
+		openFunctionVersion,
+	)
+}
+
+// GetInventoryWithServerVersion is like GetInventory,
+// but selects the versions of the components for the given Kubernetes version
+// instead of the one of the current cluster.
+func GetInventoryWithServerVersion(
+	serverVersion string,
+	regionCN bool,
+	withKnative bool,
+	withKeda bool,
+	withDapr bool,
+	withShipwright bool,
+	withCertManager bool,
+	withIngress bool,
+	openFunctionVersion string,
+) (map[string]Interface, error) {
 	inventory := map[string]Interface{}
 
 	if withKnative {
@@ -219,6 +249,34 @@ func (r *Record) Update(newRecord *Record) {
 	}
 }
 
+// Merge performs a three-way merge of r, which was modified from base, into current.
+// The versions changed in r win, the other ones are taken from current.
+func (r *Record) Merge(base *Record, current *Record) (*Record, error) {
+	ours, theirs := r.ToMap(false), current.ToMap(false)
+	original := map[string]string{}
+	if base != nil {
+		original = base.ToMap(false)
+	}
+
+	merged := map[string]string{}
+	for k, v := range theirs {
+		merged[k] = v
+	}
+	for _, m := range []map[string]string{ours, original} {
+		for k := range m {
+			if ours[k] == original[k] {
+				continue
+			}
+			if ours[k] == "" {
+				delete(merged, k)
+			} else {
+				merged[k] = ours[k]
+			}
+		}
+	}
+	return NewRecord(merged)
+}
+
 func (r *Record) ToMap(humanize bool) map[string]string {
 	m := map[string]string{}
 	if &r.OpenFunction != nil && r.OpenFunction != "" {
@@ -304,3 +362,13 @@ func (r *Record) ToMap(humanize bool) map[string]string {
 
 	return m
 }
+
+// IsSupported tells whether the version of the component is known to be compatible
+// with the Kubernetes version it has been selected for.
+// The components without compatibility matrix are always supported.
+func IsSupported(iv Interface) bool {
+	if c, ok := iv.(interface{ isValidVersion(string) bool }); ok {
+		return c.isValidVersion(iv.GetVersion())
+	}
+	return true
+}
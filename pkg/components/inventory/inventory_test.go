@@ -0,0 +1,24 @@
+package inventory
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRecordMerge(t *testing.T) {
+	base := &Record{OpenFunction: "0.5.0", Keda: "2.4.0", Dapr: "1.5.1"}
+	// Someone else installed Shipwright and upgraded Keda in the meantime.
+	current := &Record{OpenFunction: "0.5.0", Keda: "2.5.0", Dapr: "1.5.1", Shipwright: "0.6.1"}
+	// We upgraded OpenFunction and uninstalled Dapr.
+	ours := &Record{OpenFunction: "0.6.0", Keda: "2.4.0"}
+
+	got, err := ours.Merge(base, current)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &Record{OpenFunction: "0.6.0", Keda: "2.5.0", Shipwright: "0.6.1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %+v, got %+v", want, got)
+	}
+}
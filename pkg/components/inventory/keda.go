@@ -46,6 +46,10 @@ func (i *keda) GetVersion() string {
 	return i.getDefaultVersion()
 }
 
+func (i *keda) GetDependencies() []string {
+	return nil
+}
+
 func (i *keda) GetYamlFile(ver string) (map[string]string, error) {
 	yamls := map[string]string{}
 	if f, ok := os.LookupEnv(KedaYamlEnv); ok {
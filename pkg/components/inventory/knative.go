@@ -46,6 +46,10 @@ func (i *knativeServing) GetVersion() string {
 	return i.getDefaultVersion()
 }
 
+func (i *knativeServing) GetDependencies() []string {
+	return nil
+}
+
 func (i *knativeServing) GetYamlFile(knVersion string) (map[string]string, error) {
 	yamls := map[string]string{}
 	if crdYaml, ok := os.LookupEnv(KnativeServingCrdYamlEnv); ok {
@@ -45,6 +45,11 @@ func (i *kourier) GetVersion() string {
 	return i.getDefaultVersion()
 }
 
+func (i *kourier) GetDependencies() []string {
+	// Kourier is configured as the ingress of Knative Serving.
+	return []string{KnativeServingName}
+}
+
 func (i *kourier) GetYamlFile(ver string) (map[string]string, error) {
 	yamls := map[string]string{}
 	if f, ok := os.LookupEnv(KourierYamlEnv); ok {
@@ -50,6 +50,30 @@ func (i *openFunction) GetVersion() string {
 	return i.getDefaultVersion()
 }
 
+func (i *openFunction) GetDependencies() []string {
+	// The controller reconciles the resources of the runtimes and the builder,
+	// so OpenFunction is installed after them.
+	deps := []string{
+		KnativeServingName,
+		KourierName,
+		ServingDefaultDomainName,
+		KedaName,
+		DaprName,
+		ShipwrightName,
+		IngressName,
+	}
+
+	// The webhooks of OpenFunction rely on Cert Manager since v0.4.0.
+	ver := i.GetVersion()
+	if ver == "latest" {
+		return append(deps, CertManagerName)
+	}
+	if v, ok := isValidVersion(ver); ok && (v.Major() > 0 || v.Minor() >= 4) {
+		return append(deps, CertManagerName)
+	}
+	return deps
+}
+
 func (i *openFunction) GetYamlFile(ver string) (map[string]string, error) {
 	yamls := map[string]string{}
 	if f, ok := os.LookupEnv(OpenFunctionYamlEnv); ok {
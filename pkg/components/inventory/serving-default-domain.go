@@ -45,6 +45,11 @@ func (i *defaultDomain) GetVersion() string {
 	return i.getDefaultVersion()
 }
 
+func (i *defaultDomain) GetDependencies() []string {
+	// The default domain job configures Knative Serving.
+	return []string{KnativeServingName}
+}
+
 func (i *defaultDomain) GetYamlFile(ver string) (map[string]string, error) {
 	yamls := map[string]string{}
 	if f, ok := os.LookupEnv(ServingDefaultDomainYamlEnv); ok {
@@ -44,6 +44,11 @@ func (i *shipwright) GetVersion() string {
 	return i.getDefaultVersion()
 }
 
+func (i *shipwright) GetDependencies() []string {
+	// Shipwright runs its builds on Tekton Pipelines.
+	return []string{TektonPipelinesName}
+}
+
 func (i *shipwright) GetYamlFile(ver string) (map[string]string, error) {
 	yamls := map[string]string{}
 	if f, ok := os.LookupEnv(ShipwrightYamlEnv); ok {
@@ -44,6 +44,10 @@ func (i *tektonPipelines) GetVersion() string {
 	return i.getDefaultVersion()
 }
 
+func (i *tektonPipelines) GetDependencies() []string {
+	return nil
+}
+
 func (i *tektonPipelines) GetYamlFile(ver string) (map[string]string, error) {
 	yamls := map[string]string{}
 	if f, ok := os.LookupEnv(TektonPipelinesYamlEnv); ok {
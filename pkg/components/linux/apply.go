@@ -0,0 +1,299 @@
+package linux
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/OpenFunction/cli/pkg/components"
+	"github.com/OpenFunction/cli/pkg/components/manifest"
+	"github.com/pkg/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+)
+
+const (
+	// FieldManager is the field manager used for server-side apply.
+	FieldManager = "ofn"
+
+	crdKind       = "CustomResourceDefinition"
+	namespaceKind = "Namespace"
+)
+
+type operation func(ctx context.Context, ri dynamic.ResourceInterface, obj *unstructured.Unstructured) error
+
+// AddTransform registers a transform which is applied to every object before it is processed.
+func (e *Executor) AddTransform(transform manifest.Transform) {
+	e.transforms = append(e.transforms, transform)
+}
+
+// Apply fetches the manifest located at source and
+// server-side applies every object it contains.
+func (e *Executor) Apply(ctx context.Context, source string) error {
+	return e.process(ctx, source, false, func(ctx context.Context, ri dynamic.ResourceInterface, obj *unstructured.Unstructured) error {
+		data, err := obj.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		force := true
+		_, err = ri.Patch(ctx, obj.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{
+			FieldManager: FieldManager,
+			Force:        &force,
+		})
+		return err
+	})
+}
+
+// Create fetches the manifest located at source and creates every object it contains.
+// Objects that already exist are reported as object errors.
+func (e *Executor) Create(ctx context.Context, source string) error {
+	return e.process(ctx, source, false, func(ctx context.Context, ri dynamic.ResourceInterface, obj *unstructured.Unstructured) error {
+		_, err := ri.Create(ctx, obj, metav1.CreateOptions{FieldManager: FieldManager})
+		return err
+	})
+}
+
+// Delete fetches the manifest located at source and deletes every object it contains
+// in reverse order. Objects that do not exist are ignored.
+// If wait is true, it blocks until all the objects are gone.
+func (e *Executor) Delete(ctx context.Context, source string, wait bool) error {
+	propagation := metav1.DeletePropagationBackground
+	return e.process(ctx, source, true, func(ctx context.Context, ri dynamic.ResourceInterface, obj *unstructured.Unstructured) error {
+		if err := ri.Delete(ctx, obj.GetName(), metav1.DeleteOptions{PropagationPolicy: &propagation}); err != nil {
+			if k8serrors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		if wait {
+			return waitForDeletion(ctx, ri, obj.GetName())
+		}
+		return nil
+	})
+}
+
+// Patch patches the object with the apiVersion, kind, namespace and name of obj.
+func (e *Executor) Patch(ctx context.Context, obj *unstructured.Unstructured, pt types.PatchType, data []byte) error {
+	dc, mapper, err := e.getDynamicClient()
+	if err != nil {
+		return err
+	}
+
+	return e.processObject(ctx, dc, mapper, obj, false, func(ctx context.Context, ri dynamic.ResourceInterface, obj *unstructured.Unstructured) error {
+		_, err := ri.Patch(ctx, obj.GetName(), pt, data, metav1.PatchOptions{FieldManager: FieldManager})
+		return err
+	})
+}
+
+func (e *Executor) process(ctx context.Context, source string, reverse bool, op operation) error {
+	dc, mapper, err := e.getDynamicClient()
+	if err != nil {
+		return err
+	}
+
+	objs, err := manifest.Load(ctx, source)
+	if err != nil {
+		return err
+	}
+	for _, obj := range objs {
+		for _, transform := range e.transforms {
+			if err := transform(obj); err != nil {
+				return errors.Wrapf(err, "failed to transform %s %s", obj.GetKind(), obj.GetName())
+			}
+		}
+	}
+	sortObjects(objs, reverse)
+
+	me := &components.ManifestError{Source: source}
+	for _, obj := range objs {
+		if err := e.processObject(ctx, dc, mapper, obj, reverse, op); err != nil {
+			// The context is gone, there is no point in going on.
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			me.Errors = append(me.Errors, &components.ObjectError{
+				GroupVersionKind: obj.GroupVersionKind(),
+				Namespace:        obj.GetNamespace(),
+				Name:             obj.GetName(),
+				Err:              err,
+			})
+		}
+	}
+
+	if len(me.Errors) != 0 {
+		return me
+	}
+	return nil
+}
+
+func (e *Executor) processObject(
+	ctx context.Context,
+	dc dynamic.Interface,
+	mapper *restmapper.DeferredDiscoveryRESTMapper,
+	obj *unstructured.Unstructured,
+	deleting bool,
+	op operation,
+) error {
+	gvk := obj.GroupVersionKind()
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		// The kind may have been registered by a CRD applied after the discovery cache was filled.
+		mapper.Reset()
+		mapping, err = mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	}
+	if err != nil {
+		// There is nothing to delete if the kind is not served anymore.
+		if deleting && meta.IsNoMatchError(err) {
+			return nil
+		}
+		return err
+	}
+
+	var ri dynamic.ResourceInterface
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		if obj.GetNamespace() == "" {
+			obj.SetNamespace(metav1.NamespaceDefault)
+		}
+		ri = dc.Resource(mapping.Resource).Namespace(obj.GetNamespace())
+	} else {
+		ri = dc.Resource(mapping.Resource)
+	}
+
+	e.lock.Lock()
+	t := e.transaction
+	e.lock.Unlock()
+
+	run := func() error { return op(ctx, ri, obj) }
+	if t != nil && !deleting {
+		err = t.track(ctx, ri, obj, run)
+	} else {
+		err = run()
+	}
+	if err != nil {
+		return err
+	}
+
+	if e.verbose {
+		fmt.Printf("%s %s processed\n", mapping.Resource.GroupResource().String(), obj.GetName())
+	}
+
+	// Ensure that a CRD is established before the objects depending on it are processed.
+	if !deleting && gvk.Kind == crdKind {
+		return waitForEstablished(ctx, ri, obj.GetName())
+	}
+	return nil
+}
+
+// sortObjects moves the namespaces and the CRDs in front of the other objects,
+// or behind them when reverse is true, and keeps the manifest order otherwise.
+func sortObjects(objs []*unstructured.Unstructured, reverse bool) {
+	weight := func(obj *unstructured.Unstructured) int {
+		switch obj.GetKind() {
+		case namespaceKind:
+			return 0
+		case crdKind:
+			return 1
+		default:
+			return 2
+		}
+	}
+
+	sort.SliceStable(objs, func(i, j int) bool {
+		return weight(objs[i]) < weight(objs[j])
+	})
+
+	if reverse {
+		for i, j := 0, len(objs)-1; i < j; i, j = i+1, j-1 {
+			objs[i], objs[j] = objs[j], objs[i]
+		}
+	}
+}
+
+func waitForEstablished(ctx context.Context, ri dynamic.ResourceInterface, name string) error {
+	return poll(ctx, func() (bool, error) {
+		crd, err := ri.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		conditions, _, _ := unstructured.NestedSlice(crd.Object, "status", "conditions")
+		for _, c := range conditions {
+			condition, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if condition["type"] == "Established" && condition["status"] == "True" {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+}
+
+func waitForDeletion(ctx context.Context, ri dynamic.ResourceInterface, name string) error {
+	return poll(ctx, func() (bool, error) {
+		if _, err := ri.Get(ctx, name, metav1.GetOptions{}); err != nil {
+			if k8serrors.IsNotFound(err) {
+				return true, nil
+			}
+			return false, err
+		}
+		return false, nil
+	})
+}
+
+func poll(ctx context.Context, condition func() (bool, error)) error {
+	t := time.NewTicker(time.Second)
+	defer t.Stop()
+
+	for {
+		if done, err := condition(); err != nil || done {
+			return err
+		}
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return errors.Wrap(
+				ctx.Err(),
+				"context marked done. stopping check loop",
+			)
+		}
+	}
+}
+
+func (e *Executor) getDynamicClient() (dynamic.Interface, *restmapper.DeferredDiscoveryRESTMapper, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.dynamicClient != nil {
+		return e.dynamicClient, e.mapper, nil
+	}
+
+	if e.cf == nil {
+		return nil, nil, errors.New("no kubeconfig is available to the executor")
+	}
+
+	config, err := e.cf.ToRESTConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dc, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	discoveryClient, err := e.cf.ToDiscoveryClient()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	e.dynamicClient = dc
+	e.mapper = restmapper.NewDeferredDiscoveryRESTMapper(discoveryClient)
+	return e.dynamicClient, e.mapper, nil
+}
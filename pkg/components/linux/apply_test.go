@@ -0,0 +1,291 @@
+package linux
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/OpenFunction/cli/pkg/components"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery/cached/memory"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/restmapper"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var (
+	configMapResource = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	namespaceResource = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+	functionResource  = schema.GroupVersionResource{Group: "core.openfunction.io", Version: "v1beta1", Resource: "functions"}
+
+	coreResources = &metav1.APIResourceList{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{
+			{Name: "configmaps", Kind: "ConfigMap", Namespaced: true},
+			{Name: "namespaces", Kind: "Namespace"},
+		},
+	}
+	functionResources = &metav1.APIResourceList{
+		GroupVersion: "core.openfunction.io/v1beta1",
+		APIResources: []metav1.APIResource{
+			{Name: "functions", Kind: "Function", Namespaced: true},
+		},
+	}
+)
+
+// newFakeExecutor returns an executor whose dynamic client is a fake one holding objs,
+// the resources served by the fake API server can be changed with the returned discovery client.
+func newFakeExecutor(objs ...runtime.Object) (*Executor, *dynamicfake.FakeDynamicClient, *fakediscovery.FakeDiscovery) {
+	discovery := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{Resources: []*metav1.APIResourceList{coreResources}}}
+	dc := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), objs...)
+	return &Executor{
+		dynamicClient: dc,
+		mapper:        restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discovery)),
+	}, dc, discovery
+}
+
+func newObject(apiVersion string, kind string, namespace string, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
+
+func createOp(ctx context.Context, ri dynamic.ResourceInterface, obj *unstructured.Unstructured) error {
+	_, err := ri.Create(ctx, obj, metav1.CreateOptions{})
+	return err
+}
+
+func TestSortObjects(t *testing.T) {
+	objs := func() []*unstructured.Unstructured {
+		return []*unstructured.Unstructured{
+			newObject("apps/v1", "Deployment", "demo", "controller"),
+			newObject("apiextensions.k8s.io/v1", crdKind, "", "functions.core.openfunction.io"),
+			newObject("v1", "ServiceAccount", "demo", "controller"),
+			newObject("v1", namespaceKind, "", "demo"),
+			newObject("apiextensions.k8s.io/v1", crdKind, "", "builders.core.openfunction.io"),
+		}
+	}
+	names := func(objs []*unstructured.Unstructured) string {
+		var names []string
+		for _, obj := range objs {
+			names = append(names, obj.GetKind()+"/"+obj.GetName())
+		}
+		return strings.Join(names, ",")
+	}
+
+	tests := []struct {
+		name    string
+		reverse bool
+		want    string
+	}{
+		{
+			name: "apply",
+			want: "Namespace/demo," +
+				"CustomResourceDefinition/functions.core.openfunction.io," +
+				"CustomResourceDefinition/builders.core.openfunction.io," +
+				"Deployment/controller," +
+				"ServiceAccount/controller",
+		},
+		{
+			name:    "delete",
+			reverse: true,
+			want: "ServiceAccount/controller," +
+				"Deployment/controller," +
+				"CustomResourceDefinition/builders.core.openfunction.io," +
+				"CustomResourceDefinition/functions.core.openfunction.io," +
+				"Namespace/demo",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := objs()
+			sortObjects(got, tt.reverse)
+			if names(got) != tt.want {
+				t.Errorf("want %s, got %s", tt.want, names(got))
+			}
+		})
+	}
+}
+
+func TestProcessObject(t *testing.T) {
+	ctx := context.Background()
+	existing := newObject("v1", "ConfigMap", "demo", "existing")
+
+	patchOp := func(ctx context.Context, ri dynamic.ResourceInterface, obj *unstructured.Unstructured) error {
+		_, err := ri.Patch(ctx, obj.GetName(), types.MergePatchType, []byte(`{"data":{"key":"value"}}`), metav1.PatchOptions{})
+		return err
+	}
+	deleteOp := func(ctx context.Context, ri dynamic.ResourceInterface, obj *unstructured.Unstructured) error {
+		return ri.Delete(ctx, obj.GetName(), metav1.DeleteOptions{})
+	}
+
+	tests := []struct {
+		name     string
+		obj      *unstructured.Unstructured
+		deleting bool
+		op       operation
+		// check is run against the fake dynamic client after the object has been processed.
+		check   func(t *testing.T, dc *dynamicfake.FakeDynamicClient)
+		wantErr bool
+	}{
+		{
+			name: "create",
+			obj:  newObject("v1", "ConfigMap", "demo", "created"),
+			op:   createOp,
+			check: func(t *testing.T, dc *dynamicfake.FakeDynamicClient) {
+				if _, err := dc.Resource(configMapResource).Namespace("demo").Get(ctx, "created", metav1.GetOptions{}); err != nil {
+					t.Error(err)
+				}
+			},
+		},
+		{
+			name: "create cluster scoped",
+			obj:  newObject("v1", namespaceKind, "", "created"),
+			op:   createOp,
+			check: func(t *testing.T, dc *dynamicfake.FakeDynamicClient) {
+				if _, err := dc.Resource(namespaceResource).Get(ctx, "created", metav1.GetOptions{}); err != nil {
+					t.Error(err)
+				}
+			},
+		},
+		{
+			name: "namespaced object without namespace",
+			obj:  newObject("v1", "ConfigMap", "", "created"),
+			op:   createOp,
+			check: func(t *testing.T, dc *dynamicfake.FakeDynamicClient) {
+				if _, err := dc.Resource(configMapResource).Namespace(metav1.NamespaceDefault).Get(ctx, "created", metav1.GetOptions{}); err != nil {
+					t.Error(err)
+				}
+			},
+		},
+		{
+			name: "patch",
+			obj:  newObject("v1", "ConfigMap", "demo", "existing"),
+			op:   patchOp,
+			check: func(t *testing.T, dc *dynamicfake.FakeDynamicClient) {
+				obj, err := dc.Resource(configMapResource).Namespace("demo").Get(ctx, "existing", metav1.GetOptions{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if v, _, _ := unstructured.NestedString(obj.Object, "data", "key"); v != "value" {
+					t.Errorf("want the object patched, got %v", obj.Object)
+				}
+			},
+		},
+		{
+			name:     "delete",
+			obj:      newObject("v1", "ConfigMap", "demo", "existing"),
+			deleting: true,
+			op:       deleteOp,
+			check: func(t *testing.T, dc *dynamicfake.FakeDynamicClient) {
+				if _, err := dc.Resource(configMapResource).Namespace("demo").Get(ctx, "existing", metav1.GetOptions{}); err == nil {
+					t.Error("want the object deleted")
+				}
+			},
+		},
+		{
+			name:     "delete kind not served",
+			obj:      newObject("example.com/v1", "Unknown", "demo", "existing"),
+			deleting: true,
+			op:       deleteOp,
+		},
+		{
+			name:    "create kind not served",
+			obj:     newObject("example.com/v1", "Unknown", "demo", "created"),
+			op:      createOp,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, dc, _ := newFakeExecutor(existing.DeepCopy())
+			err := e.processObject(ctx, e.dynamicClient, e.mapper, tt.obj, tt.deleting, tt.op)
+			if tt.wantErr {
+				if !meta.IsNoMatchError(err) {
+					t.Fatalf("want a no match error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.check != nil {
+				tt.check(t, dc)
+			}
+		})
+	}
+}
+
+func TestProcessObjectResetsMapper(t *testing.T) {
+	ctx := context.Background()
+	e, dc, discovery := newFakeExecutor()
+
+	// Fill the discovery cache before the kind is registered, as if its CRD had just been applied.
+	if err := e.processObject(ctx, e.dynamicClient, e.mapper, newObject("v1", "ConfigMap", "demo", "config"), false, createOp); err != nil {
+		t.Fatal(err)
+	}
+	discovery.Resources = append(discovery.Resources, functionResources)
+
+	if err := e.processObject(ctx, e.dynamicClient, e.mapper, newObject("core.openfunction.io/v1beta1", "Function", "demo", "sample"), false, createOp); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dc.Resource(functionResource).Namespace("demo").Get(ctx, "sample", metav1.GetOptions{}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	ctx := context.Background()
+	manifest := filepath.Join(t.TempDir(), "manifest.yaml")
+	if err := ioutil.WriteFile(manifest, []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: existing
+  namespace: demo
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: created
+  namespace: demo
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: demo
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e, dc, _ := newFakeExecutor(newObject("v1", "ConfigMap", "demo", "existing"))
+	err := e.Create(ctx, manifest)
+
+	me, ok := err.(*components.ManifestError)
+	if !ok || len(me.Errors) != 1 || me.Errors[0].Name != "existing" || !k8serrors.IsAlreadyExists(me.Errors[0]) {
+		t.Fatalf("want an already exists error for the existing object, got %v", err)
+	}
+	if err := components.IgnoreAlreadyExists(err); err != nil {
+		t.Errorf("want the already exists error ignored, got %v", err)
+	}
+	if _, err := dc.Resource(configMapResource).Namespace("demo").Get(ctx, "created", metav1.GetOptions{}); err != nil {
+		t.Error(err)
+	}
+	if _, err := dc.Resource(namespaceResource).Get(ctx, "demo", metav1.GetOptions{}); err != nil {
+		t.Error(err)
+	}
+}
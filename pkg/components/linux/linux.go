@@ -4,26 +4,43 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"io/ioutil"
-	"os"
 	"os/exec"
-	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/OpenFunction/cli/pkg/components"
 	"github.com/OpenFunction/cli/pkg/components/inventory"
+	"github.com/OpenFunction/cli/pkg/components/manifest"
 	"github.com/pkg/errors"
-	"gopkg.in/yaml.v2"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/dynamic"
+	k8s "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/restmapper"
 )
 
 type Executor struct {
-	verbose bool
+	verbose    bool
+	cf         genericclioptions.RESTClientGetter
+	transforms []manifest.Transform
+
+	lock          sync.Mutex
+	dynamicClient dynamic.Interface
+	mapper        *restmapper.DeferredDiscoveryRESTMapper
+	clientset     k8s.Interface
+
+	// The record read by GetInventoryRecord,
+	// RecordInventory merges the changes made since then into the current record.
+	recordBase            *inventory.Record
+	recordResourceVersion string
+
+	// The changes tracked since BeginTransaction, nil if there is no transaction.
+	transaction *transaction
 }
 
-func NewExecutor(verbose bool) components.OperatorExecutor {
+func NewExecutor(cf genericclioptions.RESTClientGetter, verbose bool) components.OperatorExecutor {
 	return &Executor{
 		verbose: verbose,
+		cf:      cf,
 	}
 }
 
@@ -63,141 +80,16 @@ func (e *Executor) DownloadDaprClient(daprVersion string, inRegionCN bool) error
 	return nil
 }
 
-func (e *Executor) KubectlExec(
-	ctx context.Context,
-	cmd string,
-	wait bool,
-) error {
-	var kubectlCMD string
-	kubectlCMD = fmt.Sprintf("kubectl %s", cmd)
-
-	// The `kubectl create` command does not support using the --wait option.
-	if !wait && !strings.Contains(kubectlCMD, "create") {
-		kubectlCMD += " --wait=false"
-	}
-
-	if _, _, err := e.Exec(kubectlCMD); err != nil {
-		return err
-	}
-	return nil
-}
-
 func (e *Executor) getClusterName(ctx context.Context) (string, error) {
-	cmd := "kubectl config current-context"
-
-	if out, _, err := e.Exec(cmd); err != nil {
-		return "", err
-	} else {
-		return strings.TrimSuffix(out, "\n"), nil
-	}
-}
-
-func (e *Executor) RecordInventory(ctx context.Context, inventoryMap map[string]string) error {
-	dirname, err := os.UserHomeDir()
-	if err != nil {
-		return err
+	if e.cf == nil {
+		return "", errors.New("no kubeconfig is available to the executor")
 	}
 
-	if err := os.Mkdir(filepath.Join(dirname, components.OpenFunctionDir), os.ModePerm); err != nil {
-		if !strings.Contains(err.Error(), "file exists") {
-			return err
-		}
-	}
-
-	cName, err := e.getClusterName(ctx)
+	rawConfig, err := e.cf.ToRawKubeConfigLoader().RawConfig()
 	if err != nil {
-		return err
-	}
-
-	fileName := fmt.Sprintf(components.RecordFileNameTmpl, cName)
-
-	filePath := filepath.Join(dirname, components.OpenFunctionDir, fileName)
-	file, err := os.Open(filePath)
-	if err != nil {
-		return err
-	}
-
-	defer file.Close()
-
-	oldYaml, err := ioutil.ReadFile(file.Name())
-	if err != nil {
-		return err
-	}
-
-	record := &inventory.Record{}
-	err = yaml.Unmarshal(oldYaml, record)
-	if err != nil {
-		return err
-	}
-
-	newRecord, err := inventory.NewRecord(inventoryMap)
-	if err != nil {
-		return err
-	}
-
-	record.Update(newRecord)
-
-	recordData, err := yaml.Marshal(record)
-	if err != nil {
-		return err
-	}
-
-	err = ioutil.WriteFile(filePath, recordData, 0644)
-	if err != nil {
-		panic(err)
-	}
-	return nil
-}
-
-func (e *Executor) GetInventoryRecord(ctx context.Context) (*inventory.Record, error) {
-	var file *os.File
-
-	dirname, err := os.UserHomeDir()
-	if err != nil {
-		return nil, err
-	}
-
-	if err := os.Mkdir(filepath.Join(dirname, components.OpenFunctionDir), os.ModePerm); err != nil {
-		if !strings.Contains(err.Error(), "file exists") {
-			return nil, err
-		}
-	}
-
-	cName, err := e.getClusterName(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	fileName := fmt.Sprintf(components.RecordFileNameTmpl, cName)
-
-	filePath := filepath.Join(dirname, components.OpenFunctionDir, fileName)
-
-	if _, err := os.Stat(filePath); errors.Is(err, os.ErrNotExist) {
-		file, err = os.Create(filePath)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	file, err = os.Open(filePath)
-	if err != nil {
-		return nil, err
-	}
-
-	defer file.Close()
-
-	recordYaml, err := ioutil.ReadFile(file.Name())
-	if err != nil {
-		return nil, err
-	}
-
-	record := &inventory.Record{}
-	err = yaml.Unmarshal(recordYaml, record)
-	if err != nil {
-		return nil, err
+		return "", err
 	}
-
-	return record, nil
+	return rawConfig.CurrentContext, nil
 }
 
 func (e *Executor) DownloadKind(ctx context.Context, cf *genericclioptions.ConfigFlags) error {
@@ -232,12 +124,3 @@ func (e *Executor) GetNodeIP(ctx context.Context) (string, error) {
 	}
 	return nodeIP, nil
 }
-
-func (e *Executor) CurlOpenFunction(ctx context.Context, endpoint string) (string, error) {
-	curlCMD := fmt.Sprintf("curl %s", endpoint)
-	res, _, err := e.Exec(curlCMD)
-	if err != nil {
-		return "", err
-	}
-	return strings.TrimSuffix(res, "\n"), nil
-}
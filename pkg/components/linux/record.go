@@ -0,0 +1,237 @@
+package linux
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/OpenFunction/cli/pkg/components"
+	"github.com/OpenFunction/cli/pkg/components/inventory"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8s "k8s.io/client-go/kubernetes"
+)
+
+const (
+	recordTimeout    = 30 * time.Second
+	recordMaxRetries = 5
+	migratedSuffix   = ".migrated"
+	managedByLabel   = "app.kubernetes.io/managed-by"
+)
+
+// GetInventoryRecord returns the inventory record kept in the cluster.
+// The record kept in the local file by the former versions is merged into it the first time.
+func (e *Executor) GetInventoryRecord(ctx context.Context) (*inventory.Record, error) {
+	cl, err := e.getClientset()
+	if err != nil {
+		return nil, err
+	}
+
+	record, cm, err := getRecord(ctx, cl)
+	if err != nil {
+		return nil, err
+	}
+
+	if cm, err = e.migrateLocalRecord(ctx, cl, record, cm); err != nil {
+		return nil, errors.Wrap(err, "failed to migrate the local inventory record")
+	}
+	if cm != nil {
+		if record, err = decodeRecord(cm); err != nil {
+			return nil, err
+		}
+		e.recordResourceVersion = cm.ResourceVersion
+	}
+
+	e.recordBase = record
+	r := *record
+	return &r, nil
+}
+
+// RecordInventory saves the inventory record in the cluster.
+// If the record has been changed by someone else since GetInventoryRecord,
+// the changes made in inventoryMap are merged into the current record.
+func (e *Executor) RecordInventory(ctx context.Context, inventoryMap map[string]string) error {
+	// The record must be saved even if the operation has timed out.
+	ctx, done := context.WithTimeout(context.Background(), recordTimeout)
+	defer done()
+
+	cl, err := e.getClientset()
+	if err != nil {
+		return err
+	}
+
+	record, err := inventory.NewRecord(inventoryMap)
+	if err != nil {
+		return err
+	}
+
+	base, resourceVersion := e.recordBase, e.recordResourceVersion
+	for i := 0; i < recordMaxRetries; i++ {
+		cm, err := writeRecord(ctx, cl, record, resourceVersion)
+		if err == nil {
+			e.recordBase, e.recordResourceVersion = record, cm.ResourceVersion
+			return nil
+		}
+		if !k8serrors.IsConflict(err) && !k8serrors.IsAlreadyExists(err) && !k8serrors.IsNotFound(err) {
+			return err
+		}
+
+		current, cm, err := getRecord(ctx, cl)
+		if err != nil {
+			return err
+		}
+		if record, err = record.Merge(base, current); err != nil {
+			return err
+		}
+
+		base, resourceVersion = current, ""
+		if cm != nil {
+			resourceVersion = cm.ResourceVersion
+		}
+	}
+	return errors.Errorf("failed to save the inventory record after %d attempts", recordMaxRetries)
+}
+
+// migrateLocalRecord merges the record of the local file into the record of the cluster,
+// the versions known by the cluster are kept. The local file is renamed once it has been migrated.
+func (e *Executor) migrateLocalRecord(ctx context.Context, cl k8s.Interface, record *inventory.Record, cm *corev1.ConfigMap) (*corev1.ConfigMap, error) {
+	filePath, err := e.localRecordPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return cm, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	local := &inventory.Record{}
+	if err := yaml.Unmarshal(data, local); err != nil {
+		return nil, err
+	}
+
+	m := record.ToMap(false)
+	for k, v := range local.ToMap(false) {
+		if m[k] == "" {
+			m[k] = v
+		}
+	}
+	merged, err := inventory.NewRecord(m)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceVersion := ""
+	if cm != nil {
+		resourceVersion = cm.ResourceVersion
+	}
+	if cm, err = writeRecord(ctx, cl, merged, resourceVersion); err != nil {
+		return nil, err
+	}
+
+	if err := os.Rename(filePath, filePath+migratedSuffix); err != nil {
+		return nil, err
+	}
+	return cm, nil
+}
+
+func (e *Executor) localRecordPath(ctx context.Context) (string, error) {
+	dirname, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	cName, err := e.getClusterName(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	fileName := fmt.Sprintf(components.RecordFileNameTmpl, cName)
+	return filepath.Join(dirname, components.OpenFunctionDir, fileName), nil
+}
+
+// getRecord returns the record kept in the cluster along with its ConfigMap,
+// or an empty record and a nil ConfigMap if there is no record yet.
+func getRecord(ctx context.Context, cl k8s.Interface) (*inventory.Record, *corev1.ConfigMap, error) {
+	cm, err := cl.CoreV1().ConfigMaps(components.RecordNamespace).Get(ctx, components.RecordConfigMapName, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return &inventory.Record{}, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	record, err := decodeRecord(cm)
+	if err != nil {
+		return nil, nil, err
+	}
+	return record, cm, nil
+}
+
+// writeRecord creates the ConfigMap of the record if resourceVersion is empty,
+// otherwise it updates the ConfigMap provided that it still has the given resourceVersion.
+func writeRecord(ctx context.Context, cl k8s.Interface, record *inventory.Record, resourceVersion string) (*corev1.ConfigMap, error) {
+	data, err := yaml.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            components.RecordConfigMapName,
+			Namespace:       components.RecordNamespace,
+			ResourceVersion: resourceVersion,
+			Labels: map[string]string{
+				managedByLabel: FieldManager,
+			},
+		},
+		Data: map[string]string{
+			components.RecordConfigMapKey: string(data),
+		},
+	}
+
+	if resourceVersion == "" {
+		return cl.CoreV1().ConfigMaps(components.RecordNamespace).Create(ctx, cm, metav1.CreateOptions{FieldManager: FieldManager})
+	}
+	return cl.CoreV1().ConfigMaps(components.RecordNamespace).Update(ctx, cm, metav1.UpdateOptions{FieldManager: FieldManager})
+}
+
+func decodeRecord(cm *corev1.ConfigMap) (*inventory.Record, error) {
+	record := &inventory.Record{}
+	if err := yaml.Unmarshal([]byte(cm.Data[components.RecordConfigMapKey]), record); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode the inventory record in %s/%s", cm.Namespace, cm.Name)
+	}
+	return record, nil
+}
+
+func (e *Executor) getClientset() (k8s.Interface, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.clientset != nil {
+		return e.clientset, nil
+	}
+
+	if e.cf == nil {
+		return nil, errors.New("no kubeconfig is available to the executor")
+	}
+
+	config, err := e.cf.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if e.clientset, err = k8s.NewForConfig(config); err != nil {
+		return nil, err
+	}
+	return e.clientset, nil
+}
@@ -0,0 +1,312 @@
+package linux
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/OpenFunction/cli/pkg/components"
+	"github.com/OpenFunction/cli/pkg/components/inventory"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	fakek8s "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const recordKubeconfig = `
+apiVersion: v1
+kind: Config
+current-context: demo
+contexts:
+- name: demo
+  context:
+    cluster: demo
+clusters:
+- name: demo
+  cluster:
+    server: https://127.0.0.1:6443
+`
+
+var configMapGVR = corev1.SchemeGroupVersion.WithResource("configmaps")
+
+// newRecordExecutor returns an executor using cl whose cluster is named demo,
+// the home directory is set to a temporary directory for the local records.
+func newRecordExecutor(t *testing.T, cl *fakek8s.Clientset) (*Executor, string) {
+	t.Helper()
+	home := t.TempDir()
+	home0 := os.Getenv("HOME")
+	os.Setenv("HOME", home)
+	t.Cleanup(func() { os.Setenv("HOME", home0) })
+
+	kubeconfig := filepath.Join(home, "kubeconfig")
+	if err := ioutil.WriteFile(kubeconfig, []byte(recordKubeconfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cf := genericclioptions.NewConfigFlags(false)
+	cf.KubeConfig = &kubeconfig
+	return &Executor{cf: cf, clientset: cl}, home
+}
+
+// withResourceVersions makes the fake clientset check and bump the resourceVersion of the ConfigMaps
+// the way the API server does.
+func withResourceVersions(cl *fakek8s.Clientset) {
+	cl.PrependReactor("create", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		cm := action.(k8stesting.CreateAction).GetObject().(*corev1.ConfigMap).DeepCopy()
+		cm.ResourceVersion = "1"
+		return true, cm, cl.Tracker().Create(configMapGVR, cm, cm.Namespace)
+	})
+	cl.PrependReactor("update", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		cm := action.(k8stesting.UpdateAction).GetObject().(*corev1.ConfigMap).DeepCopy()
+		obj, err := cl.Tracker().Get(configMapGVR, cm.Namespace, cm.Name)
+		if err != nil {
+			return true, nil, err
+		}
+		current := obj.(*corev1.ConfigMap)
+		if cm.ResourceVersion != current.ResourceVersion {
+			return true, nil, k8serrors.NewConflict(configMapGVR.GroupResource(), cm.Name, nil)
+		}
+		rv, _ := strconv.Atoi(current.ResourceVersion)
+		cm.ResourceVersion = strconv.Itoa(rv + 1)
+		return true, cm, cl.Tracker().Update(configMapGVR, cm, cm.Namespace)
+	})
+}
+
+func recordConfigMap(t *testing.T, record *inventory.Record, resourceVersion string) *corev1.ConfigMap {
+	t.Helper()
+	data, err := yaml.Marshal(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            components.RecordConfigMapName,
+			Namespace:       components.RecordNamespace,
+			ResourceVersion: resourceVersion,
+		},
+		Data: map[string]string{components.RecordConfigMapKey: string(data)},
+	}
+}
+
+// savedRecord returns the record kept in the cluster along with its ConfigMap.
+func savedRecord(t *testing.T, cl *fakek8s.Clientset) (*inventory.Record, *corev1.ConfigMap) {
+	t.Helper()
+	cm, err := cl.CoreV1().ConfigMaps(components.RecordNamespace).Get(context.Background(), components.RecordConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, err := decodeRecord(cm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return record, cm
+}
+
+func TestRecordInventory(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// existing is the record in the cluster before GetInventoryRecord.
+		existing *inventory.Record
+		// concurrent is the record saved by someone else between GetInventoryRecord and RecordInventory.
+		concurrent *inventory.Record
+		// change is applied to the record returned by GetInventoryRecord before it's saved.
+		change              func(r *inventory.Record)
+		alwaysConflict      bool
+		want                *inventory.Record
+		wantResourceVersion string
+		wantErr             string
+	}{
+		{
+			name:                "created",
+			change:              func(r *inventory.Record) { r.OpenFunction = "0.6.0" },
+			want:                &inventory.Record{OpenFunction: "0.6.0"},
+			wantResourceVersion: "1",
+		},
+		{
+			name:                "updated",
+			existing:            &inventory.Record{OpenFunction: "0.6.0", Keda: "2.4.0"},
+			change:              func(r *inventory.Record) { r.Keda = "" },
+			want:                &inventory.Record{OpenFunction: "0.6.0"},
+			wantResourceVersion: "2",
+		},
+		{
+			name:                "conflict merged",
+			existing:            &inventory.Record{OpenFunction: "0.6.0", Keda: "2.4.0"},
+			concurrent:          &inventory.Record{OpenFunction: "0.6.0", Keda: "2.4.0", Dapr: "1.5.1"},
+			change:              func(r *inventory.Record) { r.Keda = "2.5.0" },
+			want:                &inventory.Record{OpenFunction: "0.6.0", Keda: "2.5.0", Dapr: "1.5.1"},
+			wantResourceVersion: "3",
+		},
+		{
+			name:                "created concurrently",
+			concurrent:          &inventory.Record{OpenFunction: "0.6.0", Dapr: "1.5.1"},
+			change:              func(r *inventory.Record) { r.Keda = "2.4.0" },
+			want:                &inventory.Record{OpenFunction: "0.6.0", Keda: "2.4.0", Dapr: "1.5.1"},
+			wantResourceVersion: "2",
+		},
+		{
+			name:           "too many conflicts",
+			existing:       &inventory.Record{OpenFunction: "0.6.0"},
+			change:         func(r *inventory.Record) { r.Keda = "2.4.0" },
+			alwaysConflict: true,
+			wantErr:        "failed to save the inventory record after 5 attempts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cl := fakek8s.NewSimpleClientset()
+			withResourceVersions(cl)
+			if tt.existing != nil {
+				if _, err := cl.CoreV1().ConfigMaps(components.RecordNamespace).Create(ctx, recordConfigMap(t, tt.existing, ""), metav1.CreateOptions{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			e, _ := newRecordExecutor(t, cl)
+
+			record, err := e.GetInventoryRecord(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.concurrent != nil {
+				var err error
+				if tt.existing != nil {
+					_, err = cl.CoreV1().ConfigMaps(components.RecordNamespace).Update(ctx, recordConfigMap(t, tt.concurrent, "1"), metav1.UpdateOptions{})
+				} else {
+					_, err = cl.CoreV1().ConfigMaps(components.RecordNamespace).Create(ctx, recordConfigMap(t, tt.concurrent, ""), metav1.CreateOptions{})
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			updates := 0
+			if tt.alwaysConflict {
+				cl.PrependReactor("update", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+					updates++
+					return true, nil, k8serrors.NewConflict(configMapGVR.GroupResource(), components.RecordConfigMapName, nil)
+				})
+			}
+
+			tt.change(record)
+			err = e.RecordInventory(ctx, record.ToMap(false))
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("want error %q, got %v", tt.wantErr, err)
+				}
+				if updates != recordMaxRetries {
+					t.Errorf("want %d attempts, got %d", recordMaxRetries, updates)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, cm := savedRecord(t, cl)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want record %+v, got %+v", tt.want, got)
+			}
+			if cm.ResourceVersion != tt.wantResourceVersion {
+				t.Errorf("want resourceVersion %s, got %s", tt.wantResourceVersion, cm.ResourceVersion)
+			}
+			if cm.Labels[managedByLabel] != FieldManager {
+				t.Errorf("want the record labelled as managed by %s, got %v", FieldManager, cm.Labels)
+			}
+		})
+	}
+}
+
+func TestMigrateLocalRecord(t *testing.T) {
+	ctx := context.Background()
+	local := &inventory.Record{OpenFunction: "0.5.0", Keda: "2.4.0"}
+
+	tests := []struct {
+		name     string
+		existing *inventory.Record
+		noLocal  bool
+		want     *inventory.Record
+	}{
+		{
+			name: "no record in the cluster",
+			want: &inventory.Record{OpenFunction: "0.5.0", Keda: "2.4.0"},
+		},
+		{
+			name:     "versions of the cluster kept",
+			existing: &inventory.Record{OpenFunction: "0.6.0", Dapr: "1.5.1"},
+			want:     &inventory.Record{OpenFunction: "0.6.0", Keda: "2.4.0", Dapr: "1.5.1"},
+		},
+		{
+			name:     "no local record",
+			existing: &inventory.Record{OpenFunction: "0.6.0"},
+			noLocal:  true,
+			want:     &inventory.Record{OpenFunction: "0.6.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cl := fakek8s.NewSimpleClientset()
+			withResourceVersions(cl)
+			if tt.existing != nil {
+				if _, err := cl.CoreV1().ConfigMaps(components.RecordNamespace).Create(ctx, recordConfigMap(t, tt.existing, ""), metav1.CreateOptions{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			e, home := newRecordExecutor(t, cl)
+
+			file := filepath.Join(home, components.OpenFunctionDir, "demo-inventory.yaml")
+			if path, err := e.localRecordPath(ctx); err != nil || path != file {
+				t.Fatalf("want the local record at %s, got %s, %v", file, path, err)
+			}
+			if !tt.noLocal {
+				data, err := yaml.Marshal(local)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := ioutil.WriteFile(file, data, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			record, err := e.GetInventoryRecord(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(record, tt.want) {
+				t.Errorf("want record %+v, got %+v", tt.want, record)
+			}
+			if got, _ := savedRecord(t, cl); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want saved record %+v, got %+v", tt.want, got)
+			}
+
+			if _, err := os.Stat(file); !os.IsNotExist(err) {
+				t.Errorf("want %s renamed, got %v", file, err)
+			}
+			_, err = os.Stat(file + migratedSuffix)
+			if tt.noLocal != os.IsNotExist(err) {
+				t.Errorf("want %s%s to exist: %t, got %v", file, migratedSuffix, !tt.noLocal, err)
+			}
+
+			// The local record is only migrated once.
+			if _, err := e.GetInventoryRecord(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if got, _ := savedRecord(t, cl); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want saved record %+v after migration, got %+v", tt.want, got)
+			}
+		})
+	}
+}
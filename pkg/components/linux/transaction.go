@@ -0,0 +1,143 @@
+package linux
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/OpenFunction/cli/pkg/components"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+const rollbackSource = "rollback"
+
+// change is an object changed during a transaction.
+type change struct {
+	ri  dynamic.ResourceInterface
+	obj *unstructured.Unstructured
+	// previous is the object before it was changed, or nil if it has been created.
+	previous *unstructured.Unstructured
+}
+
+// transaction keeps the changes made to the objects in the order they have been made.
+type transaction struct {
+	lock    sync.Mutex
+	changes []*change
+	seen    map[string]bool
+}
+
+// BeginTransaction makes the executor keep track of the objects it applies, creates or patches from now on,
+// so that the changes can be reverted by Rollback.
+func (e *Executor) BeginTransaction() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.transaction = &transaction{seen: map[string]bool{}}
+}
+
+// Rollback reverts the changes made since BeginTransaction in reverse order,
+// the objects which have been created are deleted and the ones which have been changed are restored.
+func (e *Executor) Rollback(ctx context.Context) error {
+	e.lock.Lock()
+	t := e.transaction
+	e.transaction = nil
+	e.lock.Unlock()
+
+	if t == nil {
+		return nil
+	}
+
+	me := &components.ManifestError{Source: rollbackSource}
+	propagation := metav1.DeletePropagationBackground
+	for i := len(t.changes) - 1; i >= 0; i-- {
+		c := t.changes[i]
+
+		var err error
+		if c.previous == nil {
+			err = c.ri.Delete(ctx, c.obj.GetName(), metav1.DeleteOptions{PropagationPolicy: &propagation})
+			if k8serrors.IsNotFound(err) {
+				err = nil
+			}
+		} else {
+			err = restore(ctx, c.ri, c.previous)
+		}
+
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			me.Errors = append(me.Errors, &components.ObjectError{
+				GroupVersionKind: c.obj.GroupVersionKind(),
+				Namespace:        c.obj.GetNamespace(),
+				Name:             c.obj.GetName(),
+				Err:              err,
+			})
+			continue
+		}
+
+		if e.verbose {
+			fmt.Printf("%s %s rolled back\n", c.obj.GetKind(), c.obj.GetName())
+		}
+	}
+
+	if len(me.Errors) != 0 {
+		return me
+	}
+	return nil
+}
+
+// track runs op on obj and keeps the state of obj before it,
+// the state before the first change of an object is the one restored by Rollback.
+func (t *transaction) track(ctx context.Context, ri dynamic.ResourceInterface, obj *unstructured.Unstructured, op func() error) error {
+	key := obj.GroupVersionKind().String() + "/" + obj.GetNamespace() + "/" + obj.GetName()
+
+	t.lock.Lock()
+	seen := t.seen[key]
+	t.lock.Unlock()
+	if seen {
+		return op()
+	}
+
+	previous, err := ri.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		previous, err = nil, nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := op(); err != nil {
+		return err
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if !t.seen[key] {
+		t.seen[key] = true
+		t.changes = append(t.changes, &change{ri: ri, obj: obj.DeepCopy(), previous: previous})
+	}
+	return nil
+}
+
+// restore puts back the previous state of an object.
+func restore(ctx context.Context, ri dynamic.ResourceInterface, previous *unstructured.Unstructured) error {
+	current, err := ri.Get(ctx, previous.GetName(), metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		obj := previous.DeepCopy()
+		obj.SetResourceVersion("")
+		obj.SetUID("")
+		_, err = ri.Create(ctx, obj, metav1.CreateOptions{FieldManager: FieldManager})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	obj := previous.DeepCopy()
+	obj.SetResourceVersion(current.GetResourceVersion())
+	_, err = ri.Update(ctx, obj, metav1.UpdateOptions{FieldManager: FieldManager})
+	return err
+}
@@ -0,0 +1,101 @@
+package linux
+
+import (
+	"context"
+	"testing"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+func TestRollback(t *testing.T) {
+	ctx := context.Background()
+	configMap := func(name string, value string) *unstructured.Unstructured {
+		obj := newObject("v1", "ConfigMap", "demo", name)
+		unstructured.SetNestedField(obj.Object, value, "data", "key")
+		return obj
+	}
+	patch := func(value string) operation {
+		return func(ctx context.Context, ri dynamic.ResourceInterface, obj *unstructured.Unstructured) error {
+			_, err := ri.Patch(ctx, obj.GetName(), types.MergePatchType, []byte(`{"data":{"key":"`+value+`"}}`), metav1.PatchOptions{})
+			return err
+		}
+	}
+	deleteOp := func(ctx context.Context, ri dynamic.ResourceInterface, obj *unstructured.Unstructured) error {
+		return ri.Delete(ctx, obj.GetName(), metav1.DeleteOptions{})
+	}
+
+	e, dc, _ := newFakeExecutor(
+		configMap("patched", "before"),
+		configMap("deleted", "before"),
+		configMap("deleted-by-uninstall", "before"),
+	)
+	cms := dc.Resource(configMapResource).Namespace("demo")
+
+	// The changes made before the transaction aren't rolled back.
+	if err := e.processObject(ctx, e.dynamicClient, e.mapper, configMap("created-before", "before"), false, createOp); err != nil {
+		t.Fatal(err)
+	}
+
+	e.BeginTransaction()
+	for _, step := range []struct {
+		obj      *unstructured.Unstructured
+		deleting bool
+		op       operation
+	}{
+		{configMap("created", "after"), false, createOp},
+		{configMap("patched", ""), false, patch("after")},
+		// The state before the first change is the one restored.
+		{configMap("patched", ""), false, patch("again")},
+		{configMap("deleted", ""), false, patch("after")},
+		// The objects deleted by the executor aren't tracked.
+		{configMap("deleted-by-uninstall", ""), true, deleteOp},
+	} {
+		if err := e.processObject(ctx, e.dynamicClient, e.mapper, step.obj, step.deleting, step.op); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Someone else deletes an object changed during the transaction.
+	if err := cms.Delete(ctx, "deleted", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.Rollback(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"created-before": "before",
+		"patched":        "before",
+		"deleted":        "before",
+	}
+	for name, value := range want {
+		obj, err := cms.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if v, _, _ := unstructured.NestedString(obj.Object, "data", "key"); v != value {
+			t.Errorf("%s: want %q, got %q", name, value, v)
+		}
+	}
+	for _, name := range []string{"created", "deleted-by-uninstall"} {
+		if _, err := cms.Get(ctx, name, metav1.GetOptions{}); !k8serrors.IsNotFound(err) {
+			t.Errorf("%s: want the object to be gone, got %v", name, err)
+		}
+	}
+
+	// The transaction is over once it has been rolled back.
+	if err := e.processObject(ctx, e.dynamicClient, e.mapper, configMap("created-after", "after"), false, createOp); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Rollback(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cms.Get(ctx, "created-after", metav1.GetOptions{}); err != nil {
+		t.Error(err)
+	}
+}
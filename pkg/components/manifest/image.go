@@ -0,0 +1,124 @@
+package manifest
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	defaultDomain        = "docker.io"
+	officialRepoPrefix   = "library/"
+	imageFieldName       = "image"
+	containerNameFieldID = "name"
+)
+
+var (
+	// imageRefRegexp matches the fully qualified image references,
+	// i.e. with a registry domain and a tag or a digest,
+	// which are found outside of the image fields,
+	// such as in the arguments of a container or in the data of a ConfigMap.
+	imageRefRegexp = regexp.MustCompile(
+		`^(?:[a-zA-Z0-9-]+\.)+[a-zA-Z0-9-]+(?::[0-9]+)?` +
+			`(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)+` +
+			`(?::[\w][\w.-]{0,127})?(?:@sha256:[a-f0-9]{64})?$`,
+	)
+
+	// containerImageRegexp matches the values of the image fields which are references,
+	// as opposed to placeholders like $(params.builder-image).
+	containerImageRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._/:@-]*$`)
+)
+
+// Transform modifies an object of a manifest before it is processed.
+type Transform func(obj *unstructured.Unstructured) error
+
+// Relocate returns a Transform replacing the registry of every image referenced by an object with registry,
+// e.g. gcr.io/knative-releases/knative.dev/serving/cmd/controller@sha256:... becomes
+// registry.corp.local/mirror/knative-releases/knative.dev/serving/cmd/controller@sha256:...
+func Relocate(registry string) Transform {
+	return func(obj *unstructured.Unstructured) error {
+		obj.Object = walkImages(obj.Object, func(ref string) string {
+			return RelocateImage(ref, registry)
+		}).(map[string]interface{})
+		return nil
+	}
+}
+
+// RelocateImage returns the reference of image in registry.
+func RelocateImage(image string, registry string) string {
+	_, path := splitImage(image)
+	return strings.TrimSuffix(registry, "/") + "/" + path
+}
+
+// Images returns the sorted and deduplicated list of images referenced by the objects.
+func Images(objs []*unstructured.Unstructured) []string {
+	set := map[string]bool{}
+	for _, obj := range objs {
+		walkImages(obj.Object, func(ref string) string {
+			domain, path := splitImage(ref)
+			set[domain+"/"+path] = true
+			return ref
+		})
+	}
+
+	images := make([]string, 0, len(set))
+	for image := range set {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+	return images
+}
+
+// splitImage splits an image reference into the registry domain and the remainder,
+// using the same defaults as Docker for the references without domain.
+func splitImage(image string) (string, string) {
+	i := strings.IndexRune(image, '/')
+	if i == -1 {
+		return defaultDomain, officialRepoPrefix + image
+	}
+
+	domain := image[:i]
+	if !strings.ContainsAny(domain, ".:") && domain != "localhost" {
+		return defaultDomain, image
+	}
+	return domain, image[i+1:]
+}
+
+// walkImages calls fn for every image reference found in v and replaces it by the result.
+func walkImages(v interface{}, fn func(ref string) string) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		// The image field of a container, the reference may not be fully qualified.
+		_, isContainer := t[containerNameFieldID]
+		for key, val := range t {
+			if s, ok := val.(string); ok && key == imageFieldName && isContainer && containerImageRegexp.MatchString(s) {
+				t[key] = fn(s)
+				continue
+			}
+			t[key] = walkImages(val, fn)
+		}
+		return t
+	case []interface{}:
+		for i := range t {
+			t[i] = walkImages(t[i], fn)
+		}
+		return t
+	case string:
+		if isImageRef(t) {
+			return fn(t)
+		}
+		return t
+	default:
+		return v
+	}
+}
+
+func isImageRef(s string) bool {
+	// A reference without tag nor digest can't be told apart from a host name and a path.
+	if !strings.Contains(s, "@sha256:") && !strings.Contains(s[strings.LastIndex(s, "/")+1:], ":") {
+		return false
+	}
+	return imageRefRegexp.MatchString(s)
+}
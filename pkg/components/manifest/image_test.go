@@ -0,0 +1,79 @@
+package manifest
+
+import (
+	"reflect"
+	"testing"
+)
+
+const relocateManifest = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: controller
+spec:
+  template:
+    spec:
+      containers:
+      - name: controller
+        image: gcr.io/tekton-releases/controller:v0.30.0
+        args: ["-git-image", "gcr.io/tekton-releases/git-init@sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", "-url", "https://example.com/x:1"]
+      initContainers:
+      - name: init
+        image: busybox
+      - name: placeholder
+        image: $(params.image)
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config-deployment
+data:
+  queueSidecarImage: gcr.io/knative-releases/queue:v1.0.1
+  registriesSkippingTagResolving: kind.local,ko.local
+`
+
+func TestImages(t *testing.T) {
+	objs, err := Decode([]byte(relocateManifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"docker.io/library/busybox",
+		"gcr.io/knative-releases/queue:v1.0.1",
+		"gcr.io/tekton-releases/controller:v0.30.0",
+		"gcr.io/tekton-releases/git-init@sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+	}
+	if got := Images(objs); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestRelocate(t *testing.T) {
+	objs, err := Decode([]byte(relocateManifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	relocate := Relocate("registry.corp.local/mirror/")
+	for _, obj := range objs {
+		if err := relocate(obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{
+		"registry.corp.local/mirror/knative-releases/queue:v1.0.1",
+		"registry.corp.local/mirror/library/busybox",
+		"registry.corp.local/mirror/tekton-releases/controller:v0.30.0",
+		"registry.corp.local/mirror/tekton-releases/git-init@sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+	}
+	if got := Images(objs); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+
+	containers := objs[0].Object["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})["initContainers"].([]interface{})
+	if image := containers[1].(map[string]interface{})["image"]; image != "$(params.image)" {
+		t.Errorf("placeholder should be kept, got %v", image)
+	}
+}
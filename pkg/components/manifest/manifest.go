@@ -0,0 +1,89 @@
+package manifest
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const (
+	decoderBufferSize = 4096
+)
+
+// Fetch returns the content of the manifest located at source,
+// which can be either a http(s) URL or a path on the local file system.
+func Fetch(ctx context.Context, source string) ([]byte, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		return ioutil.ReadFile(strings.TrimPrefix(source, "file://"))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to fetch %s: %s", source, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// Decode splits a multi-document YAML or JSON manifest into objects.
+// Empty documents are skipped and `List` kinds are flattened into their items.
+func Decode(data []byte) ([]*unstructured.Unstructured, error) {
+	var objs []*unstructured.Unstructured
+
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), decoderBufferSize)
+	for {
+		raw := map[string]interface{}{}
+		if err := decoder.Decode(&raw); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, errors.Wrap(err, "failed to decode manifest")
+		}
+		if len(raw) == 0 {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{Object: raw}
+		if obj.IsList() {
+			list, err := obj.ToList()
+			if err != nil {
+				return nil, err
+			}
+			for i := range list.Items {
+				objs = append(objs, &list.Items[i])
+			}
+			continue
+		}
+
+		if obj.GetKind() == "" {
+			return nil, errors.Errorf("object %q has no kind", obj.GetName())
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+// Load fetches the manifest located at source and decodes it into objects.
+func Load(ctx context.Context, source string) ([]*unstructured.Unstructured, error) {
+	data, err := Fetch(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(data)
+}
@@ -0,0 +1,113 @@
+package manifest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "multiple documents",
+			data: `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: keda
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: keda-operator
+  namespace: keda
+`,
+			want: []string{"Namespace/keda", "ServiceAccount/keda-operator"},
+		},
+		{
+			name: "empty documents",
+			data: `
+---
+# comment only
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: keda
+---
+`,
+			want: []string{"Namespace/keda"},
+		},
+		{
+			name: "list",
+			data: `
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: first
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: second
+---
+apiVersion: v1
+kind: ConfigMapList
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: third
+`,
+			want: []string{"ConfigMap/first", "ConfigMap/second", "ConfigMap/third"},
+		},
+		{
+			name: "json",
+			data: `{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "keda"}}`,
+			want: []string{"Namespace/keda"},
+		},
+		{
+			name: "missing kind",
+			data: `
+apiVersion: v1
+metadata:
+  name: keda
+`,
+			wantErr: `object "keda" has no kind`,
+		},
+		{
+			name:    "invalid",
+			data:    "kind: [Namespace\n",
+			wantErr: "failed to decode manifest",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objs, err := Decode([]byte(tt.data))
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("want error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, obj := range objs {
+				got = append(got, obj.GetKind()+"/"+obj.GetName())
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
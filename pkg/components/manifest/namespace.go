@@ -0,0 +1,63 @@
+package manifest
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	namespaceKind          = "Namespace"
+	namespaceFieldName     = "namespace"
+	injectCAFromAnnotation = "cert-manager.io/inject-ca-from"
+)
+
+// RenameNamespaces returns a Transform moving the objects from the namespaces which are keys of namespaces
+// to the corresponding values. The references to these namespaces are renamed as well,
+// e.g. the subjects of the role bindings, the services of the webhooks
+// and the certificates injected by cert-manager.
+func RenameNamespaces(namespaces map[string]string) Transform {
+	return func(obj *unstructured.Unstructured) error {
+		if obj.GetKind() == namespaceKind {
+			if to, ok := namespaces[obj.GetName()]; ok {
+				obj.SetName(to)
+			}
+		}
+
+		obj.Object = walkNamespaces(obj.Object, namespaces).(map[string]interface{})
+
+		annotations := obj.GetAnnotations()
+		if ref, ok := annotations[injectCAFromAnnotation]; ok {
+			parts := strings.SplitN(ref, "/", 2)
+			if to, ok := namespaces[parts[0]]; ok && len(parts) == 2 {
+				annotations[injectCAFromAnnotation] = to + "/" + parts[1]
+				obj.SetAnnotations(annotations)
+			}
+		}
+		return nil
+	}
+}
+
+// walkNamespaces renames the values of the namespace fields found in v.
+func walkNamespaces(v interface{}, namespaces map[string]string) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for key, val := range t {
+			if s, ok := val.(string); ok && key == namespaceFieldName {
+				if to, ok := namespaces[s]; ok {
+					t[key] = to
+				}
+				continue
+			}
+			t[key] = walkNamespaces(val, namespaces)
+		}
+		return t
+	case []interface{}:
+		for i := range t {
+			t[i] = walkNamespaces(t[i], namespaces)
+		}
+		return t
+	default:
+		return v
+	}
+}
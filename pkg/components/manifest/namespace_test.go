@@ -0,0 +1,76 @@
+package manifest
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const namespaceManifest = `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: knative-serving
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: controller-admin
+subjects:
+- kind: ServiceAccount
+  name: controller
+  namespace: knative-serving
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: validation.webhook.serving.knative.dev
+  annotations:
+    cert-manager.io/inject-ca-from: knative-serving/serving-cert
+webhooks:
+- name: validation.webhook.serving.knative.dev
+  clientConfig:
+    service:
+      name: webhook
+      namespace: knative-serving
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: kourier
+  namespace: kourier-system
+`
+
+func TestRenameNamespaces(t *testing.T) {
+	objs, err := Decode([]byte(namespaceManifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rename := RenameNamespaces(map[string]string{"knative-serving": "serving"})
+	for _, obj := range objs {
+		if err := rename(obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	check := func(obj *unstructured.Unstructured, want string, fields ...string) {
+		if got, _, _ := unstructured.NestedString(obj.Object, fields...); got != want {
+			t.Errorf("%s %s: want %s at %v, got %s", obj.GetKind(), obj.GetName(), want, fields, got)
+		}
+	}
+	check(objs[0], "serving", "metadata", "name")
+	check(objs[3], "kourier-system", "metadata", "namespace")
+
+	if got := objs[2].GetAnnotations()[injectCAFromAnnotation]; got != "serving/serving-cert" {
+		t.Errorf("want serving/serving-cert, got %s", got)
+	}
+	subjects, _, _ := unstructured.NestedSlice(objs[1].Object, "subjects")
+	if got := subjects[0].(map[string]interface{})["namespace"]; got != "serving" {
+		t.Errorf("want the subject in serving, got %s", got)
+	}
+	webhooks, _, _ := unstructured.NestedSlice(objs[2].Object, "webhooks")
+	if got, _, _ := unstructured.NestedString(webhooks[0].(map[string]interface{}), "clientConfig", "service", "namespace"); got != "serving" {
+		t.Errorf("want the webhook service in serving, got %s", got)
+	}
+}
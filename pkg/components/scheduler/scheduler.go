@@ -0,0 +1,226 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/OpenFunction/cli/pkg/cmd/util/spinners"
+	"github.com/pkg/errors"
+)
+
+// Task is the work of a node, it reports the progress and the result through the spinner.
+type Task func(ctx context.Context, spinner *spinners.Spinner)
+
+type node struct {
+	name         string
+	dependencies []string
+	task         Task
+}
+
+// Scheduler runs the tasks of a dependency graph with maximum parallelism,
+// a task is started as soon as all its prerequisites are done.
+type Scheduler struct {
+	nodes   map[string]*node
+	reverse bool
+}
+
+// NewScheduler returns a Scheduler that runs a node after its dependencies.
+func NewScheduler() *Scheduler {
+	return &Scheduler{
+		nodes: map[string]*node{},
+	}
+}
+
+// NewReverseScheduler returns a Scheduler that runs a node after the nodes depending on it,
+// which is the order used to uninstall components.
+func NewReverseScheduler() *Scheduler {
+	return &Scheduler{
+		nodes:   map[string]*node{},
+		reverse: true,
+	}
+}
+
+// Add adds a node to the graph.
+// The dependencies which are not part of the graph are ignored.
+func (s *Scheduler) Add(name string, dependencies []string, task Task) {
+	s.nodes[name] = &node{
+		name:         name,
+		dependencies: dependencies,
+		task:         task,
+	}
+}
+
+// Len returns the number of nodes in the graph.
+func (s *Scheduler) Len() int {
+	return len(s.nodes)
+}
+
+// prerequisites returns the nodes that must be done before the node with the given name.
+func (s *Scheduler) prerequisites(name string) []string {
+	var prereqs []string
+
+	if !s.reverse {
+		for _, dep := range s.nodes[name].dependencies {
+			if _, ok := s.nodes[dep]; ok && dep != name {
+				prereqs = append(prereqs, dep)
+			}
+		}
+	} else {
+		for _, n := range s.nodes {
+			if n.name == name {
+				continue
+			}
+			for _, dep := range n.dependencies {
+				if dep == name {
+					prereqs = append(prereqs, n.name)
+					break
+				}
+			}
+		}
+	}
+
+	sort.Strings(prereqs)
+	return prereqs
+}
+
+// Order returns the nodes grouped by levels,
+// the nodes of a level only depend on the nodes of the previous levels.
+func (s *Scheduler) Order() ([][]string, error) {
+	pending := map[string][]string{}
+	for name := range s.nodes {
+		pending[name] = s.prerequisites(name)
+	}
+
+	done := map[string]bool{}
+	var levels [][]string
+	for len(pending) != 0 {
+		var level []string
+		for name, prereqs := range pending {
+			ready := true
+			for _, p := range prereqs {
+				if !done[p] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				level = append(level, name)
+			}
+		}
+
+		if len(level) == 0 {
+			var names []string
+			for name := range pending {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, errors.Errorf("dependency cycle detected between %s", strings.Join(names, ", "))
+		}
+
+		sort.Strings(level)
+		for _, name := range level {
+			done[name] = true
+			delete(pending, name)
+		}
+		levels = append(levels, level)
+	}
+	return levels, nil
+}
+
+type state struct {
+	spinner *spinners.Spinner
+	done    chan struct{}
+}
+
+// Run runs all the nodes and displays a spinner for each of them.
+// Once a node fails, the nodes that haven't started yet are skipped
+// and the context of the running ones is cancelled.
+func (s *Scheduler) Run(ctx context.Context) error {
+	levels, err := s.Order()
+	if err != nil {
+		return err
+	}
+
+	if len(s.nodes) == 0 {
+		return nil
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	grp := spinners.NewSpinnerGroup()
+	states := map[string]*state{}
+	idx := 0
+	for _, level := range levels {
+		for _, name := range level {
+			grp.AddSpinner()
+			states[name] = &state{
+				spinner: grp.At(idx).WithName(name),
+				done:    make(chan struct{}),
+			}
+			idx += 1
+		}
+	}
+
+	var wg sync.WaitGroup
+	for name, st := range states {
+		prereqs := s.prerequisites(name)
+		if len(prereqs) != 0 {
+			st.spinner.Update(fmt.Sprintf("Waiting for %s...", strings.Join(prereqs, ", ")))
+		}
+
+		wg.Add(1)
+		go func(n *node, st *state, prereqs []string) {
+			defer wg.Done()
+			defer close(st.done)
+
+			for _, p := range prereqs {
+				select {
+				case <-states[p].done:
+				case <-runCtx.Done():
+					return
+				}
+
+				if states[p].spinner.Failed() {
+					if st.spinner.IsActive() {
+						st.spinner.ErrorWithMessage(fmt.Sprintf("Skipped, %s failed", p), nil)
+					}
+					return
+				}
+			}
+
+			if runCtx.Err() != nil {
+				return
+			}
+
+			n.task(runCtx, st.spinner)
+			if st.spinner.Failed() {
+				cancel()
+			}
+		}(s.nodes[name], st, prereqs)
+	}
+
+	grp.Start(runCtx)
+	err = grp.Wait()
+	cancel()
+	wg.Wait()
+
+	if err != nil {
+		return err
+	}
+
+	// The group may stop before receiving the error of the last spinner.
+	for _, level := range levels {
+		for _, name := range level {
+			if err := states[name].spinner.Err(); err != nil {
+				return err
+			}
+		}
+	}
+
+	// The group doesn't report the expiration of the context as an error.
+	return ctx.Err()
+}
@@ -0,0 +1,87 @@
+package scheduler
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type orderCase struct {
+	name    string
+	reverse bool
+	nodes   map[string][]string
+	want    [][]string
+	wantErr string
+}
+
+func TestSchedulerOrder(t *testing.T) {
+	cases := []*orderCase{
+		&orderCase{
+			name: "install",
+			nodes: map[string][]string{
+				"Tekton Pipelines": nil,
+				"Shipwright":       {"Tekton Pipelines"},
+				"Knative Serving":  nil,
+				"Kourier":          {"Knative Serving"},
+				"CertManager":      nil,
+				"OpenFunction":     {"CertManager", "Kourier", "Shipwright", "Keda"},
+			},
+			want: [][]string{
+				{"CertManager", "Knative Serving", "Tekton Pipelines"},
+				{"Kourier", "Shipwright"},
+				{"OpenFunction"},
+			},
+		},
+		&orderCase{
+			name:    "uninstall",
+			reverse: true,
+			nodes: map[string][]string{
+				"Tekton Pipelines": nil,
+				"Shipwright":       {"Tekton Pipelines"},
+				"CertManager":      nil,
+				"OpenFunction":     {"CertManager", "Shipwright"},
+			},
+			want: [][]string{
+				{"OpenFunction"},
+				{"CertManager", "Shipwright"},
+				{"Tekton Pipelines"},
+			},
+		},
+		&orderCase{
+			name: "cycle",
+			nodes: map[string][]string{
+				"A": {"B"},
+				"B": {"A"},
+				"C": nil,
+			},
+			wantErr: "dependency cycle detected between A, B",
+		},
+	}
+
+	for _, c := range cases {
+		var s *Scheduler
+		if c.reverse {
+			s = NewReverseScheduler()
+		} else {
+			s = NewScheduler()
+		}
+		for name, deps := range c.nodes {
+			s.Add(name, deps, nil)
+		}
+
+		levels, err := s.Order()
+		if c.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+				t.Errorf("%s: want error %q, got %v", c.name, c.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(levels, c.want) {
+			t.Errorf("%s: want %v, got %v", c.name, c.want, levels)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package upgrade
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/OpenFunction/cli/pkg/components"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8s "k8s.io/client-go/kubernetes"
+)
+
+const (
+	// The checkpoint is kept next to the inventory record.
+	CheckpointConfigMapName = "ofn-upgrade"
+	checkpointPlanKey       = "plan.yaml"
+
+	// Each backed up Function is kept in its own ConfigMap, so that the backup isn't limited by the size of a ConfigMap.
+	checkpointLabel       = "openfunction.io/upgrade-checkpoint"
+	checkpointFunctionKey = "function.json"
+)
+
+// Checkpoint is the progress of an upgrade, it's saved in the cluster after each step
+// so that an interrupted upgrade can be resumed.
+type Checkpoint struct {
+	Plan *Plan
+	// Functions are the Functions backed up by the CRD migration.
+	Functions []*unstructured.Unstructured
+
+	resourceVersion string
+	// The names of the ConfigMaps holding the Functions which have been saved.
+	saved map[string]bool
+}
+
+// LoadCheckpoint returns the checkpoint kept in the cluster, or nil if there is no upgrade in progress.
+func LoadCheckpoint(ctx context.Context, cl k8s.Interface) (*Checkpoint, error) {
+	cm, err := cl.CoreV1().ConfigMaps(components.RecordNamespace).Get(ctx, CheckpointConfigMapName, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Checkpoint{
+		Plan:            &Plan{},
+		resourceVersion: cm.ResourceVersion,
+		saved:           map[string]bool{},
+	}
+	if err := yaml.Unmarshal([]byte(cm.Data[checkpointPlanKey]), c.Plan); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode the upgrade plan in %s/%s", cm.Namespace, cm.Name)
+	}
+
+	backups, err := listFunctionBackups(ctx, cl)
+	if err != nil {
+		return nil, err
+	}
+	for _, backup := range backups {
+		obj := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(backup.Data[checkpointFunctionKey]), &obj); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode the Function in %s/%s", backup.Namespace, backup.Name)
+		}
+		c.Functions = append(c.Functions, &unstructured.Unstructured{Object: obj})
+		c.saved[backup.Name] = true
+	}
+	return c, nil
+}
+
+// Save creates or updates the checkpoint in the cluster.
+// The backups of the Functions are created once and deleted when the Functions are no longer kept.
+func (c *Checkpoint) Save(ctx context.Context, cl k8s.Interface) error {
+	if err := c.saveFunctions(ctx, cl); err != nil {
+		return errors.Wrap(err, "failed to save the upgrade checkpoint")
+	}
+
+	plan, err := yaml.Marshal(c.Plan)
+	if err != nil {
+		return err
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            CheckpointConfigMapName,
+			Namespace:       components.RecordNamespace,
+			ResourceVersion: c.resourceVersion,
+		},
+		Data: map[string]string{
+			checkpointPlanKey: string(plan),
+		},
+	}
+
+	if c.resourceVersion == "" {
+		cm, err = cl.CoreV1().ConfigMaps(components.RecordNamespace).Create(ctx, cm, metav1.CreateOptions{})
+	} else {
+		cm, err = cl.CoreV1().ConfigMaps(components.RecordNamespace).Update(ctx, cm, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to save the upgrade checkpoint")
+	}
+	c.resourceVersion = cm.ResourceVersion
+	return nil
+}
+
+func (c *Checkpoint) saveFunctions(ctx context.Context, cl k8s.Interface) error {
+	if c.saved == nil {
+		c.saved = map[string]bool{}
+	}
+
+	kept := map[string]bool{}
+	for _, fn := range c.Functions {
+		name := functionBackupName(fn)
+		kept[name] = true
+		if c.saved[name] {
+			continue
+		}
+
+		data, err := json.Marshal(fn.Object)
+		if err != nil {
+			return err
+		}
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: components.RecordNamespace,
+				Labels:    map[string]string{checkpointLabel: CheckpointConfigMapName},
+			},
+			Data: map[string]string{
+				checkpointFunctionKey: string(data),
+			},
+		}
+		_, err = cl.CoreV1().ConfigMaps(components.RecordNamespace).Create(ctx, cm, metav1.CreateOptions{})
+		// The backup is left by an interrupted save.
+		if k8serrors.IsAlreadyExists(err) {
+			_, err = cl.CoreV1().ConfigMaps(components.RecordNamespace).Update(ctx, cm, metav1.UpdateOptions{})
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to back up Function %s/%s", fn.GetNamespace(), fn.GetName())
+		}
+		c.saved[name] = true
+	}
+
+	for name := range c.saved {
+		if kept[name] {
+			continue
+		}
+		if err := deleteFunctionBackup(ctx, cl, name); err != nil {
+			return err
+		}
+		delete(c.saved, name)
+	}
+	return nil
+}
+
+// Delete removes the checkpoint and the backed up Functions from the cluster once the upgrade is done,
+// it fails if the Functions haven't been restored.
+func (c *Checkpoint) Delete(ctx context.Context, cl k8s.Interface) error {
+	if len(c.Functions) != 0 {
+		return errors.Errorf("the %d backed up Functions haven't been restored", len(c.Functions))
+	}
+
+	backups, err := listFunctionBackups(ctx, cl)
+	if err != nil {
+		return err
+	}
+	for _, backup := range backups {
+		if err := deleteFunctionBackup(ctx, cl, backup.Name); err != nil {
+			return err
+		}
+	}
+	c.saved = nil
+
+	err = cl.CoreV1().ConfigMaps(components.RecordNamespace).Delete(ctx, CheckpointConfigMapName, metav1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+	c.resourceVersion = ""
+	return nil
+}
+
+// functionBackupName returns the name of the ConfigMap holding the backup of fn,
+// which is derived from its namespace and name as they may not fit in the name of a ConfigMap together.
+func functionBackupName(fn *unstructured.Unstructured) string {
+	sum := sha256.Sum256([]byte(fn.GetNamespace() + "/" + fn.GetName()))
+	return fmt.Sprintf("%s-fn-%s", CheckpointConfigMapName, hex.EncodeToString(sum[:])[:16])
+}
+
+func listFunctionBackups(ctx context.Context, cl k8s.Interface) ([]corev1.ConfigMap, error) {
+	list, err := cl.CoreV1().ConfigMaps(components.RecordNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: checkpointLabel + "=" + CheckpointConfigMapName,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the backed up Functions")
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].Name < list.Items[j].Name
+	})
+	return list.Items, nil
+}
+
+func deleteFunctionBackup(ctx context.Context, cl k8s.Interface, name string) error {
+	err := cl.CoreV1().ConfigMaps(components.RecordNamespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to delete the backed up Function in %s/%s", components.RecordNamespace, name)
+	}
+	return nil
+}
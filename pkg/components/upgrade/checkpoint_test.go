@@ -0,0 +1,113 @@
+package upgrade
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/OpenFunction/cli/pkg/components"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newFunction(namespace string, name string) *unstructured.Unstructured {
+	fn := &unstructured.Unstructured{}
+	fn.SetAPIVersion(functionGroup + "/" + FunctionV1alpha1)
+	fn.SetKind(functionKind)
+	fn.SetNamespace(namespace)
+	fn.SetName(name)
+	unstructured.SetNestedField(fn.Object, strings.Repeat("x", 512*1024), "spec", "image")
+	return fn
+}
+
+func TestCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	cl := fake.NewSimpleClientset()
+	// The fake clientset doesn't set the resource version as the API server does.
+	cl.PrependReactor("create", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		action.(k8stesting.CreateAction).GetObject().(*corev1.ConfigMap).ResourceVersion = "1"
+		return false, nil, nil
+	})
+	configMaps := func() []string {
+		list, err := cl.CoreV1().ConfigMaps(components.RecordNamespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, cm := range list.Items {
+			size := 0
+			for _, v := range cm.Data {
+				size += len(v)
+			}
+			if size > 1024*1024 {
+				t.Errorf("%s holds %d bytes, more than a ConfigMap can", cm.Name, size)
+			}
+			names = append(names, cm.Name)
+		}
+		return names
+	}
+
+	if c, err := LoadCheckpoint(ctx, cl); err != nil || c != nil {
+		t.Fatalf("want no checkpoint, got %v, %v", c, err)
+	}
+
+	// The Functions don't fit in a single ConfigMap together.
+	c := &Checkpoint{Plan: &Plan{Target: "v0.6.0"}}
+	for i := 0; i < 3; i++ {
+		c.Functions = append(c.Functions, newFunction("default", fmt.Sprintf("function-%d", i)))
+	}
+	if err := c.Save(ctx, cl); err != nil {
+		t.Fatal(err)
+	}
+	c.Plan.Completed = 1
+	if err := c.Save(ctx, cl); err != nil {
+		t.Fatal(err)
+	}
+	if got := configMaps(); len(got) != 4 {
+		t.Errorf("want the plan and 3 Functions saved, got %v", got)
+	}
+
+	loaded, err := LoadCheckpoint(ctx, cl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Plan.Target != "v0.6.0" || loaded.Plan.Completed != 1 {
+		t.Errorf("unexpected plan: %+v", loaded.Plan)
+	}
+	names := map[string]bool{}
+	for _, fn := range loaded.Functions {
+		names[fn.GetName()] = true
+	}
+	if len(loaded.Functions) != 3 || !names["function-0"] || !names["function-1"] || !names["function-2"] {
+		t.Errorf("want the Functions loaded, got %v", names)
+	}
+
+	// The backups are kept until the Functions have been restored.
+	if err := loaded.Delete(ctx, cl); err == nil {
+		t.Error("want the checkpoint kept while the Functions haven't been restored")
+	}
+	if got := configMaps(); len(got) != 4 {
+		t.Errorf("want the plan and the backups kept, got %v", got)
+	}
+
+	// The backups are deleted once the Functions have been restored.
+	loaded.Functions = nil
+	if err := loaded.Save(ctx, cl); err != nil {
+		t.Fatal(err)
+	}
+	if got := configMaps(); len(got) != 1 || got[0] != CheckpointConfigMapName {
+		t.Errorf("want the plan only, got %v", got)
+	}
+
+	if err := loaded.Delete(ctx, cl); err != nil {
+		t.Fatal(err)
+	}
+	if got := configMaps(); len(got) != 0 {
+		t.Errorf("want the checkpoint deleted, got %v", got)
+	}
+}
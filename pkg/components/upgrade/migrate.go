@@ -0,0 +1,284 @@
+package upgrade
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/OpenFunction/cli/pkg/components"
+	"github.com/openfunction/apis/core/v1alpha2"
+	"github.com/openfunction/apis/core/v1beta1"
+	"github.com/pkg/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	k8s "k8s.io/client-go/kubernetes"
+)
+
+const (
+	functionGroup    = "core.openfunction.io"
+	functionResource = "functions"
+	functionKind     = "Function"
+	functionCRD      = "functions.core.openfunction.io"
+
+	knativeNetworkConfigMap = "config-network"
+)
+
+var (
+	crdGVR = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+	// The CRDs of OpenFunction, the Builders and the Servings are recreated by the controller from the Functions.
+	openFunctionCRDs = []string{
+		functionCRD,
+		"builders.core.openfunction.io",
+		"servings.core.openfunction.io",
+	}
+
+	// knativeNetworkKeys maps the keys of config-network used by Knative Serving 0.x to the keys of 1.x.
+	knativeNetworkKeys = map[string]string{
+		"domainTemplate":        "domain-template",
+		"tagTemplate":           "tag-template",
+		"autoTLS":               "auto-tls",
+		"httpProtocol":          "http-protocol",
+		"ingress.class":         "ingress-class",
+		"certificate.class":     "certificate-class",
+		"defaultExternalScheme": "default-external-scheme",
+	}
+
+	pollInterval = 2 * time.Second
+)
+
+func functionGVR(apiVersion string) schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: functionGroup, Version: apiVersion, Resource: functionResource}
+}
+
+// BackupFunctions returns the Functions of all the namespaces in apiVersion,
+// without their status and the metadata set by the API server.
+func BackupFunctions(ctx context.Context, dc dynamic.Interface, apiVersion string) ([]*unstructured.Unstructured, error) {
+	list, err := dc.Resource(functionGVR(apiVersion)).List(ctx, metav1.ListOptions{})
+	if k8serrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the Functions")
+	}
+
+	var fns []*unstructured.Unstructured
+	for i := range list.Items {
+		fn := list.Items[i].DeepCopy()
+		unstructured.RemoveNestedField(fn.Object, "status")
+		fn.SetResourceVersion("")
+		fn.SetUID("")
+		fn.SetGeneration(0)
+		fn.SetCreationTimestamp(metav1.Time{})
+		fn.SetManagedFields(nil)
+		fn.SetFinalizers(nil)
+		fns = append(fns, fn)
+	}
+	return fns, nil
+}
+
+// DeleteCRDs deletes the CRDs of OpenFunction along with their objects and waits for them to be gone.
+func DeleteCRDs(ctx context.Context, dc dynamic.Interface) error {
+	for _, name := range openFunctionCRDs {
+		err := dc.Resource(crdGVR).Delete(ctx, name, metav1.DeleteOptions{})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete CRD %s", name)
+		}
+	}
+
+	for _, name := range openFunctionCRDs {
+		for {
+			_, err := dc.Resource(crdGVR).Get(ctx, name, metav1.GetOptions{})
+			if k8serrors.IsNotFound(err) {
+				break
+			}
+			if err != nil {
+				return err
+			}
+
+			select {
+			case <-time.After(pollInterval):
+			case <-ctx.Done():
+				return errors.Wrapf(ctx.Err(), "CRD %s is still being deleted", name)
+			}
+		}
+	}
+	return nil
+}
+
+// RestoreFunctions creates the backed up Functions in apiVersion, the Functions which already exist are left alone.
+// A v1alpha1 Function is decoded as a v1alpha2 one, it fails if any of its fields is unknown to v1alpha2.
+func RestoreFunctions(ctx context.Context, dc dynamic.Interface, fns []*unstructured.Unstructured, apiVersion string) error {
+	for _, fn := range fns {
+		obj, err := ConvertFunction(fn, apiVersion)
+		if err != nil {
+			return errors.Wrapf(err,
+				"failed to convert Function %s/%s, fix its backup in ConfigMap %s/%s and resume the upgrade",
+				fn.GetNamespace(), fn.GetName(), components.RecordNamespace, functionBackupName(fn))
+		}
+
+		_, err = dc.Resource(functionGVR(apiVersion)).Namespace(obj.GetNamespace()).Create(ctx, obj, metav1.CreateOptions{})
+		if err != nil && !k8serrors.IsAlreadyExists(err) {
+			return errors.Wrapf(err, "failed to create Function %s/%s", obj.GetNamespace(), obj.GetName())
+		}
+	}
+	return nil
+}
+
+// ConvertFunction converts a Function of v1alpha1 or v1alpha2 to apiVersion, which is either v1alpha2 or v1beta1.
+// It fails if the spec has fields which would be dropped by the conversion.
+func ConvertFunction(fn *unstructured.Unstructured, apiVersion string) (*unstructured.Unstructured, error) {
+	data, err := json.Marshal(fn.Object)
+	if err != nil {
+		return nil, err
+	}
+	src := &v1alpha2.Function{}
+	if err := json.Unmarshal(data, src); err != nil {
+		return nil, err
+	}
+	src.Status = v1alpha2.FunctionStatus{}
+
+	decoded, err := runtime.DefaultUnstructuredConverter.ToUnstructured(src)
+	if err != nil {
+		return nil, err
+	}
+	if dropped := droppedFields("spec", fn.Object["spec"], decoded["spec"]); len(dropped) != 0 {
+		return nil, errors.Errorf("the fields %s can't be converted to %s", strings.Join(dropped, ", "), apiVersion)
+	}
+
+	var dst runtime.Object = src
+	if apiVersion == FunctionV1beta1 {
+		hub := &v1beta1.Function{}
+		if err := src.ConvertTo(hub); err != nil {
+			return nil, err
+		}
+		hub.Status = v1beta1.FunctionStatus{}
+		dst = hub
+	}
+
+	m, err := runtime.DefaultUnstructuredConverter.ToUnstructured(dst)
+	if err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{Object: m}
+	obj.SetAPIVersion(functionGroup + "/" + apiVersion)
+	obj.SetKind(functionKind)
+	unstructured.RemoveNestedField(obj.Object, "status")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "creationTimestamp")
+	return obj, nil
+}
+
+// droppedFields returns the paths of the fields of from which aren't in to, the empty fields are ignored.
+func droppedFields(path string, from interface{}, to interface{}) []string {
+	var dropped []string
+	switch f := from.(type) {
+	case map[string]interface{}:
+		t, _ := to.(map[string]interface{})
+		keys := make([]string, 0, len(f))
+		for k := range f {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if _, ok := t[k]; !ok && !isEmpty(f[k]) {
+				dropped = append(dropped, path+"."+k)
+				continue
+			}
+			dropped = append(dropped, droppedFields(path+"."+k, f[k], t[k])...)
+		}
+	case []interface{}:
+		t, _ := to.([]interface{})
+		for i := range f {
+			if i < len(t) {
+				dropped = append(dropped, droppedFields(fmt.Sprintf("%s[%d]", path, i), f[i], t[i])...)
+			}
+		}
+	}
+	return dropped
+}
+
+func isEmpty(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map, reflect.Slice, reflect.String:
+		return rv.Len() == 0
+	}
+	return rv.IsZero()
+}
+
+// MigrateStorage rewrites the Functions so that they are stored in apiVersion,
+// then apiVersion is made the only stored version of their CRD.
+func MigrateStorage(ctx context.Context, dc dynamic.Interface, apiVersion string) error {
+	ri := dc.Resource(functionGVR(apiVersion))
+	list, err := ri.List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to list the Functions")
+	}
+
+	for i := range list.Items {
+		fn := &list.Items[i]
+		// An update without changes is enough for the API server to store the object in the storage version.
+		if _, err := ri.Namespace(fn.GetNamespace()).Update(ctx, fn, metav1.UpdateOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to rewrite Function %s/%s", fn.GetNamespace(), fn.GetName())
+		}
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{
+			"storedVersions": []string{apiVersion},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := dc.Resource(crdGVR).Patch(ctx, functionCRD, types.MergePatchType, patch, metav1.PatchOptions{}, "status"); err != nil {
+		return errors.Wrapf(err, "failed to update the stored versions of CRD %s", functionCRD)
+	}
+	return nil
+}
+
+// MigrateKnativeNetwork moves the values of the legacy keys of config-network in namespace to the keys of Knative Serving 1.x,
+// the values already set with the new keys are kept.
+func MigrateKnativeNetwork(ctx context.Context, cl k8s.Interface, namespace string) error {
+	cm, err := cl.CoreV1().ConfigMaps(namespace).Get(ctx, knativeNetworkConfigMap, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get %s/%s", namespace, knativeNetworkConfigMap)
+	}
+
+	if !migrateKeys(cm.Data, knativeNetworkKeys) {
+		return nil
+	}
+	if _, err := cl.CoreV1().ConfigMaps(namespace).Update(ctx, cm, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrapf(err, "failed to update %s/%s", namespace, knativeNetworkConfigMap)
+	}
+	return nil
+}
+
+// migrateKeys renames the keys of data according to keys and returns true if data has been changed.
+func migrateKeys(data map[string]string, keys map[string]string) bool {
+	changed := false
+	for from, to := range keys {
+		v, ok := data[from]
+		if !ok {
+			continue
+		}
+		if _, ok := data[to]; !ok {
+			data[to] = v
+		}
+		delete(data, from)
+		changed = true
+	}
+	return changed
+}
@@ -0,0 +1,87 @@
+package upgrade
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestConvertFunction(t *testing.T) {
+	tests := []struct {
+		name       string
+		apiVersion string
+		spec       map[string]interface{}
+		wantImage  string
+		wantErr    string
+	}{
+		{
+			name:       "to v1alpha2",
+			apiVersion: FunctionV1alpha2,
+			spec: map[string]interface{}{
+				"image": "demo:v1",
+				"port":  int64(8080),
+				"build": map[string]interface{}{
+					"builder": "openfunction/builder:v1",
+					"srcRepo": map[string]interface{}{"url": "https://github.com/OpenFunction/samples.git"},
+					"env":     map[string]interface{}{},
+				},
+			},
+			wantImage: "demo:v1",
+		},
+		{
+			name:       "to v1beta1",
+			apiVersion: FunctionV1beta1,
+			spec:       map[string]interface{}{"image": "demo:v1"},
+			wantImage:  "demo:v1",
+		},
+		{
+			name:       "field dropped",
+			apiVersion: FunctionV1beta1,
+			spec: map[string]interface{}{
+				"image":  "demo:v1",
+				"legacy": "value",
+				"build": map[string]interface{}{
+					"builder":  "openfunction/builder:v1",
+					"unknown":  true,
+					"ignored":  "",
+					"srcRepo":  map[string]interface{}{"url": "https://github.com/OpenFunction/samples.git"},
+					"removed2": []interface{}{"a"},
+				},
+			},
+			wantErr: "the fields spec.build.removed2, spec.build.unknown, spec.legacy can't be converted to v1beta1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn := &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": functionGroup + "/" + FunctionV1alpha1,
+				"kind":       functionKind,
+				"metadata":   map[string]interface{}{"name": "sample", "namespace": "default"},
+				"spec":       tt.spec,
+				"status":     map[string]interface{}{"url": "http://sample.default"},
+			}}
+
+			obj, err := ConvertFunction(fn, tt.apiVersion)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("want error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if obj.GetAPIVersion() != functionGroup+"/"+tt.apiVersion || obj.GetName() != "sample" {
+				t.Errorf("unexpected object: %v", obj.Object)
+			}
+			if image, _, _ := unstructured.NestedString(obj.Object, "spec", "image"); image != tt.wantImage {
+				t.Errorf("want image %s, got %s", tt.wantImage, image)
+			}
+			if _, ok := obj.Object["status"]; ok {
+				t.Errorf("want no status, got %v", obj.Object["status"])
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+package upgrade
+
+import (
+	"fmt"
+
+	"github.com/OpenFunction/cli/pkg/components/common"
+	"github.com/OpenFunction/cli/pkg/components/inventory"
+	"github.com/OpenFunction/cli/pkg/components/scheduler"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+type StepType string
+
+const (
+	// CRDMigration backs up the Functions and removes the CRDs which can't be upgraded in place.
+	CRDMigration StepType = "CRDMigration"
+	// ComponentUpgrade installs a component or upgrades it to the target version.
+	ComponentUpgrade StepType = "ComponentUpgrade"
+	// KnativeURLScheme moves the settings of the URLs of Knative Serving 0.x to the keys of 1.x.
+	KnativeURLScheme StepType = "KnativeURLScheme"
+	// FunctionConversion converts the Functions to the API version of the target version of OpenFunction.
+	FunctionConversion StepType = "FunctionConversion"
+
+	FunctionV1alpha1 = "v1alpha1"
+	FunctionV1alpha2 = "v1alpha2"
+	FunctionV1beta1  = "v1beta1"
+
+	unknownVersion = "unknown"
+)
+
+var (
+	// The versions of OpenFunction serving the API versions of Function.
+	functionV1alpha2Since = version.MustParseGeneric("0.4.0")
+	functionV1beta1Since  = version.MustParseGeneric("0.6.0")
+
+	knativeServingV1 = version.MustParseGeneric("1.0.0")
+)
+
+// Step is a step of an upgrade plan.
+type Step struct {
+	Type      StepType `yaml:"type"`
+	Component string   `yaml:"component,omitempty"`
+	// From and To are the versions of the component for the component upgrades,
+	// and the API versions for the migrations and the conversions.
+	From        string `yaml:"from,omitempty"`
+	To          string `yaml:"to,omitempty"`
+	Description string `yaml:"description"`
+}
+
+// Plan is the ordered list of steps upgrading OpenFunction and its dependencies to a target version.
+type Plan struct {
+	// Target is the version of OpenFunction to upgrade to.
+	Target string  `yaml:"target"`
+	Steps  []*Step `yaml:"steps"`
+	// Warnings are about the versions which are left unchanged or differ from the record.
+	Warnings []string `yaml:"warnings,omitempty"`
+	// Completed is the number of steps which have been applied.
+	Completed int `yaml:"completed"`
+}
+
+// CurrentVersions returns the versions of the installed components,
+// the recorded versions are used for the ones whose version can't be detected.
+func CurrentVersions(installed map[string]*common.ComponentStatus, recorded map[string]string) map[string]string {
+	m := map[string]string{}
+	for name, st := range installed {
+		if !st.Installed {
+			continue
+		}
+		switch {
+		case st.Version != "":
+			m[name] = st.Version
+		case common.NormalizeVersion(recorded[name]) != "":
+			m[name] = common.NormalizeVersion(recorded[name])
+		default:
+			m[name] = unknownVersion
+		}
+	}
+	return m
+}
+
+// NewPlan returns the plan upgrading the components from the current versions to the versions of target.
+// The components missing from current are installed, and the recorded versions are only used for warnings.
+// The steps are ordered as CRD migrations, component upgrades in the order of their dependencies,
+// Knative URL scheme changes and Function conversions.
+func NewPlan(current map[string]string, recorded map[string]string, target map[string]inventory.Interface) (*Plan, error) {
+	plan := &Plan{}
+	if of, ok := target[inventory.OpenFunctionName]; ok {
+		plan.Target = of.GetVersion()
+	}
+
+	sched := scheduler.NewScheduler()
+	for name, iv := range target {
+		sched.Add(name, iv.GetDependencies(), nil)
+	}
+	levels, err := sched.Order()
+	if err != nil {
+		return nil, err
+	}
+
+	var upgrades []*Step
+	for _, level := range levels {
+		for _, name := range level {
+			from, to := current[name], target[name].GetVersion()
+			if rec := common.NormalizeVersion(recorded[name]); rec != "" && from != "" && from != unknownVersion && rec != from {
+				plan.Warnings = append(plan.Warnings, fmt.Sprintf("%s is recorded as %s but %s is running", name, rec, from))
+			}
+
+			switch compareVersions(from, to) {
+			case 0:
+				continue
+			case 1:
+				plan.Warnings = append(plan.Warnings, fmt.Sprintf("%s %s is newer than %s and is left unchanged", name, from, to))
+				continue
+			}
+
+			desc := fmt.Sprintf("Upgrade %s from %s to %s", name, from, to)
+			if from == "" {
+				desc = fmt.Sprintf("Install %s %s", name, to)
+			}
+			upgrades = append(upgrades, &Step{
+				Type:        ComponentUpgrade,
+				Component:   name,
+				From:        from,
+				To:          to,
+				Description: desc,
+			})
+		}
+	}
+
+	// The Functions are only migrated if OpenFunction is installed and upgraded.
+	var migrations, conversions []*Step
+	if from, ok := current[inventory.OpenFunctionName]; ok && plan.Target != "" && compareVersions(from, plan.Target) < 0 {
+		fromAPI, toAPI := FunctionAPIVersion(from), FunctionAPIVersion(plan.Target)
+		switch {
+		case fromAPI == FunctionV1alpha1 && toAPI != FunctionV1alpha1:
+			migrations = append(migrations, &Step{
+				Type:      CRDMigration,
+				Component: inventory.OpenFunctionName,
+				From:      fromAPI,
+				To:        toAPI,
+				Description: fmt.Sprintf("Back up the Functions and delete the CRDs of OpenFunction %s, %s isn't served by OpenFunction %s",
+					from, fromAPI, plan.Target),
+			})
+			conversions = append(conversions, &Step{
+				Type:        FunctionConversion,
+				Component:   inventory.OpenFunctionName,
+				From:        fromAPI,
+				To:          toAPI,
+				Description: fmt.Sprintf("Recreate the backed up Functions in %s", toAPI),
+			})
+		case fromAPI == FunctionV1alpha2 && toAPI == FunctionV1beta1:
+			conversions = append(conversions, &Step{
+				Type:        FunctionConversion,
+				Component:   inventory.OpenFunctionName,
+				From:        fromAPI,
+				To:          toAPI,
+				Description: fmt.Sprintf("Rewrite the Functions in %s and drop %s from the stored versions of their CRD", toAPI, fromAPI),
+			})
+		}
+	}
+
+	var schemes []*Step
+	if from, ok := current[inventory.KnativeServingName]; ok {
+		if iv, ok := target[inventory.KnativeServingName]; ok && isBefore(from, knativeServingV1) && !isBefore(iv.GetVersion(), knativeServingV1) {
+			schemes = append(schemes, &Step{
+				Type:        KnativeURLScheme,
+				Component:   inventory.KnativeServingName,
+				From:        from,
+				To:          iv.GetVersion(),
+				Description: "Move the legacy URL settings of config-network, e.g. domainTemplate and httpProtocol, to the keys of Knative Serving 1.x",
+			})
+		}
+	}
+
+	plan.Steps = append(plan.Steps, migrations...)
+	plan.Steps = append(plan.Steps, upgrades...)
+	plan.Steps = append(plan.Steps, schemes...)
+	plan.Steps = append(plan.Steps, conversions...)
+	return plan, nil
+}
+
+// RestoreBackups makes the plan recreate the Functions backed up by a discarded plan, unless it already does,
+// e.g. if OpenFunction has been upgraded by the discarded plan but its Functions haven't been recreated.
+func (p *Plan) RestoreBackups() error {
+	toAPI := FunctionAPIVersion(p.Target)
+	if toAPI == FunctionV1alpha1 {
+		return errors.Errorf("the backed up Functions can't be restored by OpenFunction %s which only serves %s", p.Target, toAPI)
+	}
+	for _, step := range p.Steps[p.Completed:] {
+		if step.Type == FunctionConversion && step.From == FunctionV1alpha1 {
+			return nil
+		}
+	}
+
+	p.Steps = append(p.Steps, &Step{
+		Type:        FunctionConversion,
+		Component:   inventory.OpenFunctionName,
+		From:        FunctionV1alpha1,
+		To:          toAPI,
+		Description: fmt.Sprintf("Recreate the backed up Functions in %s", toAPI),
+	})
+	return nil
+}
+
+// Done returns true if all the steps of the plan have been applied.
+func (p *Plan) Done() bool {
+	return p.Completed >= len(p.Steps)
+}
+
+// FunctionAPIVersion returns the API version of Function served by the version of OpenFunction.
+func FunctionAPIVersion(openFunctionVersion string) string {
+	if openFunctionVersion == common.LatestVersion {
+		return FunctionV1beta1
+	}
+	v, err := version.ParseGeneric(openFunctionVersion)
+	if err != nil {
+		return FunctionV1beta1
+	}
+	switch {
+	case v.LessThan(functionV1alpha2Since):
+		return FunctionV1alpha1
+	case v.LessThan(functionV1beta1Since):
+		return FunctionV1alpha2
+	default:
+		return FunctionV1beta1
+	}
+}
+
+// compareVersions returns -1, 0 or 1 if from is older than, the same as or newer than to.
+// A missing or unknown version is older than any other, and "latest" is newer than any other.
+func compareVersions(from string, to string) int {
+	if from == to {
+		return 0
+	}
+	if from == "" || from == unknownVersion || to == common.LatestVersion {
+		return -1
+	}
+	if from == common.LatestVersion {
+		return 1
+	}
+
+	f, err := version.ParseGeneric(from)
+	if err != nil {
+		return -1
+	}
+	t, err := version.ParseGeneric(to)
+	if err != nil {
+		return -1
+	}
+	switch {
+	case f.LessThan(t):
+		return -1
+	case t.LessThan(f):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// isBefore returns true if v is a valid version older than ver.
+func isBefore(v string, ver *version.Version) bool {
+	parsed, err := version.ParseGeneric(v)
+	return err == nil && parsed.LessThan(ver)
+}
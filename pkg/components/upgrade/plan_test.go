@@ -0,0 +1,174 @@
+package upgrade
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/OpenFunction/cli/pkg/components/inventory"
+)
+
+func TestNewPlan(t *testing.T) {
+	tests := []struct {
+		name                string
+		openFunctionVersion string
+		current             map[string]string
+		recorded            map[string]string
+		want                []string
+		wantWarnings        int
+	}{
+		{
+			name:                "from v1alpha1 and Knative 0.x",
+			openFunctionVersion: "v0.6.0",
+			current: map[string]string{
+				inventory.OpenFunctionName:         "0.3.1",
+				inventory.KnativeServingName:       "0.21.1",
+				inventory.KourierName:              "0.21.0",
+				inventory.ServingDefaultDomainName: "0.21.1",
+			},
+			want: []string{
+				"CRDMigration/OpenFunction",
+				"ComponentUpgrade/CertManager",
+				"ComponentUpgrade/Knative Serving",
+				"ComponentUpgrade/DefaultDomain",
+				"ComponentUpgrade/Kourier",
+				"ComponentUpgrade/OpenFunction",
+				"KnativeURLScheme/Knative Serving",
+				"FunctionConversion/OpenFunction",
+			},
+		},
+		{
+			name:                "from v1alpha2",
+			openFunctionVersion: "v0.6.0",
+			current: map[string]string{
+				inventory.OpenFunctionName:         "0.5.0",
+				inventory.CertManagerName:          "1.5.4",
+				inventory.KnativeServingName:       "1.0.1",
+				inventory.KourierName:              "1.0.1",
+				inventory.ServingDefaultDomainName: "1.0.1",
+			},
+			recorded: map[string]string{
+				inventory.OpenFunctionName: "0.4.0",
+			},
+			want: []string{
+				"ComponentUpgrade/OpenFunction",
+				"FunctionConversion/OpenFunction",
+			},
+			wantWarnings: 1,
+		},
+		{
+			name:                "up to date",
+			openFunctionVersion: "v0.6.0",
+			current: map[string]string{
+				inventory.OpenFunctionName:         "0.6.0",
+				inventory.CertManagerName:          "1.6.0",
+				inventory.KnativeServingName:       "1.0.1",
+				inventory.KourierName:              "1.0.1",
+				inventory.ServingDefaultDomainName: "1.0.1",
+			},
+			wantWarnings: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := inventory.GetInventoryWithServerVersion("v1.20.7", false, true, false, false, false, true, false, tt.openFunctionVersion)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			plan, err := NewPlan(tt.current, tt.recorded, target)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, step := range plan.Steps {
+				got = append(got, string(step.Type)+"/"+step.Component)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want steps %v, got %v", tt.want, got)
+			}
+			if len(plan.Warnings) != tt.wantWarnings {
+				t.Errorf("want %d warnings, got %v", tt.wantWarnings, plan.Warnings)
+			}
+		})
+	}
+}
+
+func TestRestoreBackups(t *testing.T) {
+	tests := []struct {
+		name                string
+		openFunctionVersion string
+		current             map[string]string
+		want                []string
+		wantErr             string
+	}{
+		{
+			name:                "OpenFunction upgraded by the discarded plan",
+			openFunctionVersion: "v0.6.0",
+			current: map[string]string{
+				inventory.OpenFunctionName:         "0.6.0",
+				inventory.CertManagerName:          "1.5.4",
+				inventory.KnativeServingName:       "1.0.1",
+				inventory.KourierName:              "1.0.1",
+				inventory.ServingDefaultDomainName: "1.0.1",
+			},
+			want: []string{"FunctionConversion/v1alpha1/v1beta1"},
+		},
+		{
+			name:                "Functions recreated by the new plan",
+			openFunctionVersion: "v0.6.0",
+			current: map[string]string{
+				inventory.OpenFunctionName:         "0.3.1",
+				inventory.CertManagerName:          "1.5.4",
+				inventory.KnativeServingName:       "1.0.1",
+				inventory.KourierName:              "1.0.1",
+				inventory.ServingDefaultDomainName: "1.0.1",
+			},
+			want: []string{
+				"CRDMigration/v1alpha1/v1beta1",
+				"ComponentUpgrade//",
+				"FunctionConversion/v1alpha1/v1beta1",
+			},
+		},
+		{
+			name:                "v1alpha1 only",
+			openFunctionVersion: "v0.3.1",
+			current:             map[string]string{inventory.OpenFunctionName: "0.3.1"},
+			wantErr:             "the backed up Functions can't be restored by OpenFunction 0.3.1 which only serves v1alpha1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := inventory.GetInventoryWithServerVersion("v1.20.7", false, true, false, false, false, true, false, tt.openFunctionVersion)
+			if err != nil {
+				t.Fatal(err)
+			}
+			plan, err := NewPlan(tt.current, nil, target)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = plan.RestoreBackups()
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("want error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, step := range plan.Steps {
+				if step.Type == ComponentUpgrade {
+					step = &Step{Type: ComponentUpgrade}
+				}
+				got = append(got, string(step.Type)+"/"+step.From+"/"+step.To)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want steps %v, got %v", tt.want, got)
+			}
+		})
+	}
+}